@@ -0,0 +1,68 @@
+// Command genclient writes the JavaScript client of the API used by the
+// frontend. The client is generated from the OpenAPI document built into
+// the backend, or from the one served at -spec, such as
+// http://localhost:8080/openapi.json.
+//
+//	go run ./cmd/genclient -o ../frontend/src/services/client.js
+package main
+
+import (
+	"backend/openapi"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+func main() {
+	out := flag.String("o", "../frontend/src/services/client.js", "file to write the client to")
+	spec := flag.String("spec", "", "URL or file of an OpenAPI document to generate from instead of the built-in one")
+	flag.Parse()
+
+	doc, err := load(*spec)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "genclient:", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(*out, openapi.Client(doc), 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "genclient:", err)
+		os.Exit(1)
+	}
+}
+
+// load reads the document at spec, or builds it when spec is empty.
+func load(spec string) (*openapi.Document, error) {
+	if spec == "" {
+		return openapi.Spec(), nil
+	}
+	var data []byte
+	var err error
+	if strings.HasPrefix(spec, "http://") || strings.HasPrefix(spec, "https://") {
+		data, err = fetch(spec)
+	} else {
+		data, err = os.ReadFile(spec)
+	}
+	if err != nil {
+		return nil, err
+	}
+	doc := &openapi.Document{}
+	if err := json.Unmarshal(data, doc); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", spec, err)
+	}
+	return doc, nil
+}
+
+func fetch(url string) ([]byte, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
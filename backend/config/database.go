@@ -2,11 +2,9 @@ package config
 
 import (
 	"backend/model"
-	"context"
 	"database/sql"
 	"fmt"
 	"os"
-	"path/filepath"
 
 	"github.com/rs/zerolog/log"
 	"github.com/uptrace/bun"
@@ -52,31 +50,5 @@ func ConnectDB() (*bun.DB, error) {
 
 	log.Info().Msg("Successfully connected to PostgreSQL using Bun")
 
-	// Try running the SQL script, but don't fail if there's an error
-	if err := runSchemaScript(db); err != nil {
-		log.Warn().Err(err).Msg("Schema SQL script failed, continuing anyway")
-	}
-
 	return db, nil
 }
-
-func runSchemaScript(db *bun.DB) error {
-	sqlPath := filepath.Join("config", "tables.sql")
-
-	content, err := os.ReadFile(sqlPath)
-	if err != nil {
-		return fmt.Errorf("failed to read SQL file: %v", err)
-	}
-
-	log.Debug().Msg("Running SQL schema script...")
-
-	res, err := db.ExecContext(context.Background(), string(content))
-	if err != nil {
-		return fmt.Errorf("failed to execute SQL script: %w", err)
-	}
-
-	rowsAffected, _ := res.RowsAffected()
-	log.Info().Int64("rowsAffected", rowsAffected).Str("file", sqlPath).Msg("Schema SQL script executed successfully")
-
-	return nil
-}
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS budget_plan_expenses;
+DROP TABLE IF EXISTS expenses;
+DROP TABLE IF EXISTS category;
+DROP TABLE IF EXISTS budget_plan;
+DROP TABLE IF EXISTS users;
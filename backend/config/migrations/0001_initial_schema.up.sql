@@ -1,4 +1,4 @@
-CREATE TABLE users
+CREATE TABLE IF NOT EXISTS users
 (
     id           SERIAL PRIMARY KEY,
     name         TEXT NOT NULL,
@@ -6,7 +6,7 @@ CREATE TABLE users
     password     TEXT NOT NULL,
     created_date TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
 );
-CREATE TABLE budget_plan
+CREATE TABLE IF NOT EXISTS budget_plan
 (
     id           SERIAL PRIMARY KEY,
     name         TEXT             NOT NULL,
@@ -17,12 +17,12 @@ CREATE TABLE budget_plan
 
 );
 
-CREATE TABLE category
+CREATE TABLE IF NOT EXISTS category
 (
     id          SERIAL PRIMARY KEY,
     name        TEXT UNIQUE NOT NULL
 );
-CREATE TABLE expenses
+CREATE TABLE IF NOT EXISTS expenses
 (
     id           SERIAL PRIMARY KEY,
     amount       DOUBLE PRECISION NOT NULL,
@@ -34,9 +34,9 @@ CREATE TABLE expenses
     budget_id      INT REFERENCES budget_plan (id) ON DELETE CASCADE
 );
 
-CREATE TABLE budget_plan_expenses
+CREATE TABLE IF NOT EXISTS budget_plan_expenses
 (
     budget_plan_id INT NOT NULL REFERENCES budget_plan (id) ON DELETE CASCADE,
     expense_id     INT NOT NULL REFERENCES expenses (id) ON DELETE CASCADE,
     PRIMARY KEY (budget_plan_id, expense_id)
-);
\ No newline at end of file
+);
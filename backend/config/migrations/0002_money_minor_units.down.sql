@@ -0,0 +1,13 @@
+ALTER TABLE budget_plan ADD COLUMN total_amount DOUBLE PRECISION;
+UPDATE budget_plan SET total_amount = total_amount_minor / 100.0;
+ALTER TABLE budget_plan
+    ALTER COLUMN total_amount SET NOT NULL,
+    DROP COLUMN total_amount_minor,
+    DROP COLUMN total_amount_currency;
+
+ALTER TABLE expenses ADD COLUMN amount DOUBLE PRECISION;
+UPDATE expenses SET amount = amount_minor / 100.0;
+ALTER TABLE expenses
+    ALTER COLUMN amount SET NOT NULL,
+    DROP COLUMN amount_minor,
+    DROP COLUMN amount_currency;
@@ -0,0 +1,15 @@
+ALTER TABLE expenses
+    ADD COLUMN amount_minor    BIGINT,
+    ADD COLUMN amount_currency CHAR(3) NOT NULL DEFAULT 'BRL';
+UPDATE expenses SET amount_minor = ROUND(amount::NUMERIC * 100);
+ALTER TABLE expenses
+    ALTER COLUMN amount_minor SET NOT NULL,
+    DROP COLUMN amount;
+
+ALTER TABLE budget_plan
+    ADD COLUMN total_amount_minor    BIGINT,
+    ADD COLUMN total_amount_currency CHAR(3) NOT NULL DEFAULT 'BRL';
+UPDATE budget_plan SET total_amount_minor = ROUND(total_amount::NUMERIC * 100);
+ALTER TABLE budget_plan
+    ALTER COLUMN total_amount_minor SET NOT NULL,
+    DROP COLUMN total_amount;
@@ -0,0 +1,6 @@
+ALTER TABLE expenses
+    DROP COLUMN exchange_rate,
+    DROP COLUMN base_amount_minor,
+    DROP COLUMN base_amount_currency;
+
+DROP TABLE exchange_rates;
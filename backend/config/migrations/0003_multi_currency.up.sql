@@ -0,0 +1,27 @@
+CREATE TABLE exchange_rates
+(
+    id     SERIAL PRIMARY KEY,
+    base   CHAR(3)        NOT NULL,
+    quote  CHAR(3)        NOT NULL,
+    rate   NUMERIC(20, 10) NOT NULL CHECK (rate > 0),
+    date   DATE           NOT NULL,
+    source TEXT           NOT NULL DEFAULT 'manual',
+    UNIQUE (base, quote, date)
+);
+
+ALTER TABLE expenses
+    ADD COLUMN exchange_rate        NUMERIC(20, 10) NOT NULL DEFAULT 1,
+    ADD COLUMN base_amount_minor    BIGINT,
+    ADD COLUMN base_amount_currency CHAR(3);
+UPDATE expenses e
+SET base_amount_minor    = e.amount_minor,
+    base_amount_currency = COALESCE(bp.total_amount_currency, e.amount_currency)
+FROM budget_plan bp
+WHERE bp.id = e.budget_id;
+UPDATE expenses
+SET base_amount_minor    = amount_minor,
+    base_amount_currency = amount_currency
+WHERE base_amount_minor IS NULL;
+ALTER TABLE expenses
+    ALTER COLUMN base_amount_minor SET NOT NULL,
+    ALTER COLUMN base_amount_currency SET NOT NULL;
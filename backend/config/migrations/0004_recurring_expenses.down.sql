@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS expenses_occurrence_idx;
+ALTER TABLE expenses
+    DROP COLUMN recurring_expense_id,
+    DROP COLUMN occurrence_date;
+
+DROP TABLE recurring_expenses;
@@ -0,0 +1,27 @@
+CREATE TABLE recurring_expenses
+(
+    id              SERIAL PRIMARY KEY,
+    user_id         INT     NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    budget_id       INT     NOT NULL REFERENCES budget_plan (id) ON DELETE CASCADE,
+    category_id     INT REFERENCES category (id),
+    category_name   TEXT,
+    description     TEXT,
+    amount_minor    BIGINT  NOT NULL,
+    amount_currency CHAR(3) NOT NULL,
+    frequency       TEXT    NOT NULL CHECK (frequency IN ('daily', 'weekly', 'monthly', 'yearly')),
+    interval_count  INT     NOT NULL DEFAULT 1 CHECK (interval_count > 0),
+    by_day          TEXT,
+    by_month_day    INT,
+    count           INT,
+    start_date      DATE    NOT NULL,
+    end_date        DATE,
+    next_occurrence DATE,
+    paused          BOOLEAN NOT NULL DEFAULT FALSE,
+    skipped_dates   DATE[]  NOT NULL DEFAULT '{}'
+);
+CREATE INDEX recurring_expenses_due_idx ON recurring_expenses (next_occurrence) WHERE NOT paused;
+
+ALTER TABLE expenses
+    ADD COLUMN recurring_expense_id INT REFERENCES recurring_expenses (id) ON DELETE SET NULL,
+    ADD COLUMN occurrence_date      DATE;
+CREATE UNIQUE INDEX expenses_occurrence_idx ON expenses (recurring_expense_id, occurrence_date);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS expenses_budget_date_idx;
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS expenses_budget_date_idx ON expenses (budget_id, date);
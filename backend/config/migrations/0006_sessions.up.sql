@@ -0,0 +1,17 @@
+ALTER TABLE users
+    ADD COLUMN token_version INT NOT NULL DEFAULT 0;
+
+CREATE TABLE sessions
+(
+    id            SERIAL PRIMARY KEY,
+    user_id       INT       NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    token_hash    CHAR(64)  NOT NULL UNIQUE,
+    token_version INT       NOT NULL,
+    user_agent    TEXT,
+    created_at    TIMESTAMP NOT NULL DEFAULT current_timestamp,
+    expires_at    TIMESTAMP NOT NULL,
+    last_used_at  TIMESTAMP,
+    revoked_at    TIMESTAMP,
+    replaced_by   INT REFERENCES sessions (id) ON DELETE SET NULL
+);
+CREATE INDEX sessions_user_idx ON sessions (user_id) WHERE revoked_at IS NULL;
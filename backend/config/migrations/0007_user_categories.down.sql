@@ -0,0 +1,33 @@
+DROP INDEX IF EXISTS category_parent_idx;
+DROP INDEX IF EXISTS category_user_parent_name_idx;
+
+ALTER TABLE category
+    DROP COLUMN parent_id;
+
+-- collapse the per-user copies back into one global category per name
+UPDATE expenses e
+SET category_id = keep.id
+FROM category c,
+     (SELECT name, MIN(id) AS id FROM category GROUP BY name) keep
+WHERE c.id = e.category_id
+  AND keep.name = c.name;
+
+UPDATE recurring_expenses r
+SET category_id = keep.id
+FROM category c,
+     (SELECT name, MIN(id) AS id FROM category GROUP BY name) keep
+WHERE c.id = r.category_id
+  AND keep.name = c.name;
+
+DELETE
+FROM category c
+    USING (SELECT name, MIN(id) AS id FROM category GROUP BY name) keep
+WHERE c.name = keep.name
+  AND c.id <> keep.id;
+
+ALTER TABLE category
+    DROP COLUMN user_id;
+ALTER TABLE category
+    ADD CONSTRAINT category_name_key UNIQUE (name);
+ALTER TABLE expenses
+    ADD CONSTRAINT expenses_category_name_fkey FOREIGN KEY (category_name) REFERENCES category (name);
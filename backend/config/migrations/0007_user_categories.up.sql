@@ -0,0 +1,49 @@
+ALTER TABLE expenses
+    DROP CONSTRAINT IF EXISTS expenses_category_name_fkey;
+ALTER TABLE category
+    DROP CONSTRAINT IF EXISTS category_name_key;
+
+ALTER TABLE category
+    ADD COLUMN user_id   INT REFERENCES users (id) ON DELETE CASCADE,
+    ADD COLUMN parent_id INT REFERENCES category (id) ON DELETE CASCADE;
+
+-- give every user a private copy of the global categories they use
+INSERT INTO category (name, user_id)
+SELECT DISTINCT c.name, used.user_id
+FROM category c
+         JOIN (SELECT e.category_id, bp.user_id
+               FROM expenses e
+                        JOIN budget_plan bp ON bp.id = e.budget_id
+               UNION
+               SELECT category_id, user_id
+               FROM recurring_expenses) used ON used.category_id = c.id
+WHERE c.user_id IS NULL;
+
+UPDATE expenses e
+SET category_id = own.id
+FROM budget_plan bp,
+     category global,
+     category own
+WHERE bp.id = e.budget_id
+  AND global.id = e.category_id
+  AND global.user_id IS NULL
+  AND own.user_id = bp.user_id
+  AND own.name = global.name;
+
+UPDATE recurring_expenses r
+SET category_id = own.id
+FROM category global,
+     category own
+WHERE global.id = r.category_id
+  AND global.user_id IS NULL
+  AND own.user_id = r.user_id
+  AND own.name = global.name;
+
+DELETE
+FROM category c
+WHERE c.user_id IS NULL
+  AND NOT EXISTS (SELECT 1 FROM expenses e WHERE e.category_id = c.id)
+  AND NOT EXISTS (SELECT 1 FROM recurring_expenses r WHERE r.category_id = c.id);
+
+CREATE UNIQUE INDEX category_user_parent_name_idx ON category (user_id, COALESCE(parent_id, 0), lower(name));
+CREATE INDEX category_parent_idx ON category (parent_id);
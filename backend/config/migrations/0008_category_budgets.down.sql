@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS expenses_budget_category_idx;
+DROP TABLE plan_category_budgets;
@@ -0,0 +1,10 @@
+CREATE TABLE plan_category_budgets
+(
+    id              SERIAL PRIMARY KEY,
+    plan_id         INT     NOT NULL REFERENCES budget_plan (id) ON DELETE CASCADE,
+    category_id     INT     NOT NULL REFERENCES category (id) ON DELETE CASCADE,
+    amount_minor    BIGINT  NOT NULL CHECK (amount_minor >= 0),
+    amount_currency CHAR(3) NOT NULL,
+    UNIQUE (plan_id, category_id)
+);
+CREATE INDEX IF NOT EXISTS expenses_budget_category_idx ON expenses (budget_id, category_id);
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS budget_periods;
+
+ALTER TABLE budget_plan
+    DROP COLUMN IF EXISTS rollover,
+    DROP COLUMN IF EXISTS end_date,
+    DROP COLUMN IF EXISTS period_days,
+    DROP COLUMN IF EXISTS period;
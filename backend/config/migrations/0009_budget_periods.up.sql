@@ -0,0 +1,29 @@
+ALTER TABLE budget_plan
+    ADD COLUMN period      TEXT    NOT NULL DEFAULT 'one_off' CHECK (period IN ('one_off', 'weekly', 'monthly', 'custom')),
+    ADD COLUMN period_days INT CHECK (period_days > 0),
+    ADD COLUMN end_date    DATE,
+    ADD COLUMN rollover    BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE budget_periods
+(
+    id                SERIAL PRIMARY KEY,
+    plan_id           INT     NOT NULL REFERENCES budget_plan (id) ON DELETE CASCADE,
+    start_date        DATE    NOT NULL,
+    end_date          DATE,
+    amount_minor      BIGINT  NOT NULL,
+    amount_currency   CHAR(3) NOT NULL,
+    rollover_minor    BIGINT  NOT NULL DEFAULT 0,
+    rollover_currency CHAR(3) NOT NULL,
+    UNIQUE (plan_id, start_date),
+    CHECK (end_date IS NULL OR end_date > start_date)
+);
+CREATE INDEX budget_periods_due_idx ON budget_periods (end_date);
+
+-- every existing plan becomes a single open-ended period
+INSERT INTO budget_periods (plan_id, start_date, amount_minor, amount_currency, rollover_currency)
+SELECT id,
+       COALESCE(created_date, CURRENT_TIMESTAMP)::DATE,
+       total_amount_minor,
+       total_amount_currency,
+       total_amount_currency
+FROM budget_plan;
@@ -0,0 +1,14 @@
+CREATE TABLE incomes
+(
+    id                   SERIAL PRIMARY KEY,
+    budget_id            INT              NOT NULL REFERENCES budget_plan (id) ON DELETE CASCADE,
+    description          TEXT,
+    source               TEXT,
+    amount_minor         BIGINT           NOT NULL CHECK (amount_minor > 0),
+    amount_currency      CHAR(3)          NOT NULL,
+    exchange_rate        NUMERIC(20, 10)  NOT NULL DEFAULT 1,
+    base_amount_minor    BIGINT           NOT NULL,
+    base_amount_currency CHAR(3)          NOT NULL,
+    date                 TIMESTAMPTZ      NOT NULL
+);
+CREATE INDEX incomes_budget_date_idx ON incomes (budget_id, date);
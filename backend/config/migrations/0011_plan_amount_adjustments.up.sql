@@ -0,0 +1,32 @@
+CREATE TABLE plan_amount_adjustments
+(
+    id                       SERIAL PRIMARY KEY,
+    plan_id                  INT              NOT NULL REFERENCES budget_plan (id) ON DELETE CASCADE,
+    amount_minor             BIGINT           NOT NULL,
+    amount_currency          CHAR(3)          NOT NULL,
+    original_amount_minor    BIGINT           NOT NULL,
+    original_amount_currency CHAR(3)          NOT NULL,
+    exchange_rate            NUMERIC(20, 10)  NOT NULL DEFAULT 1,
+    total_after_minor        BIGINT           NOT NULL,
+    total_after_currency     CHAR(3)          NOT NULL,
+    reason                   TEXT,
+    author_id                INT              REFERENCES users (id) ON DELETE SET NULL,
+    created_at               TIMESTAMPTZ      NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX plan_amount_adjustments_plan_idx ON plan_amount_adjustments (plan_id, id);
+
+-- the current totals become the first entry of every history
+INSERT INTO plan_amount_adjustments (plan_id, amount_minor, amount_currency, original_amount_minor,
+                                     original_amount_currency, total_after_minor, total_after_currency,
+                                     reason, author_id)
+SELECT id,
+       total_amount_minor,
+       total_amount_currency,
+       total_amount_minor,
+       total_amount_currency,
+       total_amount_minor,
+       total_amount_currency,
+       'Opening balance',
+       user_id
+FROM budget_plan
+WHERE total_amount_minor <> 0;
@@ -0,0 +1,5 @@
+ALTER TABLE expenses
+    DROP COLUMN IF EXISTS paid_by;
+
+DROP TABLE IF EXISTS plan_invitations;
+DROP TABLE IF EXISTS plan_members;
@@ -0,0 +1,35 @@
+CREATE TABLE plan_members
+(
+    plan_id    INT         NOT NULL REFERENCES budget_plan (id) ON DELETE CASCADE,
+    user_id    INT         NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role       TEXT        NOT NULL CHECK (role IN ('owner', 'editor', 'viewer')),
+    invited_by INT REFERENCES users (id) ON DELETE SET NULL,
+    joined_at  TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (plan_id, user_id)
+);
+CREATE INDEX plan_members_user_idx ON plan_members (user_id);
+
+INSERT INTO plan_members (plan_id, user_id, role)
+SELECT id, user_id, 'owner'
+FROM budget_plan;
+
+CREATE TABLE plan_invitations
+(
+    id           SERIAL PRIMARY KEY,
+    plan_id      INT         NOT NULL REFERENCES budget_plan (id) ON DELETE CASCADE,
+    email        TEXT        NOT NULL,
+    role         TEXT        NOT NULL CHECK (role IN ('editor', 'viewer')),
+    status       TEXT        NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'declined', 'revoked')),
+    invited_by   INT REFERENCES users (id) ON DELETE SET NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    responded_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX plan_invitations_pending_idx ON plan_invitations (plan_id, lower(email)) WHERE status = 'pending';
+
+ALTER TABLE expenses
+    ADD COLUMN paid_by INT REFERENCES users (id) ON DELETE SET NULL;
+
+UPDATE expenses e
+SET paid_by = bp.user_id
+FROM budget_plan bp
+WHERE bp.id = e.budget_id;
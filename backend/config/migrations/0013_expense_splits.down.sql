@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS settlement_payments;
+DROP TABLE IF EXISTS expense_splits;
+
+ALTER TABLE expenses
+    DROP COLUMN IF EXISTS split_method;
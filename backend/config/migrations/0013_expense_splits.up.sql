@@ -0,0 +1,28 @@
+ALTER TABLE expenses
+    ADD COLUMN split_method TEXT CHECK (split_method IN ('equal', 'percentage', 'exact'));
+
+CREATE TABLE expense_splits
+(
+    expense_id      INT     NOT NULL REFERENCES expenses (id) ON DELETE CASCADE,
+    user_id         INT     NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    amount_minor    BIGINT  NOT NULL CHECK (amount_minor >= 0),
+    amount_currency CHAR(3) NOT NULL,
+    PRIMARY KEY (expense_id, user_id)
+);
+CREATE INDEX expense_splits_user_idx ON expense_splits (user_id);
+
+CREATE TABLE settlement_payments
+(
+    id              SERIAL PRIMARY KEY,
+    plan_id         INT         NOT NULL REFERENCES budget_plan (id) ON DELETE CASCADE,
+    from_user_id    INT         NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    to_user_id      INT         NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    amount_minor    BIGINT      NOT NULL CHECK (amount_minor > 0),
+    amount_currency CHAR(3)     NOT NULL,
+    date            TIMESTAMPTZ NOT NULL,
+    note            TEXT,
+    created_by      INT REFERENCES users (id) ON DELETE SET NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (from_user_id <> to_user_id)
+);
+CREATE INDEX settlement_payments_plan_idx ON settlement_payments (plan_id, date);
@@ -0,0 +1,16 @@
+CREATE TABLE tags
+(
+    id         SERIAL PRIMARY KEY,
+    plan_id    INT         NOT NULL REFERENCES budget_plan (id) ON DELETE CASCADE,
+    name       TEXT        NOT NULL CHECK (length(trim(name)) > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX tags_plan_name_idx ON tags (plan_id, lower(name));
+
+CREATE TABLE expense_tags
+(
+    expense_id INT NOT NULL REFERENCES expenses (id) ON DELETE CASCADE,
+    tag_id     INT NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+    PRIMARY KEY (expense_id, tag_id)
+);
+CREATE INDEX expense_tags_tag_idx ON expense_tags (tag_id);
@@ -0,0 +1,19 @@
+CREATE TABLE expense_rules
+(
+    id                  SERIAL PRIMARY KEY,
+    user_id             INT         NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    plan_id             INT REFERENCES budget_plan (id) ON DELETE CASCADE,
+    name                TEXT        NOT NULL,
+    priority            INT         NOT NULL DEFAULT 0,
+    enabled             BOOLEAN     NOT NULL DEFAULT TRUE,
+    description_pattern TEXT,
+    min_amount          BIGINT,
+    max_amount          BIGINT,
+    currency            CHAR(3),
+    date_from           TIMESTAMPTZ,
+    date_to             TIMESTAMPTZ,
+    category_id         INT REFERENCES category (id) ON DELETE SET NULL,
+    tags                TEXT[]      NOT NULL DEFAULT '{}',
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX expense_rules_user_idx ON expense_rules (user_id, priority);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS expense_attachments;
+DROP FUNCTION IF EXISTS queue_blob_deletion();
+DROP TABLE IF EXISTS blob_deletions;
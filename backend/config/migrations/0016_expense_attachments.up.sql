@@ -0,0 +1,35 @@
+CREATE TABLE expense_attachments
+(
+    id           SERIAL PRIMARY KEY,
+    expense_id   INT         NOT NULL REFERENCES expenses (id) ON DELETE CASCADE,
+    storage_key  TEXT        NOT NULL UNIQUE,
+    file_name    TEXT        NOT NULL,
+    content_type TEXT        NOT NULL,
+    size_bytes   BIGINT      NOT NULL CHECK (size_bytes > 0),
+    sha256       CHAR(64)    NOT NULL,
+    uploaded_by  INT REFERENCES users (id) ON DELETE SET NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX expense_attachments_expense_idx ON expense_attachments (expense_id);
+
+-- Blobs live outside the database, so deleted attachments queue their key
+-- here, whether removed directly or by cascade, for the application to purge.
+CREATE TABLE blob_deletions
+(
+    storage_key TEXT PRIMARY KEY,
+    queued_at   TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE FUNCTION queue_blob_deletion() RETURNS TRIGGER AS
+$$
+BEGIN
+    INSERT INTO blob_deletions (storage_key) VALUES (OLD.storage_key) ON CONFLICT DO NOTHING;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER expense_attachments_queue_blob_deletion
+    AFTER DELETE
+    ON expense_attachments
+    FOR EACH ROW
+EXECUTE FUNCTION queue_blob_deletion();
@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS tags_refresh_search ON tags;
+DROP FUNCTION IF EXISTS tags_refresh_search();
+DROP TRIGGER IF EXISTS expense_tags_refresh_search ON expense_tags;
+DROP FUNCTION IF EXISTS expense_tags_refresh_search();
+DROP TRIGGER IF EXISTS expenses_refresh_search ON expenses;
+DROP FUNCTION IF EXISTS expenses_refresh_search();
+DROP FUNCTION IF EXISTS refresh_expense_search(INT);
+DROP TABLE IF EXISTS expense_search;
@@ -0,0 +1,76 @@
+-- The search document of an expense covers its description, its category
+-- name and the names of its tags. Tags live in another table, so the
+-- document is kept in its own table by triggers rather than as a generated
+-- column.
+CREATE TABLE expense_search
+(
+    expense_id INT      PRIMARY KEY REFERENCES expenses (id) ON DELETE CASCADE,
+    document   TSVECTOR NOT NULL
+);
+CREATE INDEX expense_search_document_idx ON expense_search USING GIN (document);
+
+CREATE FUNCTION refresh_expense_search(target INT) RETURNS VOID AS
+$$
+INSERT INTO expense_search (expense_id, document)
+SELECT e.id,
+       setweight(to_tsvector('simple', coalesce(e.description, '')), 'A') ||
+       setweight(to_tsvector('simple', coalesce(e.category_name, '')), 'B') ||
+       setweight(to_tsvector('simple', coalesce((SELECT string_agg(t.name, ' ')
+                                                 FROM expense_tags AS et
+                                                          JOIN tags AS t ON t.id = et.tag_id
+                                                 WHERE et.expense_id = e.id), '')), 'C')
+FROM expenses AS e
+WHERE e.id = target
+ON CONFLICT (expense_id) DO UPDATE SET document = EXCLUDED.document;
+$$ LANGUAGE sql;
+
+CREATE FUNCTION expenses_refresh_search() RETURNS TRIGGER AS
+$$
+BEGIN
+    PERFORM refresh_expense_search(NEW.id);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER expenses_refresh_search
+    AFTER INSERT OR UPDATE OF description, category_name
+    ON expenses
+    FOR EACH ROW
+EXECUTE FUNCTION expenses_refresh_search();
+
+CREATE FUNCTION expense_tags_refresh_search() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM refresh_expense_search(OLD.expense_id);
+        RETURN OLD;
+    END IF;
+    PERFORM refresh_expense_search(NEW.expense_id);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER expense_tags_refresh_search
+    AFTER INSERT OR DELETE
+    ON expense_tags
+    FOR EACH ROW
+EXECUTE FUNCTION expense_tags_refresh_search();
+
+CREATE FUNCTION tags_refresh_search() RETURNS TRIGGER AS
+$$
+BEGIN
+    PERFORM refresh_expense_search(et.expense_id)
+    FROM expense_tags AS et
+    WHERE et.tag_id = NEW.id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER tags_refresh_search
+    AFTER UPDATE OF name
+    ON tags
+    FOR EACH ROW
+EXECUTE FUNCTION tags_refresh_search();
+
+SELECT refresh_expense_search(id)
+FROM expenses;
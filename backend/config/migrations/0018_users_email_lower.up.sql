@@ -0,0 +1,2 @@
+-- Emails are looked up ignoring case.
+CREATE INDEX IF NOT EXISTS users_email_lower_idx ON users (lower(email));
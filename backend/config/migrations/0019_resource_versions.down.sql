@@ -0,0 +1,14 @@
+DROP TRIGGER IF EXISTS tags_bump_version ON tags;
+DROP FUNCTION IF EXISTS tags_bump_version();
+DROP TRIGGER IF EXISTS expense_tags_bump_version ON expense_tags;
+DROP FUNCTION IF EXISTS expense_tags_bump_version();
+DROP TRIGGER IF EXISTS category_bump_version ON category;
+DROP TRIGGER IF EXISTS expenses_bump_version ON expenses;
+DROP TRIGGER IF EXISTS budget_plan_bump_version ON budget_plan;
+DROP FUNCTION IF EXISTS bump_version();
+ALTER TABLE category
+    DROP COLUMN IF EXISTS version;
+ALTER TABLE expenses
+    DROP COLUMN IF EXISTS version;
+ALTER TABLE budget_plan
+    DROP COLUMN IF EXISTS version;
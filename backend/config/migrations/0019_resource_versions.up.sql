@@ -0,0 +1,71 @@
+-- Plans, expenses and categories carry a version, served as their ETag by
+-- the v2 API. Every update of a row bumps it, so that writes conditioned on
+-- the version a client read fail once anyone else changed the row.
+ALTER TABLE budget_plan
+    ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE expenses
+    ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE category
+    ADD COLUMN version INT NOT NULL DEFAULT 1;
+
+CREATE FUNCTION bump_version() RETURNS TRIGGER AS
+$$
+BEGIN
+    NEW.version := OLD.version + 1;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER budget_plan_bump_version
+    BEFORE UPDATE
+    ON budget_plan
+    FOR EACH ROW
+EXECUTE FUNCTION bump_version();
+
+CREATE TRIGGER expenses_bump_version
+    BEFORE UPDATE
+    ON expenses
+    FOR EACH ROW
+EXECUTE FUNCTION bump_version();
+
+CREATE TRIGGER category_bump_version
+    BEFORE UPDATE
+    ON category
+    FOR EACH ROW
+EXECUTE FUNCTION bump_version();
+
+-- The tags of an expense are part of it: tagging, untagging and renaming a
+-- tag change the version of the expenses concerned.
+CREATE FUNCTION expense_tags_bump_version() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        UPDATE expenses SET version = version WHERE id = OLD.expense_id;
+        RETURN OLD;
+    END IF;
+    UPDATE expenses SET version = version WHERE id = NEW.expense_id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER expense_tags_bump_version
+    AFTER INSERT OR DELETE
+    ON expense_tags
+    FOR EACH ROW
+EXECUTE FUNCTION expense_tags_bump_version();
+
+CREATE FUNCTION tags_bump_version() RETURNS TRIGGER AS
+$$
+BEGIN
+    UPDATE expenses
+    SET version = version
+    WHERE id IN (SELECT expense_id FROM expense_tags WHERE tag_id = NEW.id);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER tags_bump_version
+    AFTER UPDATE OF name
+    ON tags
+    FOR EACH ROW
+EXECUTE FUNCTION tags_bump_version();
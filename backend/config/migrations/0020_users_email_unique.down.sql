@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS users_email_lower_idx;
+CREATE INDEX IF NOT EXISTS users_email_lower_idx ON users (lower(email));
@@ -0,0 +1,10 @@
+-- Emails are unique ignoring case. Accounts whose emails differ only in case
+-- could not both log in, since emails are looked up ignoring case: the
+-- oldest keeps the address and the others are renamed out of its way,
+-- keeping their data, until they are merged or given an address by hand.
+UPDATE users AS u
+SET email = u.email || '.duplicate-' || u.id
+WHERE EXISTS (SELECT 1 FROM users AS o WHERE lower(o.email) = lower(u.email) AND o.id < u.id);
+
+DROP INDEX IF EXISTS users_email_lower_idx;
+CREATE UNIQUE INDEX users_email_lower_idx ON users (lower(email));
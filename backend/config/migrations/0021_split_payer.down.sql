@@ -0,0 +1,2 @@
+ALTER TABLE expenses
+    DROP CONSTRAINT IF EXISTS expenses_split_payer_check;
@@ -0,0 +1,15 @@
+-- A split expense must have a payer: its shares are owed to them, and
+-- balances only sum to zero when what was paid matches what is owed. Split
+-- expenses whose payer was deleted are put on the owner of their plan, as
+-- 0012 did for expenses recorded before payers.
+UPDATE expenses AS e
+SET paid_by = bp.user_id
+FROM budget_plan AS bp
+WHERE bp.id = e.budget_id
+  AND e.paid_by IS NULL
+  AND e.split_method IS NOT NULL;
+
+-- The check also stops ON DELETE SET NULL from dropping the payer of a
+-- split expense when their account is deleted.
+ALTER TABLE expenses
+    ADD CONSTRAINT expenses_split_payer_check CHECK (split_method IS NULL OR paid_by IS NOT NULL);
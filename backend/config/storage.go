@@ -0,0 +1,38 @@
+package config
+
+import (
+	"backend/storage"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// NewBlobStore builds the store for attachments selected by BLOB_STORE:
+// "local" (the default) writes below BLOB_DIR and "s3" uses the bucket
+// described by the S3_* variables.
+func NewBlobStore() (storage.BlobStore, error) {
+	switch kind := strings.ToLower(os.Getenv("BLOB_STORE")); kind {
+	case "", "local":
+		dir := os.Getenv("BLOB_DIR")
+		if dir == "" {
+			dir = "data/attachments"
+		}
+		log.Info().Str("dir", dir).Msg("Initializing local blob store")
+		return storage.NewLocalStore(dir)
+	case "s3":
+		cfg := storage.S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			PathStyle: os.Getenv("S3_PATH_STYLE") == "true",
+		}
+		log.Info().Str("endpoint", cfg.Endpoint).Str("bucket", cfg.Bucket).Msg("Initializing S3 blob store")
+		return storage.NewS3Store(cfg)
+	default:
+		return nil, fmt.Errorf("unknown BLOB_STORE %q", kind)
+	}
+}
@@ -0,0 +1,134 @@
+package controller
+
+import (
+	"backend/model"
+	"backend/service"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+)
+
+type AttachmentController interface {
+	Upload(w http.ResponseWriter, r *http.Request)
+	GetByExpense(w http.ResponseWriter, r *http.Request)
+	Download(w http.ResponseWriter, r *http.Request)
+	Delete(w http.ResponseWriter, r *http.Request)
+}
+
+type attachmentController struct {
+	service service.AttachmentService
+}
+
+func NewAttachmentController(svc *service.ServiceBase) AttachmentController {
+	return &attachmentController{
+		service: service.GetByType[service.AttachmentService](svc),
+	}
+}
+
+// Upload attaches the "file" part of a multipart form to the expense given
+// by the expense query parameter. The file is streamed, not buffered on disk.
+func (ctrl *attachmentController) Upload(w http.ResponseWriter, r *http.Request) {
+	expenseID, err := strconv.Atoi(r.URL.Query().Get("expense"))
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	// leave room for the multipart framing around the file
+	r.Body = http.MaxBytesReader(w, r.Body, model.MaxAttachmentSize+64<<10)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			writeBadRequest(w, r, errors.New("missing file part"))
+			return
+		}
+		if err != nil {
+			writeBadRequest(w, r, err)
+			return
+		}
+		if part.FormName() != "file" {
+			part.Close()
+			continue
+		}
+		attachment, err := ctrl.service.Upload(expenseID, part.FileName(), part, callerEmail(r))
+		part.Close()
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(attachment); err != nil {
+			writeServiceError(w, r, err)
+		}
+		return
+	}
+}
+
+func (ctrl *attachmentController) GetByExpense(w http.ResponseWriter, r *http.Request) {
+	expenseID, err := strconv.Atoi(r.URL.Query().Get("expense"))
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	v, err := ctrl.service.GetByExpense(expenseID, callerEmail(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	if v == nil {
+		v = []model.Attachment{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
+
+// Download streams the file of the attachment given by the id query parameter.
+func (ctrl *attachmentController) Download(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	attachment, blob, err := ctrl.service.Download(id, callerEmail(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	defer blob.Close()
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.SizeBytes, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, blob); err != nil {
+		// headers are already sent, so the client only sees a short body
+		log.Error().Err(err).Int("attachment_id", id).Msg("Failed to stream attachment")
+	}
+}
+
+func (ctrl *attachmentController) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	if err := ctrl.service.Delete(id, callerEmail(r)); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"backend/model"
+	"backend/service"
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+type BudgetPeriodController interface {
+	GetByPlan(w http.ResponseWriter, r *http.Request)
+	Current(w http.ResponseWriter, r *http.Request)
+}
+
+type budgetPeriodController struct {
+	service service.BudgetPeriodService
+}
+
+func NewBudgetPeriodController(svc *service.ServiceBase) BudgetPeriodController {
+	return &budgetPeriodController{
+		service: service.GetByType[service.BudgetPeriodService](svc),
+	}
+}
+
+func (ctrl *budgetPeriodController) GetByPlan(w http.ResponseWriter, r *http.Request) {
+	planID, err := strconv.Atoi(r.URL.Query().Get("plan"))
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	v, err := ctrl.service.GetByPlan(planID, callerEmail(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	if v == nil {
+		v = []model.BudgetPeriod{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
+
+func (ctrl *budgetPeriodController) Current(w http.ResponseWriter, r *http.Request) {
+	planID, err := strconv.Atoi(r.URL.Query().Get("plan"))
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	period, err := ctrl.service.Current(planID, callerEmail(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(period); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
@@ -16,6 +16,7 @@ type BudgetPlanController interface {
 	GetByUser(w http.ResponseWriter, r *http.Request)
 	Delete(w http.ResponseWriter, r *http.Request)
 	UpdateAmount(w http.ResponseWriter, r *http.Request)
+	History(w http.ResponseWriter, r *http.Request)
 	Update(w http.ResponseWriter, r *http.Request)
 }
 
@@ -34,113 +35,179 @@ func NewBudgetPlanController(svc *service.ServiceBase) BudgetPlanController {
 func (ctrl *budgetPlanController) CreatePlan(w http.ResponseWriter, r *http.Request) {
 	var plan request.BudgetPlanRequest
 
-	if err := json.NewDecoder(r.Body).Decode(&plan); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-	}
-	expenses := make([]*model.Expense, len(plan.Expenses))
-	for i, id := range plan.Expenses {
-		expenses[i] = &model.Expense{ID: id}
-	}
-
-	budgetPlat := model.BudgetPlan{
-		Name:        plan.Name,
-		TotalAmount: 0,
-		Description: plan.Description,
-		UserID:      plan.UserID,
-		Expenses:    expenses,
-		CreatedDate: time.Now(),
+	if err := decodeJSON(w, r, &plan); err != nil {
+		writeBadRequest(w, r, err)
+		return
 	}
+	budgetPlat := planOf(plan)
 	email, ok := r.Context().Value("email").(string)
 	if !ok {
 		log.Println(ok)
-		http.Error(w, "Email not found", http.StatusUnauthorized)
+		writeServiceError(w, r, service.ErrUnauthorized)
 		return
 	}
 	err := ctrl.service.Create(&budgetPlat, email)
 	if err != nil {
 		log.Println(err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, r, err)
+		return
 	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	if err := json.NewEncoder(w).Encode(budgetPlat); err != nil {
 		log.Println(err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, r, err)
+	}
+}
+
+// planOf builds the plan created by a request. Its total is read in the base
+// currency of the plan, and opens the plan as its first adjustment.
+func planOf(plan request.BudgetPlanRequest) model.BudgetPlan {
+	expenses := make([]*model.Expense, len(plan.Expenses))
+	for i, id := range plan.Expenses {
+		expenses[i] = &model.Expense{ID: id}
+	}
+	budgetPlan := model.BudgetPlan{
+		Name:        plan.Name,
+		TotalAmount: plan.TotalAmount.In(plan.BaseCurrency),
+		Description: plan.Description,
+		UserID:      plan.UserID,
+		Expenses:    expenses,
+		CreatedDate: plan.CreatedDate,
+		Period:      model.PeriodType(plan.Period),
+		PeriodDays:  plan.PeriodDays,
+		EndDate:     plan.EndDate,
+		Rollover:    plan.Rollover,
 	}
+	if budgetPlan.CreatedDate.IsZero() {
+		budgetPlan.CreatedDate = time.Now()
+	}
+	return budgetPlan
 }
+
+// GetByUser lists the plans of the caller, narrowed by the optional name,
+// role and period query parameters. The expenses parameter shows the
+// expenses of each plan as a summary (default), in full or not at all. It
+// is paginated and sorted by the limit, cursor and sort parameters of every list.
 func (ctrl *budgetPlanController) GetByUser(w http.ResponseWriter, r *http.Request) {
 	email, ok := r.Context().Value("email").(string)
 	if !ok {
 		log.Println(ok)
-		http.Error(w, "Email not found", http.StatusUnauthorized)
+		writeServiceError(w, r, service.ErrUnauthorized)
 		return
 	}
 	user, err := ctrl.userService.FindByEmail(email)
 	if err != nil {
 		log.Println(err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, r, err)
 		return
 	}
-
-	plans, err := ctrl.service.FindByUser(user.ID)
+	list, err := listQuery(r)
 	if err != nil {
-		log.Println(err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeBadRequest(w, r, err)
 		return
 	}
-	if plans == nil {
-		plans = []model.BudgetPlan{}
-	}
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-
-	if err = json.NewEncoder(w).Encode(plans); err != nil {
+	plans, err := ctrl.service.FindByUser(user.ID, planQuery(r), list)
+	if err != nil {
 		log.Println(err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, r, err)
 		return
 	}
+	writeList(w, r, plans, list)
+}
+
+// planQuery reads the query parameters that narrow the plans of the caller.
+func planQuery(r *http.Request) service.PlanQuery {
+	values := r.URL.Query()
+	return service.PlanQuery{
+		Name:     values.Get("name"),
+		Role:     model.PlanRole(values.Get("role")),
+		Period:   model.PeriodType(values.Get("period")),
+		Expenses: values.Get("expenses"),
+	}
 }
 
 func (ctrl *budgetPlanController) Delete(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(r.URL.Query().Get("id"))
 	if err != nil {
 		log.Println(err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeBadRequest(w, r, err)
+		return
 	}
-	err = ctrl.service.Delete(id)
+	err = ctrl.service.Delete(id, 0, callerEmail(r))
 	if err != nil {
 		log.Println(err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, r, err)
+		return
 	}
 	w.WriteHeader(http.StatusOK)
 }
 func (ctrl *budgetPlanController) UpdateAmount(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		ID     int     `json:"id"`
-		Amount float64 `json:"amount"`
-		Add    bool    `json:"add"`
+	var req request.PlanAmountRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		log.Println(err)
+		writeBadRequest(w, r, err)
+		return
+	}
+	adjustment, err := ctrl.service.UpdateAmount(&req, callerEmail(r))
+	if err != nil {
+		log.Println(err)
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(adjustment); err != nil {
+		log.Println(err)
+		writeServiceError(w, r, err)
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+}
+
+// History lists the changes to the total of the plan given by the id query parameter.
+func (ctrl *budgetPlanController) History(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
 		log.Println(err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeBadRequest(w, r, err)
+		return
 	}
-	err := ctrl.service.UpdateAmount(req.ID, req.Amount, req.Add)
+	adjustments, err := ctrl.service.History(id, callerEmail(r))
 	if err != nil {
 		log.Println(err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, r, err)
+		return
 	}
+	if adjustments == nil {
+		adjustments = []model.PlanAdjustment{}
+	}
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(adjustments); err != nil {
+		log.Println(err)
+		writeServiceError(w, r, err)
+	}
 }
 func (ctrl *budgetPlanController) Update(w http.ResponseWriter, r *http.Request) {
-	var plan model.BudgetPlan
-	if err := json.NewDecoder(r.Body).Decode(&plan); err != nil {
+	var req request.BudgetPlanUpdateRequest
+	if err := decodeJSON(w, r, &req); err != nil {
 		log.Println(err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeBadRequest(w, r, err)
+		return
+	}
+	plan := model.BudgetPlan{
+		ID:          req.ID,
+		Name:        req.Name,
+		Description: req.Description,
+		Period:      model.PeriodType(req.Period),
+		PeriodDays:  req.PeriodDays,
+		EndDate:     req.EndDate,
+		Rollover:    req.Rollover,
 	}
-	err := ctrl.service.Update(&plan)
+	err := ctrl.service.Update(&plan, callerEmail(r))
 	if err != nil {
 		log.Println(err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, r, err)
+		return
 	}
 	w.WriteHeader(http.StatusOK)
 }
@@ -0,0 +1,220 @@
+package controller
+
+import (
+	"backend/model"
+	"backend/model/request"
+	"backend/service"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// BudgetPlanV2Controller serves plans as resources of the v2 API, at
+// /plans/{id}. Single plans carry an ETag; PATCH and DELETE honour If-Match.
+type BudgetPlanV2Controller interface {
+	List(w http.ResponseWriter, r *http.Request)
+	Create(w http.ResponseWriter, r *http.Request)
+	Get(w http.ResponseWriter, r *http.Request)
+	Patch(w http.ResponseWriter, r *http.Request)
+	Delete(w http.ResponseWriter, r *http.Request)
+	Adjust(w http.ResponseWriter, r *http.Request)
+	Adjustments(w http.ResponseWriter, r *http.Request)
+}
+
+type budgetPlanV2Controller struct {
+	service     service.BudgetPlanService
+	userService service.UserService
+}
+
+func NewBudgetPlanV2Controller(svc *service.ServiceBase) BudgetPlanV2Controller {
+	return &budgetPlanV2Controller{
+		service:     service.GetByType[service.BudgetPlanService](svc),
+		userService: service.GetByType[service.UserService](svc),
+	}
+}
+
+// List lists the plans of the caller like GetByUser of the v1 API, without
+// their expenses unless the expenses parameter asks for them.
+func (ctrl *budgetPlanV2Controller) List(w http.ResponseWriter, r *http.Request) {
+	user, err := ctrl.userService.FindByEmail(callerEmail(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	list, err := listQuery(r)
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	q := planQuery(r)
+	if q.Expenses == "" {
+		q.Expenses = service.PlanExpensesNone
+	}
+	plans, err := ctrl.service.FindByUser(user.ID, q, list)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeList(w, r, plans, list)
+}
+
+func (ctrl *budgetPlanV2Controller) Create(w http.ResponseWriter, r *http.Request) {
+	var req request.BudgetPlanRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	plan := planOf(req)
+	email := callerEmail(r)
+	if err := ctrl.service.Create(&plan, email); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	created, err := ctrl.service.Get(plan.ID, email)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeCreated(w, r, plan.ID, created.Version, created)
+}
+
+func (ctrl *budgetPlanV2Controller) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	plan, err := ctrl.service.Get(id, callerEmail(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeResource(w, r, http.StatusOK, plan.Version, plan)
+}
+
+// Patch edits the fields of a plan given in the body, keeping the others.
+func (ctrl *budgetPlanV2Controller) Patch(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	var patch request.BudgetPlanPatchRequest
+	if err := decodeJSON(w, r, &patch); err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	email := callerEmail(r)
+	plan, err := ctrl.service.Get(id, email)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	if _, ok := ifMatchVersion(w, r, plan.Version); !ok {
+		return
+	}
+	// the write is conditioned on the version read above even without
+	// If-Match, so that a concurrent change is not overwritten
+	if patch.Name != nil {
+		plan.Name = *patch.Name
+	}
+	if patch.Description != nil {
+		plan.Description = *patch.Description
+	}
+	if patch.Period != nil {
+		plan.Period = model.PeriodType(*patch.Period)
+	}
+	if patch.PeriodDays != nil {
+		plan.PeriodDays = *patch.PeriodDays
+	}
+	if patch.EndDate != nil {
+		plan.EndDate = patch.EndDate
+	}
+	if patch.Rollover != nil {
+		plan.Rollover = *patch.Rollover
+	}
+	if err := ctrl.service.Update(plan, email); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	updated, err := ctrl.service.Get(id, email)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeResource(w, r, http.StatusOK, updated.Version, updated)
+}
+
+func (ctrl *budgetPlanV2Controller) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	email := callerEmail(r)
+	plan, err := ctrl.service.Get(id, email)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	version, ok := ifMatchVersion(w, r, plan.Version)
+	if !ok {
+		return
+	}
+	if err := ctrl.service.Delete(id, version, email); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Adjust adds to or subtracts from the total of a plan, like UpdateAmount of
+// the v1 API. The id of the body may be left out.
+func (ctrl *budgetPlanV2Controller) Adjust(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	req := request.PlanAmountRequest{ID: id}
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	if req.ID != id {
+		writeBadRequest(w, r, fmt.Errorf("id must be %d, the plan of the path", id))
+		return
+	}
+	adjustment, err := ctrl.service.UpdateAmount(&req, callerEmail(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(adjustment); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
+
+// Adjustments lists the changes to the total of a plan, newest first.
+func (ctrl *budgetPlanV2Controller) Adjustments(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	adjustments, err := ctrl.service.History(id, callerEmail(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	if adjustments == nil {
+		adjustments = []model.PlanAdjustment{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(adjustments); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
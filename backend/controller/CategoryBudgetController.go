@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"backend/model"
+	"backend/model/request"
+	"backend/service"
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+type CategoryBudgetController interface {
+	Save(w http.ResponseWriter, r *http.Request)
+	GetByPlan(w http.ResponseWriter, r *http.Request)
+	Delete(w http.ResponseWriter, r *http.Request)
+	Status(w http.ResponseWriter, r *http.Request)
+}
+
+type categoryBudgetController struct {
+	service service.CategoryBudgetService
+}
+
+func NewCategoryBudgetController(svc *service.ServiceBase) CategoryBudgetController {
+	return &categoryBudgetController{
+		service: service.GetByType[service.CategoryBudgetService](svc),
+	}
+}
+
+// Save creates or replaces the allocation of a category in a plan.
+func (ctrl *categoryBudgetController) Save(w http.ResponseWriter, r *http.Request) {
+	var req request.CategoryBudgetRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	budget, err := ctrl.service.Save(&req, callerEmail(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(budget); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
+
+func (ctrl *categoryBudgetController) GetByPlan(w http.ResponseWriter, r *http.Request) {
+	planID, err := strconv.Atoi(r.URL.Query().Get("plan"))
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	v, err := ctrl.service.GetByPlan(planID, callerEmail(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	if v == nil {
+		v = []model.CategoryBudget{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
+
+// Delete removes the allocation given by the plan and category query parameters.
+func (ctrl *categoryBudgetController) Delete(w http.ResponseWriter, r *http.Request) {
+	planID, err := strconv.Atoi(r.URL.Query().Get("plan"))
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	categoryID, err := strconv.Atoi(r.URL.Query().Get("category"))
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	if err := ctrl.service.Delete(planID, categoryID, callerEmail(r)); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// Status reads the same plan, period_id, from and to query parameters as the reports.
+func (ctrl *categoryBudgetController) Status(w http.ResponseWriter, r *http.Request) {
+	q, err := reportQuery(r)
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	status, err := ctrl.service.Status(q, callerEmail(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
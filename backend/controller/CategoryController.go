@@ -5,6 +5,7 @@ import (
 	"backend/model/request"
 	"backend/service"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
 )
@@ -29,95 +30,140 @@ func NewCategoryController(factory *service.ServiceBase) CategoryController {
 }
 
 func (ctrl *categoryController) CreateCategory(w http.ResponseWriter, r *http.Request) {
-	var c *request.CategoryRequest
-	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
-		http.Error(w, "Invalid Body", http.StatusBadRequest)
+	var c request.CategoryRequest
+	if err := decodeJSON(w, r, &c); err != nil {
+		writeBadRequest(w, r, err)
+		return
 	}
 
 	category := model.Category{
-		Name: c.Name,
+		Name:     c.Name,
+		ParentID: c.ParentID,
 	}
 
-	err := ctrl.service.NewCategory(&category)
+	err := ctrl.service.NewCategory(&category, callerEmail(r))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeServiceError(w, r, err)
+		return
 	}
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 
 	if err := json.NewEncoder(w).Encode(&category); err != nil {
-		http.Error(w, "response failure: "+err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, r, err)
 	}
 }
 
+// GetAll lists the caller's categories, flat with their paths or, with
+// ?tree=true, as top-level categories nesting their subcategories. The flat
+// list is narrowed by the optional parent, 0 for top-level categories, and
+// name query parameters, and is paginated and sorted by the limit, cursor
+// and sort parameters of every list.
 func (ctrl *categoryController) GetAll(w http.ResponseWriter, r *http.Request) {
-	c, err := ctrl.service.FindAll()
+	if tree, _ := strconv.ParseBool(r.URL.Query().Get("tree")); tree {
+		c, err := ctrl.service.Tree(callerEmail(r))
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(c); err != nil {
+			writeServiceError(w, r, err)
+		}
+		return
+	}
+
+	list, err := listQuery(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeBadRequest(w, r, err)
+		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(c); err != nil {
-		http.Error(w, "response failure: "+err.Error(), http.StatusInternalServerError)
+	q := service.CategoryQuery{Name: r.URL.Query().Get("name")}
+	if raw := r.URL.Query().Get("parent"); raw != "" {
+		parentID, err := strconv.Atoi(raw)
+		if err != nil {
+			writeBadRequest(w, r, fmt.Errorf("invalid parent: %w", err))
+			return
+		}
+		q.ParentID = &parentID
 	}
-
+	page, err := ctrl.service.FindAll(q, list, callerEmail(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeList(w, r, page, list)
 }
 
 func (ctrl *categoryController) FindByName(w http.ResponseWriter, r *http.Request) {
 	name := r.URL.Query().Get("name")
 
-	category, err := ctrl.service.FindByName(name)
+	category, err := ctrl.service.FindByName(name, callerEmail(r))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeServiceError(w, r, err)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(&category); err != nil {
-		http.Error(w, "response failure: "+err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, r, err)
 	}
 }
 
 func (ctrl *categoryController) FindById(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(r.URL.Query().Get("id"))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeBadRequest(w, r, err)
+		return
 	}
-	category, err := ctrl.service.FindById(id)
+	category, err := ctrl.service.FindById(id, callerEmail(r))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeServiceError(w, r, err)
+		return
 	}
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(&category); err != nil {
-		http.Error(w, "response failure: "+err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, r, err)
 	}
 }
 
 func (ctrl *categoryController) Delete(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(r.URL.Query().Get("id"))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeBadRequest(w, r, err)
+		return
 	}
-	err = ctrl.service.Delete(id)
+	err = ctrl.service.Delete(id, 0, callerEmail(r))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeServiceError(w, r, err)
+		return
 	}
 	w.WriteHeader(http.StatusOK)
 }
 
 func (ctrl *categoryController) Update(w http.ResponseWriter, r *http.Request) {
-	var category *request.CategoryRequest
-	if err := json.NewDecoder(r.Body).Decode(&category); err != nil {
-		http.Error(w, "Invalid Body", http.StatusBadRequest)
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	var category request.CategoryRequest
+	if err := decodeJSON(w, r, &category); err != nil {
+		writeBadRequest(w, r, err)
+		return
 	}
 	updated := model.Category{
-		Name: category.Name,
+		ID:       id,
+		Name:     category.Name,
+		ParentID: category.ParentID,
 	}
-	err := ctrl.service.Update(&updated)
+	err = ctrl.service.Update(&updated, callerEmail(r))
 	if err != nil {
-
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeServiceError(w, r, err)
+		return
 	}
 	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(&category); err != nil {
-		http.Error(w, "response failure: "+err.Error(), http.StatusInternalServerError)
+	if err := json.NewEncoder(w).Encode(&updated); err != nil {
+		writeServiceError(w, r, err)
 	}
 }
@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"backend/model"
+	"backend/model/request"
+	"backend/service"
+	"net/http"
+)
+
+// CategoryV2Controller serves categories as resources of the v2 API, at
+// /categories/{id}. Single categories carry an ETag; PATCH and DELETE
+// honour If-Match. The collection is listed by GetAll of CategoryController.
+type CategoryV2Controller interface {
+	Create(w http.ResponseWriter, r *http.Request)
+	Get(w http.ResponseWriter, r *http.Request)
+	Patch(w http.ResponseWriter, r *http.Request)
+	Delete(w http.ResponseWriter, r *http.Request)
+}
+
+type categoryV2Controller struct {
+	service service.CategoryService
+}
+
+func NewCategoryV2Controller(factory *service.ServiceBase) CategoryV2Controller {
+	return &categoryV2Controller{
+		service: service.GetByType[service.CategoryService](factory),
+	}
+}
+
+func (ctrl *categoryV2Controller) Create(w http.ResponseWriter, r *http.Request) {
+	var req request.CategoryRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	category := model.Category{
+		Name:     req.Name,
+		ParentID: req.ParentID,
+	}
+	email := callerEmail(r)
+	if err := ctrl.service.NewCategory(&category, email); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	created, err := ctrl.service.FindById(category.ID, email)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeCreated(w, r, category.ID, created.Version, created)
+}
+
+func (ctrl *categoryV2Controller) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	category, err := ctrl.service.FindById(id, callerEmail(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeResource(w, r, http.StatusOK, category.Version, category)
+}
+
+// Patch renames or moves a category, keeping the fields left out of the body.
+func (ctrl *categoryV2Controller) Patch(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	var patch request.CategoryPatchRequest
+	if err := decodeJSON(w, r, &patch); err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	email := callerEmail(r)
+	category, err := ctrl.service.FindById(id, email)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	if _, ok := ifMatchVersion(w, r, category.Version); !ok {
+		return
+	}
+	// the write is conditioned on the version read above even without
+	// If-Match, so that a concurrent change is not overwritten
+	if patch.Name != nil {
+		category.Name = *patch.Name
+	}
+	if patch.ParentID != nil {
+		category.ParentID = patch.ParentID
+		if *patch.ParentID == 0 {
+			category.ParentID = nil
+		}
+	}
+	if err := ctrl.service.Update(category, email); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	updated, err := ctrl.service.FindById(id, email)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeResource(w, r, http.StatusOK, updated.Version, updated)
+}
+
+func (ctrl *categoryV2Controller) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	email := callerEmail(r)
+	category, err := ctrl.service.FindById(id, email)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	version, ok := ifMatchVersion(w, r, category.Version)
+	if !ok {
+		return
+	}
+	if err := ctrl.service.Delete(id, version, email); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
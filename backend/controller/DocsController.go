@@ -0,0 +1,31 @@
+package controller
+
+import (
+	"backend/openapi"
+	"net/http"
+)
+
+type DocsController interface {
+	Spec(w http.ResponseWriter, r *http.Request)
+	Page(w http.ResponseWriter, r *http.Request)
+}
+
+type docsController struct{}
+
+func NewDocsController() DocsController {
+	return &docsController{}
+}
+
+// Spec writes the OpenAPI document of the API.
+func (ctrl *docsController) Spec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(openapi.JSON())
+}
+
+// Page writes a page that browses the OpenAPI document.
+func (ctrl *docsController) Page(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(openapi.DocsPage)
+}
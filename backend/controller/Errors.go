@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"backend/middleware"
+	"backend/model"
+	"backend/model/request"
+	"backend/service"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// callerEmail returns the email that middleware.JWTAuth stored in the request context.
+func callerEmail(r *http.Request) string {
+	email, _ := r.Context().Value("email").(string)
+	return email
+}
+
+// callerSession returns the session ID that middleware.JWTAuth stored in the request context.
+func callerSession(r *http.Request) int {
+	session, _ := r.Context().Value("session").(int)
+	return session
+}
+
+// kinds maps the sentinel errors of the services to HTTP status codes and
+// to the code reported for errors that carry no code of their own.
+var kinds = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{service.ErrNotFound, http.StatusNotFound, "not_found"},
+	{service.ErrInvalid, http.StatusBadRequest, "validation_failed"},
+	{service.ErrForbidden, http.StatusForbidden, "forbidden"},
+	{service.ErrUnauthorized, http.StatusUnauthorized, "unauthorized"},
+	{service.ErrConflict, http.StatusConflict, "conflict"},
+	{service.ErrPreconditionFailed, http.StatusPreconditionFailed, "precondition_failed"},
+}
+
+// writeServiceError maps the errors returned by services to HTTP status
+// codes and writes them as a middleware.ErrorResponse. Errors that are not
+// domain errors are logged and reported without their text, so that
+// database and other internal messages never reach clients.
+func writeServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	err = service.Classify(err)
+	var domain *service.Error
+	if errors.As(err, &domain) {
+		for _, k := range kinds {
+			if errors.Is(domain.Kind, k.err) {
+				middleware.WriteError(w, r, k.status, domain.Code, domain.Message, domain.Details)
+				return
+			}
+		}
+	}
+	for _, k := range kinds {
+		if errors.Is(err, k.err) {
+			middleware.WriteError(w, r, k.status, k.code, domainMessage(err, k.err), nil)
+			return
+		}
+	}
+	writeInternalError(w, r, err)
+}
+
+// domainMessage returns the text of an error wrapping kind, without the
+// text of kind itself in front of it when something more specific follows.
+func domainMessage(err error, kind error) string {
+	text := err.Error()
+	if prefix := kind.Error() + ": "; len(text) > len(prefix) && text[:len(prefix)] == prefix {
+		return text[len(prefix):]
+	}
+	return text
+}
+
+// writeInternalError logs err along with the request ID and answers with a
+// generic message.
+func writeInternalError(w http.ResponseWriter, r *http.Request, err error) {
+	log.Error().Err(err).Str("request_id", middleware.GetRequestID(r)).Str("method", r.Method).Str("path", r.URL.Path).Msg("Request failed")
+	middleware.WriteError(w, r, http.StatusInternalServerError, "internal_error", "", nil)
+}
+
+// writeBadRequest reports a request whose parameters or body could not be
+// read or break the rules of the request. Errors of the JSON decoder are
+// described without Go type names.
+func writeBadRequest(w http.ResponseWriter, r *http.Request, err error) {
+	var (
+		syntaxErr   *json.SyntaxError
+		typeErr     *json.UnmarshalTypeError
+		tooLargeErr *http.MaxBytesError
+		numErr      *strconv.NumError
+		invalid     *request.ValidationError
+	)
+	switch {
+	case errors.As(err, &invalid):
+		middleware.WriteError(w, r, http.StatusBadRequest, "validation_failed", invalid.Error(), map[string]interface{}{"fields": invalid.Fields})
+	case errors.As(err, &tooLargeErr):
+		middleware.WriteError(w, r, http.StatusRequestEntityTooLarge, "payload_too_large",
+			fmt.Sprintf("the request body is larger than %d bytes", tooLargeErr.Limit), map[string]interface{}{"limit": tooLargeErr.Limit})
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		middleware.WriteError(w, r, http.StatusBadRequest, "malformed_request", "the request body is empty or incomplete", nil)
+	case errors.As(err, &syntaxErr):
+		middleware.WriteError(w, r, http.StatusBadRequest, "malformed_request",
+			fmt.Sprintf("the request body is not valid JSON at offset %d", syntaxErr.Offset), map[string]interface{}{"offset": syntaxErr.Offset})
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		middleware.WriteError(w, r, http.StatusBadRequest, "malformed_request",
+			fmt.Sprintf("field %s must be %s", typeErr.Field, jsonType(typeErr.Type.Kind())), map[string]interface{}{"field": typeErr.Field})
+	case errors.As(err, &typeErr):
+		middleware.WriteError(w, r, http.StatusBadRequest, "malformed_request",
+			fmt.Sprintf("the request body must be %s", jsonType(typeErr.Type.Kind())), nil)
+	case strings.HasPrefix(err.Error(), unknownFieldPrefix):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), unknownFieldPrefix), `"`)
+		middleware.WriteError(w, r, http.StatusBadRequest, "unknown_field",
+			fmt.Sprintf("field %s is not accepted", field), map[string]interface{}{"field": field})
+	case errors.As(err, &numErr):
+		text := strings.Replace(err.Error(), numErr.Error(), fmt.Sprintf("%q is not a valid number", numErr.Num), 1)
+		middleware.WriteError(w, r, http.StatusBadRequest, "malformed_request", text, map[string]interface{}{"value": numErr.Num})
+	case errors.Is(err, model.ErrInvalidCursor):
+		middleware.WriteError(w, r, http.StatusBadRequest, "invalid_cursor", err.Error(), nil)
+	default:
+		middleware.WriteError(w, r, http.StatusBadRequest, "malformed_request", err.Error(), nil)
+	}
+}
+
+// unknownFieldPrefix starts the errors of a JSON decoder that disallows
+// unknown fields; encoding/json has no type for them.
+const unknownFieldPrefix = "json: unknown field "
+
+// jsonType names the JSON type a value of kind is decoded from.
+func jsonType(kind reflect.Kind) string {
+	switch kind {
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.String:
+		return "a string"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	}
+	return "another type"
+}
@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"backend/model"
+	"backend/model/request"
+	"backend/service"
+	"encoding/json"
+	"net/http"
+)
+
+type ExchangeRateController interface {
+	Create(w http.ResponseWriter, r *http.Request)
+	Import(w http.ResponseWriter, r *http.Request)
+	List(w http.ResponseWriter, r *http.Request)
+}
+
+type exchangeRateController struct {
+	service service.ExchangeRateService
+}
+
+func NewExchangeRateController(svc *service.ServiceBase) ExchangeRateController {
+	return &exchangeRateController{
+		service: service.GetByType[service.ExchangeRateService](svc),
+	}
+}
+
+func (ctrl *exchangeRateController) Create(w http.ResponseWriter, r *http.Request) {
+	var req request.ExchangeRateRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	rate := model.ExchangeRate{Base: req.Base, Quote: req.Quote, Rate: req.Rate, Date: req.Date, Source: "manual"}
+	if err := ctrl.service.Create(&rate); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(rate); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
+
+// Import stores the rates of a CSV body with the columns date,base,quote,rate.
+func (ctrl *exchangeRateController) Import(w http.ResponseWriter, r *http.Request) {
+	count, err := ctrl.service.Import(r.Body)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(map[string]int{"imported": count}); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
+
+func (ctrl *exchangeRateController) List(w http.ResponseWriter, r *http.Request) {
+	rates, err := ctrl.service.List(r.URL.Query().Get("base"), r.URL.Query().Get("quote"))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	if rates == nil {
+		rates = []model.ExchangeRate{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(rates); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
@@ -5,14 +5,20 @@ import (
 	"backend/model/request"
 	"backend/service"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type ExpenseController interface {
 	NewExpense(w http.ResponseWriter, r *http.Request)
 	GetByPlan(w http.ResponseWriter, r *http.Request)
 	GetByCategory(w http.ResponseWriter, r *http.Request)
+	Search(w http.ResponseWriter, r *http.Request)
 	Update(w http.ResponseWriter, r *http.Request)
 	Delete(w http.ResponseWriter, r *http.Request)
 }
@@ -27,83 +33,259 @@ func NewExpenseController(svc *service.ServiceBase) ExpenseController {
 }
 
 func (ctrl *expenseController) NewExpense(w http.ResponseWriter, r *http.Request) {
-	var e *request.ExpenseRequest
-	if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	var e request.ExpenseRequest
+	if err := decodeJSON(w, r, &e); err != nil {
+		writeBadRequest(w, r, err)
+		return
 	}
-	newExpense := model.Expense{
-		Amount:       e.Amount,
+	newExpense := expenseOf(e)
+	err := ctrl.service.NewExpense(&newExpense, callerEmail(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(newExpense); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
+
+// expenseOf builds the expense created by a request.
+func expenseOf(e request.ExpenseRequest) model.Expense {
+	return model.Expense{
+		Amount:       e.Amount.In(e.Currency),
 		Description:  e.Description,
 		CategoryID:   e.CategoryID,
 		CategoryName: e.CategoryName,
 		Date:         e.Date,
 		IsRecurring:  e.IsRecurring,
 		BudgetID:     e.BudgetID,
+		PaidBy:       e.PaidBy,
+		Tags:         e.Tags,
 	}
-	err := ctrl.service.NewExpense(&newExpense)
+}
+
+// GetByPlan lists the expenses of the plan given by the id query parameter,
+// limited to one of its periods by the optional period_id parameter or to
+// the days from and to (YYYY-MM-DD), and narrowed by the optional category,
+// paid_by, recurring, min_amount and max_amount, in the plan's base
+// currency, and tags and match parameters. It is paginated and sorted by
+// the limit, cursor and sort parameters of every list.
+func (ctrl *expenseController) GetByPlan(w http.ResponseWriter, r *http.Request) {
+	q, err := expenseQuery(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeBadRequest(w, r, err)
+		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	if err := json.NewEncoder(w).Encode(newExpense); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	list, err := listQuery(r)
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	page, err := ctrl.service.GetByPlan(q, list, callerEmail(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
 	}
+	writeList(w, r, page, list)
 }
-func (ctrl *expenseController) GetByPlan(w http.ResponseWriter, r *http.Request) {
+
+// expenseQuery reads the query parameters of GetByPlan.
+func expenseQuery(r *http.Request) (service.ExpenseQuery, error) {
 	planID, err := strconv.Atoi(r.URL.Query().Get("id"))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		return service.ExpenseQuery{}, fmt.Errorf("invalid id: %w", err)
 	}
-	v, err := ctrl.service.GetByPlan(planID)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	return expenseFilter(r, planID)
+}
+
+// expenseFilter reads the query parameters that narrow the expenses of the
+// plan planID.
+func expenseFilter(r *http.Request, planID int) (service.ExpenseQuery, error) {
+	var (
+		q   service.ExpenseQuery
+		err error
+	)
+	values := r.URL.Query()
+	q.PlanID = planID
+	for name, target := range map[string]*int{"period_id": &q.PeriodID, "category": &q.CategoryID, "paid_by": &q.PaidBy} {
+		raw := values.Get(name)
+		if raw == "" {
+			continue
+		}
+		if *target, err = strconv.Atoi(raw); err != nil {
+			return q, fmt.Errorf("invalid %s: %w", name, err)
+		}
 	}
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(v); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if raw := values.Get("recurring"); raw != "" {
+		recurring, err := strconv.ParseBool(raw)
+		if err != nil {
+			return q, fmt.Errorf("invalid recurring: %w", err)
+		}
+		q.Recurring = &recurring
 	}
+	if err := dayParams(values, map[string]**time.Time{"from": &q.From, "to": &q.To}); err != nil {
+		return q, err
+	}
+	if err := amountParams(values, "", map[string]**model.Money{"min_amount": &q.MinAmount, "max_amount": &q.MaxAmount}); err != nil {
+		return q, err
+	}
+	q.Tags, err = tagFilter(r)
+	return q, err
 }
+
+// tagFilter reads a comma separated list of tag names from the tags query
+// parameter. match=all requires every tag; the default, any, requires one.
+func tagFilter(r *http.Request) (model.TagFilter, error) {
+	var filter model.TagFilter
+	values := r.URL.Query()
+	for _, name := range strings.Split(values.Get("tags"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			filter.Tags = append(filter.Tags, name)
+		}
+	}
+	switch values.Get("match") {
+	case "", "any":
+	case "all":
+		filter.All = true
+	default:
+		return filter, errors.New("invalid match: must be any or all")
+	}
+	return filter, nil
+}
+
+// GetByCategory lists the expenses of the category given by the id query
+// parameter and of its subcategories, paginated and sorted by the limit,
+// cursor and sort parameters of every list.
 func (ctrl *expenseController) GetByCategory(w http.ResponseWriter, r *http.Request) {
 	categoryID, err := strconv.Atoi(r.URL.Query().Get("id"))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeBadRequest(w, r, err)
+		return
+	}
+	list, err := listQuery(r)
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	page, err := ctrl.service.GetByCategory(categoryID, list, callerEmail(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeList(w, r, page, list)
+}
+
+// Search runs a full-text search given by the q query parameter, narrowed by
+// the optional plan, category, from and to (YYYY-MM-DD), min_amount,
+// max_amount and currency parameters. A page holds limit results; the
+// next_cursor of a page is passed back as cursor to get the next one.
+func (ctrl *expenseController) Search(w http.ResponseWriter, r *http.Request) {
+	search, err := expenseSearch(r)
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
 	}
-	v, err := ctrl.service.GetByCategory(categoryID)
+	page, err := ctrl.service.Search(search, callerEmail(r))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, r, err)
+		return
 	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(v); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := json.NewEncoder(w).Encode(page); err != nil {
+		writeServiceError(w, r, err)
 	}
 }
+
+// expenseSearch reads the query parameters of Search.
+func expenseSearch(r *http.Request) (model.ExpenseSearch, error) {
+	values := r.URL.Query()
+	search := model.ExpenseSearch{
+		Query:    values.Get("q"),
+		Currency: values.Get("currency"),
+	}
+	for name, target := range map[string]*int{"plan": &search.PlanID, "category": &search.CategoryID, "limit": &search.Limit} {
+		raw := values.Get(name)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return search, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		*target = n
+	}
+	if err := dayParams(values, map[string]**time.Time{"from": &search.From, "to": &search.To}); err != nil {
+		return search, err
+	}
+	amounts := map[string]**model.Money{"min_amount": &search.MinAmount, "max_amount": &search.MaxAmount}
+	if err := amountParams(values, search.Currency, amounts); err != nil {
+		return search, err
+	}
+	if raw := values.Get("cursor"); raw != "" {
+		cursor, err := model.ParseSearchCursor(raw)
+		if err != nil {
+			return search, err
+		}
+		search.After = cursor
+	}
+	return search, nil
+}
+
+// amountParams reads the optional decimal query parameters named by targets
+// as amounts in currency, or without a currency when it is empty.
+func amountParams(values url.Values, currency string, targets map[string]**model.Money) error {
+	for name, target := range targets {
+		raw := values.Get(name)
+		if raw == "" {
+			continue
+		}
+		amount := model.Money{}.In(currency)
+		if err := amount.UnmarshalJSON([]byte(raw)); err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+		*target = &amount
+	}
+	return nil
+}
+
 func (ctrl *expenseController) Update(w http.ResponseWriter, r *http.Request) {
-	var e model.Expense
-	if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	var req request.ExpenseUpdateRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeBadRequest(w, r, err)
+		return
 	}
-	err := ctrl.service.Update(&e)
+	e := model.Expense{
+		ID:           req.ID,
+		Amount:       req.Amount,
+		Description:  req.Description,
+		CategoryID:   req.CategoryID,
+		CategoryName: req.CategoryName,
+		Date:         req.Date,
+		IsRecurring:  req.IsRecurring,
+		PaidBy:       req.PaidBy,
+		Tags:         req.Tags,
+	}
+	err := ctrl.service.Update(&e, callerEmail(r))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, r, err)
+		return
 	}
 	w.WriteHeader(http.StatusOK)
 }
 func (ctrl *expenseController) Delete(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		ID   int `json:"id"`
-		Plan int `json:"plan_id"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	var req request.DeleteExpenseRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeBadRequest(w, r, err)
 		return
 	}
 
-	err := ctrl.service.DeleteExpense(req.ID, req.Plan)
+	err := ctrl.service.DeleteExpense(req.ID, req.PlanID, 0, callerEmail(r))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, r, err)
+		return
 	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
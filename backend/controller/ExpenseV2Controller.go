@@ -0,0 +1,190 @@
+package controller
+
+import (
+	"backend/model/request"
+	"backend/service"
+	"fmt"
+	"net/http"
+)
+
+// ExpenseV2Controller serves the expenses of a plan as resources of the v2
+// API, at /plans/{id}/expenses/{expenseId}. Single expenses carry an ETag;
+// PATCH and DELETE honour If-Match.
+type ExpenseV2Controller interface {
+	List(w http.ResponseWriter, r *http.Request)
+	Create(w http.ResponseWriter, r *http.Request)
+	Get(w http.ResponseWriter, r *http.Request)
+	Patch(w http.ResponseWriter, r *http.Request)
+	Delete(w http.ResponseWriter, r *http.Request)
+}
+
+type expenseV2Controller struct {
+	service service.ExpenseService
+}
+
+func NewExpenseV2Controller(svc *service.ServiceBase) ExpenseV2Controller {
+	return &expenseV2Controller{
+		service: service.GetByType[service.ExpenseService](svc),
+	}
+}
+
+// expenseIDs reads the plan and expense of the path.
+func expenseIDs(r *http.Request) (int, int, error) {
+	planID, err := pathID(r, "id")
+	if err != nil {
+		return 0, 0, err
+	}
+	id, err := pathID(r, "expenseId")
+	return planID, id, err
+}
+
+// List lists the expenses of the plan, narrowed and paginated by the query
+// parameters of GetByPlan of the v1 API.
+func (ctrl *expenseV2Controller) List(w http.ResponseWriter, r *http.Request) {
+	planID, err := pathID(r, "id")
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	q, err := expenseFilter(r, planID)
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	list, err := listQuery(r)
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	page, err := ctrl.service.GetByPlan(q, list, callerEmail(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeList(w, r, page, list)
+}
+
+// Create adds an expense to the plan. The budget_id of the body may be left out.
+func (ctrl *expenseV2Controller) Create(w http.ResponseWriter, r *http.Request) {
+	planID, err := pathID(r, "id")
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	req := request.ExpenseRequest{BudgetID: planID}
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	if req.BudgetID != planID {
+		writeBadRequest(w, r, fmt.Errorf("budget_id must be %d, the plan of the path", planID))
+		return
+	}
+	expense := expenseOf(req)
+	email := callerEmail(r)
+	if err := ctrl.service.NewExpense(&expense, email); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	created, err := ctrl.service.Get(expense.ID, planID, email)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeCreated(w, r, expense.ID, created.Version, created)
+}
+
+func (ctrl *expenseV2Controller) Get(w http.ResponseWriter, r *http.Request) {
+	planID, id, err := expenseIDs(r)
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	expense, err := ctrl.service.Get(id, planID, callerEmail(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeResource(w, r, http.StatusOK, expense.Version, expense)
+}
+
+// Patch edits the fields of an expense given in the body, keeping the others.
+func (ctrl *expenseV2Controller) Patch(w http.ResponseWriter, r *http.Request) {
+	planID, id, err := expenseIDs(r)
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	var patch request.ExpensePatchRequest
+	if err := decodeJSON(w, r, &patch); err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	email := callerEmail(r)
+	expense, err := ctrl.service.Get(id, planID, email)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	if _, ok := ifMatchVersion(w, r, expense.Version); !ok {
+		return
+	}
+	// the write is conditioned on the version read above even without
+	// If-Match, so that a concurrent change is not overwritten
+	// nil tags are left alone by Update
+	expense.Tags = nil
+	if patch.Amount != nil {
+		expense.Amount = patch.Amount.In(expense.Amount.CurrencyCode())
+	}
+	if patch.Description != nil {
+		expense.Description = *patch.Description
+	}
+	if patch.CategoryID != nil {
+		expense.CategoryID = *patch.CategoryID
+	}
+	if patch.Date != nil {
+		expense.Date = *patch.Date
+	}
+	if patch.IsRecurring != nil {
+		expense.IsRecurring = *patch.IsRecurring
+	}
+	if patch.PaidBy != nil {
+		expense.PaidBy = patch.PaidBy
+	}
+	if patch.Tags != nil {
+		expense.Tags = append([]string{}, *patch.Tags...)
+	}
+	if err := ctrl.service.Update(expense, email); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	updated, err := ctrl.service.Get(id, planID, email)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeResource(w, r, http.StatusOK, updated.Version, updated)
+}
+
+func (ctrl *expenseV2Controller) Delete(w http.ResponseWriter, r *http.Request) {
+	planID, id, err := expenseIDs(r)
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	email := callerEmail(r)
+	expense, err := ctrl.service.Get(id, planID, email)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	version, ok := ifMatchVersion(w, r, expense.Version)
+	if !ok {
+		return
+	}
+	if err := ctrl.service.DeleteExpense(id, planID, version, email); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"backend/service"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type ExportController interface {
+	Export(w http.ResponseWriter, r *http.Request)
+}
+
+type exportController struct {
+	service service.ExportService
+}
+
+func NewExportController(svc *service.ServiceBase) ExportController {
+	return &exportController{
+		service: service.GetByType[service.ExportService](svc),
+	}
+}
+
+// Export streams the caller's data. It reads the format (csv, json or ofx,
+// default json), plan, from and to (YYYY-MM-DD) query parameters.
+func (ctrl *exportController) Export(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+	q := service.ExportQuery{Format: values.Get("format")}
+	if q.Format == "" {
+		q.Format = "json"
+	}
+	if raw := values.Get("plan"); raw != "" {
+		planID, err := strconv.Atoi(raw)
+		if err != nil {
+			writeBadRequest(w, r, fmt.Errorf("invalid plan: %w", err))
+			return
+		}
+		q.PlanID = planID
+	}
+	for name, target := range map[string]**time.Time{"from": &q.From, "to": &q.To} {
+		raw := values.Get(name)
+		if raw == "" {
+			continue
+		}
+		day, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			writeBadRequest(w, r, fmt.Errorf("invalid %s: must be YYYY-MM-DD", name))
+			return
+		}
+		*target = &day
+	}
+
+	stream, err := ctrl.service.Export(q, callerEmail(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	filename := fmt.Sprintf("gastozero-%s.%s", time.Now().Format("20060102"), stream.Extension)
+	w.Header().Set("Content-Type", stream.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	// the status is already sent, so a failure midway can only be logged
+	if err := stream.WriteTo(w); err != nil {
+		log.Error().Err(err).Str("format", q.Format).Msg("Failed to stream export")
+	}
+}
@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"backend/model/request"
+	"backend/service"
+	"backend/statement"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// maxStatementSize bounds the statement files accepted by the import endpoints.
+const maxStatementSize = 10 << 20
+
+// maxBackupSize bounds the JSON exports accepted by Restore.
+const maxBackupSize = 100 << 20
+
+type ImportController interface {
+	Preview(w http.ResponseWriter, r *http.Request)
+	Commit(w http.ResponseWriter, r *http.Request)
+	Restore(w http.ResponseWriter, r *http.Request)
+}
+
+type importController struct {
+	service service.ImportService
+}
+
+func NewImportController(svc *service.ServiceBase) ImportController {
+	return &importController{
+		service: service.GetByType[service.ImportService](svc),
+	}
+}
+
+// Preview reads a multipart form with the statement in "file" and, for CSV,
+// an optional JSON column mapping in "mapping". The plan comes from ?plan= and
+// the format from ?format=, falling back to the file extension.
+func (ctrl *importController) Preview(w http.ResponseWriter, r *http.Request) {
+	planID, err := strconv.Atoi(r.URL.Query().Get("plan"))
+	if err != nil {
+		writeBadRequest(w, r, fmt.Errorf("invalid plan: %w", err))
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxStatementSize)
+	if err := r.ParseMultipartForm(maxStatementSize); err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeBadRequest(w, r, fmt.Errorf("missing file: %w", err))
+		return
+	}
+	defer file.Close()
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(header.Filename), ".")
+	}
+
+	mapping := statement.DefaultCSVMapping()
+	if raw := r.FormValue("mapping"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &mapping); err != nil {
+			writeBadRequest(w, r, fmt.Errorf("invalid mapping: %v", err))
+			return
+		}
+	}
+
+	preview, err := ctrl.service.Preview(planID, format, file, mapping, callerEmail(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(preview); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
+
+func (ctrl *importController) Commit(w http.ResponseWriter, r *http.Request) {
+	var req request.ImportCommitRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	result, err := ctrl.service.Commit(&req, callerEmail(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
+
+// Restore reads a JSON export of GET /export?format=json from the body.
+func (ctrl *importController) Restore(w http.ResponseWriter, r *http.Request) {
+	result, err := ctrl.service.Restore(http.MaxBytesReader(w, r.Body, maxBackupSize), callerEmail(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
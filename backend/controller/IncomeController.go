@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"backend/model"
+	"backend/model/request"
+	"backend/service"
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+type IncomeController interface {
+	Create(w http.ResponseWriter, r *http.Request)
+	GetByPlan(w http.ResponseWriter, r *http.Request)
+	Update(w http.ResponseWriter, r *http.Request)
+	Delete(w http.ResponseWriter, r *http.Request)
+	Balance(w http.ResponseWriter, r *http.Request)
+}
+
+type incomeController struct {
+	service service.IncomeService
+}
+
+func NewIncomeController(svc *service.ServiceBase) IncomeController {
+	return &incomeController{
+		service: service.GetByType[service.IncomeService](svc),
+	}
+}
+
+func (ctrl *incomeController) Create(w http.ResponseWriter, r *http.Request) {
+	var req request.IncomeRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	income := model.Income{
+		BudgetID:    req.BudgetID,
+		Description: req.Description,
+		Source:      req.Source,
+		Amount:      req.Amount.In(req.Currency),
+		Date:        req.Date,
+	}
+	if err := ctrl.service.Create(&income, callerEmail(r)); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(income); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
+
+// GetByPlan reads the same plan, period_id, from and to query parameters as the reports.
+func (ctrl *incomeController) GetByPlan(w http.ResponseWriter, r *http.Request) {
+	q, err := reportQuery(r)
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	v, err := ctrl.service.GetByPlan(q, callerEmail(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	if v == nil {
+		v = []model.Income{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
+
+func (ctrl *incomeController) Update(w http.ResponseWriter, r *http.Request) {
+	var req request.IncomeUpdateRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	income := model.Income{
+		ID:          req.ID,
+		Description: req.Description,
+		Source:      req.Source,
+		Amount:      req.Amount.In(req.Currency),
+		Date:        req.Date,
+	}
+	if err := ctrl.service.Update(&income, callerEmail(r)); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(income); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
+
+func (ctrl *incomeController) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	if err := ctrl.service.Delete(id, callerEmail(r)); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// Balance reads the same plan, period_id, from and to query parameters as the reports.
+func (ctrl *incomeController) Balance(w http.ResponseWriter, r *http.Request) {
+	q, err := reportQuery(r)
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	balance, err := ctrl.service.Balance(q, callerEmail(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(balance); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"backend/model"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// listQuery reads the limit, sort and cursor query parameters of a list.
+// Sort names a field, prefixed with - for descending order; cursor is the
+// next_cursor of the previous page.
+func listQuery(r *http.Request) (model.ListQuery, error) {
+	values := r.URL.Query()
+	list := model.ListQuery{Sort: values.Get("sort")}
+	if raw := values.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			return list, fmt.Errorf("invalid limit: must be a positive integer")
+		}
+		list.Limit = limit
+	}
+	if raw := values.Get("cursor"); raw != "" {
+		cursor, err := model.ParseCursor(raw)
+		if err != nil {
+			return list, err
+		}
+		list.Cursor = cursor
+	}
+	return list, nil
+}
+
+// writeList writes a page of a list. Lists requested without limit nor
+// cursor are written as a plain array, as they were before pagination, with
+// the cursor of the next page in the X-Next-Cursor header.
+func writeList[T any](w http.ResponseWriter, r *http.Request, page *model.Page[T], list model.ListQuery) {
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	var v interface{} = page
+	if !list.Paginated() {
+		v = page.Items
+		if page.NextCursor != "" {
+			w.Header().Set("X-Next-Cursor", page.NextCursor)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
@@ -0,0 +1,180 @@
+package controller
+
+import (
+	"backend/model"
+	"backend/model/request"
+	"backend/service"
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+type PlanMemberController interface {
+	GetByPlan(w http.ResponseWriter, r *http.Request)
+	UpdateRole(w http.ResponseWriter, r *http.Request)
+	Remove(w http.ResponseWriter, r *http.Request)
+	Invite(w http.ResponseWriter, r *http.Request)
+	GetInvitations(w http.ResponseWriter, r *http.Request)
+	Revoke(w http.ResponseWriter, r *http.Request)
+	Pending(w http.ResponseWriter, r *http.Request)
+	Accept(w http.ResponseWriter, r *http.Request)
+	Decline(w http.ResponseWriter, r *http.Request)
+}
+
+type planMemberController struct {
+	service service.PlanMemberService
+}
+
+func NewPlanMemberController(svc *service.ServiceBase) PlanMemberController {
+	return &planMemberController{
+		service: service.GetByType[service.PlanMemberService](svc),
+	}
+}
+
+func (ctrl *planMemberController) GetByPlan(w http.ResponseWriter, r *http.Request) {
+	planID, err := strconv.Atoi(r.URL.Query().Get("plan"))
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	v, err := ctrl.service.GetByPlan(planID, callerEmail(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	if v == nil {
+		v = []model.PlanMember{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
+
+func (ctrl *planMemberController) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	var req request.MemberRoleRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	if err := ctrl.service.UpdateRole(&req, callerEmail(r)); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// Remove takes the member given by the user query parameter out of the plan given by plan.
+func (ctrl *planMemberController) Remove(w http.ResponseWriter, r *http.Request) {
+	planID, err := strconv.Atoi(r.URL.Query().Get("plan"))
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	userID, err := strconv.Atoi(r.URL.Query().Get("user"))
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	if err := ctrl.service.Remove(planID, userID, callerEmail(r)); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (ctrl *planMemberController) Invite(w http.ResponseWriter, r *http.Request) {
+	var req request.InvitationRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	invitation, err := ctrl.service.Invite(&req, callerEmail(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(invitation); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
+
+func (ctrl *planMemberController) GetInvitations(w http.ResponseWriter, r *http.Request) {
+	planID, err := strconv.Atoi(r.URL.Query().Get("plan"))
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	v, err := ctrl.service.GetInvitations(planID, callerEmail(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	if v == nil {
+		v = []model.PlanInvitation{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
+
+func (ctrl *planMemberController) Revoke(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	if err := ctrl.service.Revoke(id, callerEmail(r)); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// Pending lists the invitations addressed to the caller.
+func (ctrl *planMemberController) Pending(w http.ResponseWriter, r *http.Request) {
+	v, err := ctrl.service.Pending(callerEmail(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	if v == nil {
+		v = []model.PlanInvitation{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
+
+func (ctrl *planMemberController) Accept(w http.ResponseWriter, r *http.Request) {
+	ctrl.respond(w, r, true)
+}
+
+func (ctrl *planMemberController) Decline(w http.ResponseWriter, r *http.Request) {
+	ctrl.respond(w, r, false)
+}
+
+func (ctrl *planMemberController) respond(w http.ResponseWriter, r *http.Request, accept bool) {
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	invitation, err := ctrl.service.Respond(id, accept, callerEmail(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(invitation); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
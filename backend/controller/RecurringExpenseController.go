@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"backend/model"
+	"backend/model/request"
+	"backend/service"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type RecurringExpenseController interface {
+	Create(w http.ResponseWriter, r *http.Request)
+	GetByPlan(w http.ResponseWriter, r *http.Request)
+	Update(w http.ResponseWriter, r *http.Request)
+	Delete(w http.ResponseWriter, r *http.Request)
+	Pause(w http.ResponseWriter, r *http.Request)
+	Resume(w http.ResponseWriter, r *http.Request)
+	Skip(w http.ResponseWriter, r *http.Request)
+}
+
+type recurringExpenseController struct {
+	service service.RecurringExpenseService
+}
+
+func NewRecurringExpenseController(svc *service.ServiceBase) RecurringExpenseController {
+	return &recurringExpenseController{
+		service: service.GetByType[service.RecurringExpenseService](svc),
+	}
+}
+
+// fromRequest builds the model from the request body, applying the RRULE when one is given.
+func fromRequest(req *request.RecurringExpenseRequest) (*model.RecurringExpense, error) {
+	recurring := &model.RecurringExpense{
+		BudgetID:     req.BudgetID,
+		CategoryID:   req.CategoryID,
+		CategoryName: req.CategoryName,
+		Description:  req.Description,
+		Amount:       req.Amount.In(req.Currency),
+		Frequency:    model.Frequency(req.Frequency),
+		Interval:     req.Interval,
+		ByDay:        req.ByDay,
+		ByMonthDay:   req.ByMonthDay,
+		Count:        req.Count,
+		StartDate:    req.StartDate,
+		EndDate:      req.EndDate,
+	}
+	if req.RRule != "" {
+		if err := recurring.ApplyRRule(req.RRule); err != nil {
+			return nil, err
+		}
+	}
+	return recurring, nil
+}
+
+func (ctrl *recurringExpenseController) Create(w http.ResponseWriter, r *http.Request) {
+	var req request.RecurringExpenseRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	recurring, err := fromRequest(&req)
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	if err := ctrl.service.Create(recurring, callerEmail(r)); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(recurring); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
+
+func (ctrl *recurringExpenseController) GetByPlan(w http.ResponseWriter, r *http.Request) {
+	planID, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	v, err := ctrl.service.GetByPlan(planID, callerEmail(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	if v == nil {
+		v = []model.RecurringExpense{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
+
+// Update edits the future occurrences of the recurring expense given by the id query parameter.
+func (ctrl *recurringExpenseController) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	var req request.RecurringExpenseRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	recurring, err := fromRequest(&req)
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	recurring.ID = id
+	if err := ctrl.service.Update(recurring, callerEmail(r)); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(recurring); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
+
+func (ctrl *recurringExpenseController) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	if err := ctrl.service.Delete(id, callerEmail(r)); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (ctrl *recurringExpenseController) Pause(w http.ResponseWriter, r *http.Request) {
+	ctrl.setPaused(w, r, true)
+}
+
+func (ctrl *recurringExpenseController) Resume(w http.ResponseWriter, r *http.Request) {
+	ctrl.setPaused(w, r, false)
+}
+
+func (ctrl *recurringExpenseController) setPaused(w http.ResponseWriter, r *http.Request, paused bool) {
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	if err := ctrl.service.SetPaused(id, paused, callerEmail(r)); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// Skip skips the occurrence on the optional date query parameter (YYYY-MM-DD), or the next one.
+func (ctrl *recurringExpenseController) Skip(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	var day time.Time
+	if raw := r.URL.Query().Get("date"); raw != "" {
+		day, err = time.Parse("2006-01-02", raw)
+		if err != nil {
+			writeBadRequest(w, r, err)
+			return
+		}
+	}
+	if err := ctrl.service.Skip(id, day, callerEmail(r)); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
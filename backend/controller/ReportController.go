@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"backend/model"
+	"backend/service"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type ReportController interface {
+	ByCategory(w http.ResponseWriter, r *http.Request)
+	ByPeriod(w http.ResponseWriter, r *http.Request)
+	ByRecurrence(w http.ResponseWriter, r *http.Request)
+	ByTag(w http.ResponseWriter, r *http.Request)
+}
+
+type reportController struct {
+	service service.ReportService
+}
+
+func NewReportController(svc *service.ServiceBase) ReportController {
+	return &reportController{
+		service: service.GetByType[service.ReportService](svc),
+	}
+}
+
+// reportQuery reads the plan, period_id, from, to (YYYY-MM-DD) and recurring query parameters.
+func reportQuery(r *http.Request) (service.ReportQuery, error) {
+	var q service.ReportQuery
+	values := r.URL.Query()
+
+	planID, err := strconv.Atoi(values.Get("plan"))
+	if err != nil {
+		return q, fmt.Errorf("invalid plan: %w", err)
+	}
+	q.PlanID = planID
+
+	if raw := values.Get("period_id"); raw != "" {
+		if q.PeriodID, err = strconv.Atoi(raw); err != nil {
+			return q, fmt.Errorf("invalid period_id: %w", err)
+		}
+	}
+
+	if err := dayParams(values, map[string]**time.Time{"from": &q.From, "to": &q.To}); err != nil {
+		return q, err
+	}
+
+	if raw := values.Get("recurring"); raw != "" {
+		recurring, err := strconv.ParseBool(raw)
+		if err != nil {
+			return q, fmt.Errorf("invalid recurring: %w", err)
+		}
+		q.Recurring = &recurring
+	}
+	return q, nil
+}
+
+// dayParams reads the optional YYYY-MM-DD query parameters named by targets.
+func dayParams(values url.Values, targets map[string]**time.Time) error {
+	for name, target := range targets {
+		raw := values.Get(name)
+		if raw == "" {
+			continue
+		}
+		day, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+		*target = &day
+	}
+	return nil
+}
+
+func (ctrl *reportController) ByCategory(w http.ResponseWriter, r *http.Request) {
+	q, err := reportQuery(r)
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	report, err := ctrl.service.ByCategory(q, callerEmail(r))
+	writeReport(w, r, report, err)
+}
+
+// ByPeriod groups by the period query parameter: day, week or month (default).
+func (ctrl *reportController) ByPeriod(w http.ResponseWriter, r *http.Request) {
+	q, err := reportQuery(r)
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	period := r.URL.Query().Get("period")
+	if period == "" {
+		period = "month"
+	}
+	report, err := ctrl.service.ByPeriod(q, period, callerEmail(r))
+	writeReport(w, r, report, err)
+}
+
+func (ctrl *reportController) ByRecurrence(w http.ResponseWriter, r *http.Request) {
+	q, err := reportQuery(r)
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	report, err := ctrl.service.ByRecurrence(q, callerEmail(r))
+	writeReport(w, r, report, err)
+}
+
+func (ctrl *reportController) ByTag(w http.ResponseWriter, r *http.Request) {
+	q, err := reportQuery(r)
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	report, err := ctrl.service.ByTag(q, callerEmail(r))
+	writeReport(w, r, report, err)
+}
+
+func writeReport(w http.ResponseWriter, r *http.Request, report *model.Report, err error) {
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
@@ -0,0 +1,28 @@
+package controller
+
+import (
+	"backend/model/request"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// maxBodySize bounds the JSON bodies accepted by the API. Files are uploaded
+// as multipart forms, with limits of their own.
+const maxBodySize = 1 << 20
+
+// decodeJSON reads the JSON body of r into v and checks v against the
+// validate tags of its fields. Bodies larger than maxBodySize, fields v does
+// not have and anything after the JSON value are rejected.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return errors.New("the request body must hold a single JSON value")
+	}
+	return request.Validate(v)
+}
@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"backend/middleware"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// pathID reads the integer path parameter name of a route of the v2 API.
+func pathID(r *http.Request, name string) (int, error) {
+	id, err := strconv.Atoi(mux.Vars(r)[name])
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return id, nil
+}
+
+// versionETag returns the entity tag of a resource at version, the row
+// version that the database bumps on every write.
+func versionETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// writeResource writes a single resource along with the ETag of its version.
+// A GET whose If-None-Match holds the tag is answered with 304 Not Modified
+// instead.
+func writeResource(w http.ResponseWriter, r *http.Request, status int, version int, v interface{}) {
+	tag := versionETag(version)
+	w.Header().Set("ETag", tag)
+	if r.Method == http.MethodGet && matchesETag(r.Header.Get("If-None-Match"), tag, true) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
+
+// ifMatchVersion compares the If-Match header of a request that changes a
+// resource with the ETag of its current version, and writes 412
+// Precondition Failed when the resource changed since the client read it.
+// It returns the version the write must be conditioned on: current when the
+// header names it, and 0, meaning any, without If-Match or with *.
+func ifMatchVersion(w http.ResponseWriter, r *http.Request, current int) (int, bool) {
+	header := r.Header.Get("If-Match")
+	if header == "" || strings.TrimSpace(header) == "*" {
+		return 0, true
+	}
+	tag := versionETag(current)
+	if matchesETag(header, tag, false) {
+		return current, true
+	}
+	middleware.WriteError(w, r, http.StatusPreconditionFailed, "precondition_failed",
+		"the resource changed since it was read", map[string]interface{}{"etag": tag})
+	return 0, false
+}
+
+// matchesETag reports whether the comma separated entity tags of an
+// If-Match or If-None-Match header hold tag or are *. Weak tags only match
+// with weak comparison, which If-None-Match uses.
+func matchesETag(header string, tag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// writeCreated writes a resource created in the collection at the path of
+// the request, with its Location.
+func writeCreated(w http.ResponseWriter, r *http.Request, id int, version int, v interface{}) {
+	w.Header().Set("Location", strings.TrimSuffix(r.URL.Path, "/")+"/"+strconv.Itoa(id))
+	writeResource(w, r, http.StatusCreated, version, v)
+}
@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"backend/model"
+	"backend/model/request"
+	"backend/service"
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+type RuleController interface {
+	Create(w http.ResponseWriter, r *http.Request)
+	Update(w http.ResponseWriter, r *http.Request)
+	Delete(w http.ResponseWriter, r *http.Request)
+	GetByUser(w http.ResponseWriter, r *http.Request)
+	Apply(w http.ResponseWriter, r *http.Request)
+	DryRun(w http.ResponseWriter, r *http.Request)
+}
+
+type ruleController struct {
+	service service.RuleService
+}
+
+func NewRuleController(svc *service.ServiceBase) RuleController {
+	return &ruleController{
+		service: service.GetByType[service.RuleService](svc),
+	}
+}
+
+// Create saves a new rule. Rules are enabled unless the payload says otherwise.
+func (ctrl *ruleController) Create(w http.ResponseWriter, r *http.Request) {
+	rule := model.ExpenseRule{Enabled: true}
+	if err := decodeJSON(w, r, &rule); err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	if err := ctrl.service.Create(&rule, callerEmail(r)); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(rule); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
+
+func (ctrl *ruleController) Update(w http.ResponseWriter, r *http.Request) {
+	rule := model.ExpenseRule{Enabled: true}
+	if err := decodeJSON(w, r, &rule); err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	if err := ctrl.service.Update(&rule, callerEmail(r)); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(rule); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
+
+func (ctrl *ruleController) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	if err := ctrl.service.Delete(id, callerEmail(r)); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (ctrl *ruleController) GetByUser(w http.ResponseWriter, r *http.Request) {
+	v, err := ctrl.service.GetByUser(callerEmail(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	if v == nil {
+		v = []model.ExpenseRule{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
+
+// Apply runs the caller's rules over the existing expenses of a plan.
+func (ctrl *ruleController) Apply(w http.ResponseWriter, r *http.Request) {
+	ctrl.run(w, r, false)
+}
+
+// DryRun reports which expenses of a plan the caller's rules would change.
+func (ctrl *ruleController) DryRun(w http.ResponseWriter, r *http.Request) {
+	ctrl.run(w, r, true)
+}
+
+func (ctrl *ruleController) run(w http.ResponseWriter, r *http.Request, dryRun bool) {
+	var req request.RuleRunRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	v, err := ctrl.service.Run(req.PlanID, dryRun, callerEmail(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
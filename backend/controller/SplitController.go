@@ -0,0 +1,155 @@
+package controller
+
+import (
+	"backend/model"
+	"backend/model/request"
+	"backend/service"
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+type SplitController interface {
+	Split(w http.ResponseWriter, r *http.Request)
+	GetByExpense(w http.ResponseWriter, r *http.Request)
+	Unsplit(w http.ResponseWriter, r *http.Request)
+	Settlement(w http.ResponseWriter, r *http.Request)
+	RecordPayment(w http.ResponseWriter, r *http.Request)
+	GetPayments(w http.ResponseWriter, r *http.Request)
+	DeletePayment(w http.ResponseWriter, r *http.Request)
+}
+
+type splitController struct {
+	service service.SplitService
+}
+
+func NewSplitController(svc *service.ServiceBase) SplitController {
+	return &splitController{
+		service: service.GetByType[service.SplitService](svc),
+	}
+}
+
+func (ctrl *splitController) Split(w http.ResponseWriter, r *http.Request) {
+	var req request.SplitRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	v, err := ctrl.service.Split(&req, callerEmail(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
+
+// GetByExpense lists the shares of the expense given by the id query parameter.
+func (ctrl *splitController) GetByExpense(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	v, err := ctrl.service.GetByExpense(id, callerEmail(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	if v == nil {
+		v = []model.ExpenseSplit{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
+
+func (ctrl *splitController) Unsplit(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	if err := ctrl.service.Unsplit(id, callerEmail(r)); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// Settlement returns the balances of the members of the plan given by the
+// plan query parameter and the transfers that settle them.
+func (ctrl *splitController) Settlement(w http.ResponseWriter, r *http.Request) {
+	planID, err := strconv.Atoi(r.URL.Query().Get("plan"))
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	v, err := ctrl.service.Settlement(planID, callerEmail(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
+
+func (ctrl *splitController) RecordPayment(w http.ResponseWriter, r *http.Request) {
+	var req request.SettlementPaymentRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	payment, err := ctrl.service.RecordPayment(&req, callerEmail(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(payment); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
+
+func (ctrl *splitController) GetPayments(w http.ResponseWriter, r *http.Request) {
+	planID, err := strconv.Atoi(r.URL.Query().Get("plan"))
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	v, err := ctrl.service.GetPayments(planID, callerEmail(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	if v == nil {
+		v = []model.SettlementPayment{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
+
+func (ctrl *splitController) DeletePayment(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	if err := ctrl.service.DeletePayment(id, callerEmail(r)); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
@@ -7,7 +7,6 @@ import (
 	"backend/service"
 	"encoding/json"
 	"net/http"
-	"strconv"
 )
 
 type UserController interface {
@@ -17,22 +16,27 @@ type UserController interface {
 	UpdatePassword(w http.ResponseWriter, r *http.Request)
 	Update(w http.ResponseWriter, r *http.Request)
 	Delete(w http.ResponseWriter, r *http.Request)
+	Refresh(w http.ResponseWriter, r *http.Request)
+	Logout(w http.ResponseWriter, r *http.Request)
+	LogoutAll(w http.ResponseWriter, r *http.Request)
 }
 type userController struct {
-	service service.UserService
+	service  service.UserService
+	sessions service.SessionService
 }
 
 func NewUserController(svc *service.ServiceBase) UserController {
 	return &userController{
-		service: service.GetByType[service.UserService](svc),
+		service:  service.GetByType[service.UserService](svc),
+		sessions: service.GetByType[service.SessionService](svc),
 	}
 }
 
 func (ctrl *userController) CreateUser(w http.ResponseWriter, r *http.Request) {
 	var u request.UserRequest
 
-	if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
-		http.Error(w, "Invalid Body", http.StatusBadRequest)
+	if err := decodeJSON(w, r, &u); err != nil {
+		writeBadRequest(w, r, err)
 		return
 	}
 	user := model.User{
@@ -42,7 +46,7 @@ func (ctrl *userController) CreateUser(w http.ResponseWriter, r *http.Request) {
 	}
 	err := ctrl.service.CreateUser(&user)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, r, err)
 		return
 	}
 	resp := response.UserResponse{
@@ -55,7 +59,7 @@ func (ctrl *userController) CreateUser(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusCreated)
 
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		http.Error(w, "response failure: "+err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, r, err)
 	}
 }
 
@@ -63,7 +67,7 @@ func (ctrl *userController) FindByEmail(w http.ResponseWriter, r *http.Request)
 	email := r.URL.Query().Get("email")
 	user, err := ctrl.service.FindByEmail(email)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, r, err)
 		return
 	}
 	resp := response.UserResponse{
@@ -74,38 +78,74 @@ func (ctrl *userController) FindByEmail(w http.ResponseWriter, r *http.Request)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		http.Error(w, "Failed to encode JSON: "+err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, r, err)
 	}
 }
 
 func (ctrl *userController) Login(w http.ResponseWriter, r *http.Request) {
 	var u request.LoginRequest
 
-	if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
-		http.Error(w, "Invalid Body", http.StatusBadRequest)
+	if err := decodeJSON(w, r, &u); err != nil {
+		writeBadRequest(w, r, err)
 		return
 	}
 
-	token, err := ctrl.service.Login(&u)
+	u.UserAgent = r.UserAgent()
+	tokens, err := ctrl.service.Login(&u)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		writeServiceError(w, r, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 
-	err = json.NewEncoder(w).Encode(map[string]string{"token": token})
+	err = json.NewEncoder(w).Encode(tokens)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeBadRequest(w, r, err)
 	}
 }
-func (ctrl *userController) UpdatePassword(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		NewPassword string `json:"new_password"`
+
+// Refresh exchanges a refresh token for a new access and refresh token pair.
+func (ctrl *userController) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req request.RefreshRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeBadRequest(w, r, err)
+		return
+	}
+	tokens, err := ctrl.sessions.Refresh(req.RefreshToken, r.UserAgent())
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(tokens); err != nil {
+		writeServiceError(w, r, err)
+	}
+}
+
+// Logout revokes the session of the access token used for the request.
+func (ctrl *userController) Logout(w http.ResponseWriter, r *http.Request) {
+	if err := ctrl.sessions.Logout(callerSession(r), callerEmail(r)); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// LogoutAll revokes every session of the caller, on every device.
+func (ctrl *userController) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	if err := ctrl.sessions.LogoutAll(callerEmail(r)); err != nil {
+		writeServiceError(w, r, err)
+		return
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid Body", http.StatusBadRequest)
+	w.WriteHeader(http.StatusNoContent)
+}
+func (ctrl *userController) UpdatePassword(w http.ResponseWriter, r *http.Request) {
+	var req request.PasswordRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeBadRequest(w, r, err)
 		return
 	}
 
@@ -113,13 +153,13 @@ func (ctrl *userController) UpdatePassword(w http.ResponseWriter, r *http.Reques
 
 	current, err := ctrl.service.FindByEmail(email)
 	if err != nil {
-		http.Error(w, "user not found", http.StatusNotFound)
+		writeServiceError(w, r, err)
 		return
 	}
 
 	err = ctrl.service.UpdatePassword(current, req.NewPassword)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeServiceError(w, r, err)
 		return
 	}
 
@@ -130,37 +170,32 @@ func (ctrl *userController) UpdatePassword(w http.ResponseWriter, r *http.Reques
 	}
 }
 
+// Update changes the name and email of the caller.
 func (ctrl *userController) Update(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		ID    int    `json:"ID"`
-		Email string `json:"email"`
-		Name  string `json:"name"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid Body", http.StatusBadRequest)
+	var req request.UserUpdateRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeBadRequest(w, r, err)
+		return
 	}
 	user := model.User{
-		ID:    req.ID,
 		Name:  req.Name,
 		Email: req.Email,
 	}
-	err := ctrl.service.Update(&user)
+	err := ctrl.service.Update(&user, callerEmail(r))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeServiceError(w, r, err)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
 
 }
 
+// Delete deletes the account of the caller.
 func (ctrl *userController) Delete(w http.ResponseWriter, r *http.Request) {
-	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	err := ctrl.service.Delete(callerEmail(r))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-	}
-	err = ctrl.service.Delete(id)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeServiceError(w, r, err)
+		return
 	}
 	w.WriteHeader(http.StatusOK)
 }
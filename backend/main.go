@@ -6,11 +6,15 @@ import (
 	"backend/repository"
 	"backend/routes"
 	"backend/service"
+	"context"
+	"fmt"
 	"github.com/joho/godotenv"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"net/http"
 	"os"
+	"strconv"
+	"time"
 )
 
 func main() {
@@ -28,27 +32,102 @@ func main() {
 		return
 	}
 
+	migrator, err := config.NewMigrator(db)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Erro ao carregar migrações")
+	}
+
+	// `backend migrate up|down|status` manages the schema and exits
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(migrator, os.Args[2:]); err != nil {
+			log.Fatal().Err(err).Msg("Erro ao executar migrações")
+		}
+		return
+	}
+
+	applied, err := migrator.Up(context.Background())
+	if err != nil {
+		log.Fatal().Err(err).Msg("Erro ao aplicar migrações")
+	}
+	log.Info().Int("applied", applied).Msg("Migrações aplicadas")
+
+	blobs, err := config.NewBlobStore()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Erro ao configurar o armazenamento de anexos")
+	}
+
 	repoFactory := repository.NewBase()
 	sFactory := service.NewBase()
 
 	// inject repositories
 	repoFactory.Init(
 		repository.NewUserRepository(db),
+		repository.NewSessionRepository(db),
 		repository.NewBudgetPlanRepository(db),
+		repository.NewBudgetPeriodRepository(db),
+		repository.NewPlanMemberRepository(db),
 		repository.NewCategoryRepository(db),
 		repository.NewExpensesRepository(db),
+		repository.NewSplitRepository(db),
+		repository.NewTagRepository(db),
+		repository.NewRuleRepository(db),
+		repository.NewAttachmentRepository(db, blobs),
+		repository.NewIncomeRepository(db),
+		repository.NewExchangeRateRepository(db),
+		repository.NewRecurringExpenseRepository(db),
+		repository.NewReportRepository(db),
+		repository.NewCategoryBudgetRepository(db),
+		repository.NewRestoreRepository(db),
 	)
 	log.Info().Msg("Repositórios injetados com sucesso")
 
 	// inject services
 	sFactory.Init(
 		service.NewUserService(repoFactory),
+		service.NewSessionService(repoFactory),
 		service.NewCategoryService(repoFactory),
 		service.NewExpensesService(repoFactory),
+		service.NewSplitService(repoFactory),
+		service.NewTagService(repoFactory),
+		service.NewRuleService(repoFactory),
+		service.NewAttachmentService(repoFactory),
+		service.NewIncomeService(repoFactory),
 		service.NewBudgetPlanService(repoFactory),
+		service.NewBudgetPeriodService(repoFactory),
+		service.NewPlanMemberService(repoFactory),
+		service.NewExchangeRateService(repoFactory),
+		service.NewRecurringExpenseService(repoFactory),
+		service.NewReportService(repoFactory),
+		service.NewCategoryBudgetService(repoFactory),
+		service.NewImportService(repoFactory),
+		service.NewExportService(repoFactory),
 	)
 	log.Info().Msg("Serviços injetados com sucesso")
 
+	// materialize recurring expenses in the background
+	go service.RunRecurringScheduler(
+		context.Background(),
+		service.GetByType[service.RecurringExpenseService](sFactory),
+		time.Hour,
+	)
+	log.Info().Msg("Agendador de despesas recorrentes iniciado")
+
+	// start new budget periods in the background
+	go service.RunPeriodScheduler(
+		context.Background(),
+		service.GetByType[service.BudgetPeriodService](sFactory),
+		time.Hour,
+	)
+	log.Info().Msg("Agendador de períodos de orçamento iniciado")
+
+	// purge the files of deleted attachments in the background
+	go service.RunAttachmentSweeper(
+		context.Background(),
+		service.GetByType[service.AttachmentService](sFactory),
+		time.Hour,
+	)
+	log.Info().Msg("Limpeza de anexos iniciada")
+
 	// setup routes
 	router := routes.SetupRoutes(sFactory)
 	log.Info().Msg("Rotas configuradas")
@@ -57,13 +136,60 @@ func main() {
 	if err := middleware.InitJWT(); err != nil {
 		log.Fatal().Err(err).Msg("Erro ao inicializar JWT")
 	}
+	middleware.UseSessionValidator(service.GetByType[service.SessionService](sFactory))
 
 	// apply cors
 	cors := middleware.CORSMiddleware{BaseURL: "http://localhost:3000"}
-	handlerComCors := cors.Handler(router)
+	handlerComCors := cors.Handler(middleware.RequestID(router))
 
 	log.Info().Msg("Servidor rodando em :8080")
 	if err := http.ListenAndServe(":8080", handlerComCors); err != nil {
 		log.Fatal().Err(err).Msg("Erro ao subir o servidor")
 	}
 }
+
+// runMigrate handles the `migrate` subcommand.
+func runMigrate(migrator *config.Migrator, args []string) error {
+	ctx := context.Background()
+	if len(args) == 0 {
+		return fmt.Errorf("usage: migrate up|down [steps]|status")
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		log.Info().Int("applied", applied).Msg("Migrações aplicadas")
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+			steps = n
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		log.Info().Int("reverted", reverted).Msg("Migrações revertidas")
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.AppliedAt != nil {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-40s %s\n", s.Version, s.Name, state)
+		}
+	default:
+		return fmt.Errorf("unknown migrate command %q", args[0])
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrorResponse is the body of every error response of the API. Code is
+// stable and meant for programs; Message is meant for people and follows
+// the Accept-Language of the request when it can be translated.
+type ErrorResponse struct {
+	Code      string                 `json:"code"`
+	Message   string                 `json:"message"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	RequestID string                 `json:"request_id,omitempty"`
+}
+
+// DefaultLanguage is used for clients that accept none of the languages of messages.
+const DefaultLanguage = "en"
+
+// messages translates error codes, by language. A {name} in a message is
+// replaced by the detail of that name.
+var messages = map[string]map[string]string{
+	"en": {
+		"unauthorized":        "Authentication is required.",
+		"invalid_credentials": "Invalid email or password.",
+		"forbidden":           "You are not allowed to do this.",
+		"role_required":       "This requires the {role} role in the plan.",
+		"not_found":           "The resource was not found.",
+		"conflict":            "The request conflicts with the current state of the resource.",
+		"already_exists":      "The resource already exists.",
+		"category_in_use":     "The category is used by expenses.",
+		"email_in_use":        "The email is already in use.",
+		"user_in_splits":      "The user paid or shares split expenses. Unsplit them or change their payer first.",
+		"payer_required":      "The expense has no payer. Set who paid it before splitting it.",
+		"already_member":      "{email} is already a member of the plan.",
+		"already_invited":     "{email} was already invited.",
+		"same_password":       "Please enter a different password.",
+		"validation_failed":   "The request is invalid.",
+		"malformed_request":   "The request could not be read.",
+		"unknown_field":       "The field {field} is not accepted.",
+		"invalid_email":       "The email address is invalid.",
+		"weak_password":       "The password must have at least {min_length} characters, with letters and digits.",
+		"payload_too_large":   "The request body is too large.",
+		"invalid_cursor":      "The cursor is invalid or was issued for another listing.",
+		"precondition_failed": "The resource was changed by someone else. Reload it and try again.",
+		"internal_error":      "An unexpected error occurred. Please try again later.",
+	},
+	"pt": {
+		"unauthorized":        "É necessário autenticar-se.",
+		"invalid_credentials": "Email ou senha inválidos.",
+		"forbidden":           "Você não tem permissão para fazer isso.",
+		"role_required":       "Isso requer o papel {role} no plano.",
+		"not_found":           "O recurso não foi encontrado.",
+		"conflict":            "A requisição conflita com o estado atual do recurso.",
+		"already_exists":      "O recurso já existe.",
+		"category_in_use":     "A categoria é usada por despesas.",
+		"email_in_use":        "O email já está em uso.",
+		"user_in_splits":      "O usuário pagou ou divide despesas compartilhadas. Desfaça a divisão ou troque o pagador antes.",
+		"payer_required":      "A despesa não tem pagador. Informe quem a pagou antes de dividi-la.",
+		"already_member":      "{email} já é membro do plano.",
+		"already_invited":     "{email} já foi convidado.",
+		"same_password":       "Informe uma senha diferente.",
+		"validation_failed":   "A requisição é inválida.",
+		"malformed_request":   "Não foi possível ler a requisição.",
+		"unknown_field":       "O campo {field} não é aceito.",
+		"invalid_email":       "O endereço de email é inválido.",
+		"weak_password":       "A senha deve ter pelo menos {min_length} caracteres, com letras e números.",
+		"payload_too_large":   "O corpo da requisição é grande demais.",
+		"invalid_cursor":      "O cursor é inválido ou foi emitido para outra listagem.",
+		"precondition_failed": "O recurso foi alterado por outra pessoa. Recarregue-o e tente novamente.",
+		"internal_error":      "Ocorreu um erro inesperado. Tente novamente mais tarde.",
+	},
+}
+
+// WriteError writes an error response with status. text is the English
+// message; clients that prefer another language get the translation of
+// code instead, with text kept in the "reason" detail when it says more.
+func WriteError(w http.ResponseWriter, r *http.Request, status int, code string, text string, details map[string]interface{}) {
+	message := text
+	if lang := language(r); lang != DefaultLanguage || text == "" {
+		if translated, ok := messages[lang][code]; ok {
+			message = expand(translated, details)
+			if text != "" && text != expand(messages[DefaultLanguage][code], details) {
+				details = withDetail(details, "reason", text)
+			}
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(ErrorResponse{Code: code, Message: message, Details: details, RequestID: GetRequestID(r)})
+}
+
+// language picks the first language of the Accept-Language header that
+// messages are translated to.
+func language(r *http.Request) string {
+	for _, tag := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		tag = strings.TrimSpace(strings.SplitN(tag, ";", 2)[0])
+		lang := strings.ToLower(strings.SplitN(tag, "-", 2)[0])
+		if _, ok := messages[lang]; ok {
+			return lang
+		}
+	}
+	return DefaultLanguage
+}
+
+func expand(message string, details map[string]interface{}) string {
+	for name, value := range details {
+		message = strings.ReplaceAll(message, "{"+name+"}", fmt.Sprint(value))
+	}
+	return message
+}
+
+func withDetail(details map[string]interface{}, name string, value interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(details)+1)
+	for k, v := range details {
+		merged[k] = v
+	}
+	merged[name] = value
+	return merged
+}
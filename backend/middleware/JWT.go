@@ -15,12 +15,32 @@ import (
 
 var jwtKey []byte
 
+// AccessTokenTTL is how long an access token is accepted. Clients obtain a new
+// one with their refresh token once it expires.
+const AccessTokenTTL = 15 * time.Minute
+
 // Claims define the structure of JWT claims.
 type Claims struct {
-	Username string `json:"username"`
+	Username  string `json:"username"`
+	SessionID int    `json:"sid"`
+	Version   int    `json:"ver"`
 	jwt.RegisteredClaims
 }
 
+// SessionValidator reports whether the session an access token was issued for
+// is still valid, so that logged out or revoked tokens stop working before
+// they expire.
+type SessionValidator interface {
+	IsActive(sessionID int, email string, version int) (bool, error)
+}
+
+var sessions SessionValidator
+
+// UseSessionValidator makes JWTAuth check every token against v.
+func UseSessionValidator(v SessionValidator) {
+	sessions = v
+}
+
 // InitJWT loads the JWT secret from the environment and prepares it for signing tokens.
 func InitJWT() error {
 	jwtKey = []byte(os.Getenv("JWT_SECRET"))
@@ -33,12 +53,14 @@ func InitJWT() error {
 	return nil
 }
 
-// GenerateJWT generates a signed JWT token with a 2-hour expiration.
-func GenerateJWT(username string) (string, error) {
-	expirationTime := time.Now().Add(2 * time.Hour)
+// GenerateJWT generates a signed access token for a session that expires after AccessTokenTTL.
+func GenerateJWT(username string, sessionID int, version int) (string, error) {
+	expirationTime := time.Now().Add(AccessTokenTTL)
 
 	claims := &Claims{
-		Username: username,
+		Username:  username,
+		SessionID: sessionID,
+		Version:   version,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 		},
@@ -55,20 +77,21 @@ func GenerateJWT(username string) (string, error) {
 	return signedToken, nil
 }
 
-// JWTAuth is a middleware that validates JWT tokens and adds the username to the request context.
+// JWTAuth is a middleware that validates JWT tokens and adds the username and
+// session ID to the request context.
 func JWTAuth(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
 			log.Warn().Msg("Missing Authorization header")
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			WriteError(w, r, http.StatusUnauthorized, "unauthorized", "", nil)
 			return
 		}
 
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
 			log.Warn().Msg("Malformed Authorization header")
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			WriteError(w, r, http.StatusUnauthorized, "unauthorized", "", nil)
 			return
 		}
 
@@ -79,13 +102,23 @@ func JWTAuth(next http.HandlerFunc) http.HandlerFunc {
 		})
 		if err != nil || !token.Valid {
 			log.Warn().Err(err).Msg("Invalid or expired JWT")
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			WriteError(w, r, http.StatusUnauthorized, "unauthorized", "", nil)
 			return
 		}
 
-		// Token is valid, attach email (username) to context
+		if sessions != nil {
+			active, err := sessions.IsActive(claims.SessionID, claims.Username, claims.Version)
+			if err != nil || !active {
+				log.Warn().Err(err).Int("session_id", claims.SessionID).Msg("Revoked JWT")
+				WriteError(w, r, http.StatusUnauthorized, "unauthorized", "", nil)
+				return
+			}
+		}
+
+		// Token is valid, attach email (username) and session to context
 		log.Debug().Msg("JWT validated successfully")
 		ctx := context.WithValue(r.Context(), "email", claims.Username)
+		ctx = context.WithValue(ctx, "session", claims.SessionID)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	}
 }
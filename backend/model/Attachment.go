@@ -0,0 +1,36 @@
+package model
+
+import (
+	"github.com/uptrace/bun"
+	"time"
+)
+
+// MaxAttachmentSize is the largest receipt accepted, in bytes.
+const MaxAttachmentSize = 10 << 20
+
+// AttachmentContentTypes lists the receipt formats accepted, as detected from
+// their content rather than trusted from the upload.
+var AttachmentContentTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+}
+
+// Attachment is a receipt uploaded to an Expense. The file itself lives in a
+// blob store under StorageKey.
+type Attachment struct {
+	bun.BaseModel `bun:"table:expense_attachments"`
+
+	ID          int       `bun:",pk,autoincrement" json:"id"`
+	ExpenseID   int       `json:"expense_id"`
+	PlanID      int       `bun:",scanonly" json:"-"`
+	StorageKey  string    `json:"-"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	SizeBytes   int64     `json:"size_bytes"`
+	SHA256      string    `bun:"sha256" json:"sha256"`
+	UploadedBy  *int      `json:"uploaded_by"`
+	CreatedAt   time.Time `bun:",nullzero,default:current_timestamp" json:"created_at"`
+}
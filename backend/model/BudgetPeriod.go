@@ -0,0 +1,120 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"github.com/uptrace/bun"
+	"time"
+)
+
+// PeriodType is how often a BudgetPlan starts over.
+type PeriodType string
+
+const (
+	PeriodOneOff  PeriodType = "one_off"
+	PeriodWeekly  PeriodType = "weekly"
+	PeriodMonthly PeriodType = "monthly"
+	PeriodCustom  PeriodType = "custom"
+)
+
+// BudgetPeriod is one instance of a BudgetPlan. Amount is the plan total at
+// the time the period started plus the Rollover carried from the previous
+// period, which is negative when that period was overspent. EndDate is
+// exclusive and nil for a one-off plan without an end.
+type BudgetPeriod struct {
+	bun.BaseModel `bun:"table:budget_periods"`
+
+	ID         int         `bun:",pk,autoincrement" json:"id"`
+	PlanID     int         `json:"planID"`
+	StartDate  time.Time   `json:"startDate"`
+	EndDate    *time.Time  `json:"endDate"`
+	Amount     Money       `bun:"embed:amount_" json:"amount"`
+	Rollover   Money       `bun:"embed:rollover_" json:"rollover"`
+	SpentMinor int64       `bun:",scanonly" json:"-"`
+	Spent      Money       `bun:"-" json:"spent"`
+	Remaining  Money       `bun:"-" json:"remaining"`
+	Plan       *BudgetPlan `bun:"rel:belongs-to,join:plan_id=id" json:"-"`
+}
+
+// Contains reports whether the day falls within the period.
+func (p *BudgetPeriod) Contains(day time.Time) bool {
+	day = truncateDay(day)
+	return !day.Before(p.StartDate) && (p.EndDate == nil || day.Before(*p.EndDate))
+}
+
+// ValidatePeriod normalizes the period settings of the plan and reports
+// whether they are consistent. An empty Period means one-off.
+func (b *BudgetPlan) ValidatePeriod() error {
+	if b.Period == "" {
+		b.Period = PeriodOneOff
+	}
+	if b.EndDate != nil {
+		end := truncateDay(*b.EndDate)
+		b.EndDate = &end
+	}
+	switch b.Period {
+	case PeriodOneOff, PeriodWeekly, PeriodMonthly:
+		b.PeriodDays = 0
+	case PeriodCustom:
+		if b.PeriodDays < 1 {
+			return errors.New("periodDays must be positive for a custom period")
+		}
+	default:
+		return fmt.Errorf("unknown period %q", b.Period)
+	}
+	if b.EndDate != nil && !b.EndDate.After(truncateDay(b.CreatedDate)) {
+		return errors.New("endDate must be after startDate")
+	}
+	return nil
+}
+
+// IsPeriodic reports whether the plan starts a new period when one ends.
+func (b *BudgetPlan) IsPeriodic() bool {
+	return b.Period != "" && b.Period != PeriodOneOff
+}
+
+// FirstPeriod returns the period starting on the plan's start date.
+func (b *BudgetPlan) FirstPeriod() *BudgetPeriod {
+	start := truncateDay(b.CreatedDate)
+	return &BudgetPeriod{
+		PlanID:    b.ID,
+		StartDate: start,
+		EndDate:   b.PeriodEnd(start),
+		Amount:    b.TotalAmount,
+		Rollover:  NewMoney(0, b.BaseCurrency()),
+	}
+}
+
+// PeriodEnd returns the exclusive end of the period starting on start, or
+// nil when it never ends. Monthly periods keep the day of month of the plan
+// start, clamped to the length of shorter months. No period runs past the
+// plan's EndDate.
+func (b *BudgetPlan) PeriodEnd(start time.Time) *time.Time {
+	start = truncateDay(start)
+	var end time.Time
+	switch b.Period {
+	case PeriodWeekly:
+		end = start.AddDate(0, 0, 7)
+	case PeriodMonthly:
+		end = addMonthClamped(start, truncateDay(b.CreatedDate).Day())
+	case PeriodCustom:
+		end = start.AddDate(0, 0, b.PeriodDays)
+	default:
+		return b.EndDate
+	}
+	if b.EndDate != nil && b.EndDate.Before(end) {
+		end = *b.EndDate
+	}
+	return &end
+}
+
+// addMonthClamped returns the given day of the month after t, or the last
+// day of that month when it is shorter.
+func addMonthClamped(t time.Time, day int) time.Time {
+	first := time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1).Day()
+	if day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"encoding/json"
 	"github.com/uptrace/bun"
 	"time"
 )
@@ -9,9 +10,52 @@ type BudgetPlan struct {
 	bun.BaseModel `bun:"table:budget_plan"`
 	ID            int        `bun:"id,pk,autoincrement" json:"id"`
 	Name          string     `json:"name"`
-	TotalAmount   float64    `json:"totalAmount"`
+	TotalAmount   Money      `bun:"embed:total_amount_" json:"totalAmount"`
 	Description   string     `json:"description"`
 	CreatedDate   time.Time  `bun:"created_date" json:"startDate"`
+	Period        PeriodType `json:"period"`
+	PeriodDays    int        `bun:",nullzero" json:"periodDays,omitempty"`
+	EndDate       *time.Time `json:"endDate"`
+	Rollover      bool       `json:"rollover"`
 	UserID        int        `json:"userID"`
+	Role          PlanRole   `bun:",scanonly" json:"role,omitempty"`
 	Expenses      []*Expense `bun:"m2m:budget_plan_expenses" json:"expenses"`
+	// Summary is set instead of Expenses by plan listings that summarize them.
+	Summary *PlanSummary `bun:"-" json:"summary,omitempty"`
+	// Version is bumped by the database on every update of the plan.
+	Version int `bun:",scanonly" json:"-"`
+}
+
+// PlanSummary counts and totals the expenses of a plan, in its base currency.
+type PlanSummary struct {
+	ExpenseCount int   `json:"expenseCount"`
+	ExpenseTotal Money `json:"expenseTotal"`
+}
+
+// BaseCurrency is the currency every expense of the plan is converted to.
+func (b *BudgetPlan) BaseCurrency() string {
+	return b.TotalAmount.CurrencyCode()
+}
+
+// MarshalJSON exposes the currency of TotalAmount as "baseCurrency".
+func (b BudgetPlan) MarshalJSON() ([]byte, error) {
+	type alias BudgetPlan
+	return json.Marshal(struct {
+		alias
+		BaseCurrency string `json:"baseCurrency"`
+	}{alias(b), b.BaseCurrency()})
+}
+
+// UnmarshalJSON binds TotalAmount to the optional "baseCurrency" field of the payload.
+func (b *BudgetPlan) UnmarshalJSON(data []byte) error {
+	type alias BudgetPlan
+	aux := struct {
+		*alias
+		BaseCurrency string `json:"baseCurrency"`
+	}{alias: (*alias)(b)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	b.TotalAmount = b.TotalAmount.In(aux.BaseCurrency)
+	return nil
 }
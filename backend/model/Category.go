@@ -1,10 +1,51 @@
 package model
 
-import "github.com/uptrace/bun"
+import (
+	"github.com/uptrace/bun"
+	"strings"
+)
 
+// CategoryPathSeparator joins the names of a category and its parent, as in "Food > Restaurants".
+const CategoryPathSeparator = " > "
+
+// DefaultCategories is the set seeded for new users: top-level names with their subcategories.
+var DefaultCategories = []struct {
+	Name     string
+	Children []string
+}{
+	{"Food", []string{"Groceries", "Restaurants"}},
+	{"Housing", []string{"Rent", "Utilities", "Maintenance"}},
+	{"Transport", []string{"Fuel", "Public Transport", "Ride Sharing"}},
+	{"Health", []string{"Pharmacy", "Insurance"}},
+	{"Leisure", []string{"Travel", "Subscriptions"}},
+	{"Education", nil},
+	{"Shopping", nil},
+	{"Other", nil},
+}
+
+// Category belongs to a single user. Categories form a two-level hierarchy:
+// a top-level category may have subcategories, which cannot nest further.
 type Category struct {
 	bun.BaseModel `bun:"table:category"`
 
-	ID   int    `bun:",pk,autoincrement" json:"id"`
-	Name string `json:"name"`
+	ID       int    `bun:",pk,autoincrement" json:"id"`
+	Name     string `json:"name"`
+	UserID   int    `json:"user_id"`
+	ParentID *int   `json:"parent_id"`
+	// Path is the full name of the category, filled when listing.
+	Path     string      `bun:",scanonly" json:"path,omitempty"`
+	Children []*Category `bun:"-" json:"children,omitempty"`
+	// Version is bumped by the database on every update of the category.
+	Version int `bun:",scanonly" json:"-"`
+}
+
+// SplitCategoryPath splits "Food > Restaurants" into its trimmed names.
+func SplitCategoryPath(path string) []string {
+	var names []string
+	for _, name := range strings.Split(path, strings.TrimSpace(CategoryPathSeparator)) {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
 }
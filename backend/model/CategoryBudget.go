@@ -0,0 +1,60 @@
+package model
+
+import (
+	"github.com/uptrace/bun"
+	"math"
+	"time"
+)
+
+// CategoryBudget allocates part of a BudgetPlan to a category, in the plan's
+// base currency. Spending in the subcategories counts against the allocation.
+type CategoryBudget struct {
+	bun.BaseModel `bun:"table:plan_category_budgets"`
+
+	ID           int    `bun:",pk,autoincrement" json:"id"`
+	PlanID       int    `json:"plan_id"`
+	CategoryID   int    `json:"category_id"`
+	CategoryName string `bun:",scanonly" json:"category_name"`
+	Amount       Money  `bun:"embed:amount_" json:"amount"`
+}
+
+// CategoryBudgetStatus compares an allocation with what was spent.
+type CategoryBudgetStatus struct {
+	CategoryID   int     `json:"category_id"`
+	CategoryName string  `json:"category_name"`
+	Allocated    Money   `json:"allocated"`
+	Spent        Money   `json:"spent"`
+	Remaining    Money   `json:"remaining"`
+	PercentUsed  float64 `json:"percent_used"`
+	OverBudget   bool    `json:"over_budget"`
+}
+
+// BudgetStatus is the allocation status of every category budget of a plan.
+// Unallocated is the spending in categories without an allocation.
+type BudgetStatus struct {
+	PlanID      int                    `json:"plan_id"`
+	Currency    string                 `json:"currency"`
+	From        *time.Time             `json:"from,omitempty"`
+	To          *time.Time             `json:"to,omitempty"`
+	Allocated   Money                  `json:"allocated"`
+	Spent       Money                  `json:"spent"`
+	Unallocated Money                  `json:"unallocated"`
+	OverBudget  bool                   `json:"over_budget"`
+	Categories  []CategoryBudgetStatus `json:"categories"`
+}
+
+// NewCategoryBudgetStatus computes the remaining amount and usage of an allocation.
+func NewCategoryBudgetStatus(categoryID int, name string, allocated Money, spent Money) CategoryBudgetStatus {
+	status := CategoryBudgetStatus{
+		CategoryID:   categoryID,
+		CategoryName: name,
+		Allocated:    allocated,
+		Spent:        spent,
+		Remaining:    Money{Minor: allocated.Minor - spent.Minor, Currency: allocated.Currency},
+		OverBudget:   spent.Minor > allocated.Minor,
+	}
+	if allocated.Minor > 0 {
+		status.PercentUsed = math.Round(float64(spent.Minor)/float64(allocated.Minor)*10000) / 100
+	}
+	return status
+}
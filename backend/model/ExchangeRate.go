@@ -0,0 +1,18 @@
+package model
+
+import (
+	"github.com/uptrace/bun"
+	"time"
+)
+
+// ExchangeRate is the price of one unit of Base expressed in Quote on Date.
+type ExchangeRate struct {
+	bun.BaseModel `bun:"table:exchange_rates"`
+
+	ID     int       `bun:",pk,autoincrement" json:"id"`
+	Base   string    `json:"base"`
+	Quote  string    `json:"quote"`
+	Rate   float64   `json:"rate"`
+	Date   time.Time `json:"date"`
+	Source string    `json:"source"`
+}
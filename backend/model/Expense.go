@@ -1,6 +1,7 @@
 package model
 
 import (
+	"encoding/json"
 	"github.com/uptrace/bun"
 	"time"
 )
@@ -9,11 +10,50 @@ type Expense struct {
 	bun.BaseModel `bun:"table:expenses"`
 
 	ID           int       `bun:",pk,autoincrement" json:"id"`
-	Amount       float64   `json:"amount"`
+	Amount       Money     `bun:"embed:amount_" json:"amount"`
 	Description  string    `json:"description"`
 	CategoryID   int       `json:"category_id"`
 	CategoryName string    `json:"category_name"`
 	Date         time.Time `json:"date"`
 	IsRecurring  bool      `json:"is_recurring"`
 	BudgetID     int       `json:"budget_id"`
+	ExchangeRate float64   `bun:"exchange_rate" json:"exchange_rate"`
+	BaseAmount   Money     `bun:"embed:base_amount_" json:"base_amount"`
+	// PaidBy is the plan member who paid the expense.
+	PaidBy *int `json:"paid_by"`
+	// Tags are the names of the tags of the expense, sorted.
+	Tags []string `bun:",scanonly,array" json:"tags"`
+	// SplitMethod is set when the expense is shared between plan members.
+	SplitMethod *SplitMethod `json:"split_method,omitempty"`
+	// Rank is how well the expense matches a search, only set on search results.
+	Rank float32 `bun:",scanonly" json:"rank,omitempty"`
+	// RecurringExpenseID and OccurrenceDate are set on expenses materialized
+	// from a RecurringExpense and make the scheduler idempotent.
+	RecurringExpenseID *int       `json:"recurring_expense_id,omitempty"`
+	OccurrenceDate     *time.Time `json:"occurrence_date,omitempty"`
+	// Version is bumped by the database on every update of the expense or its tags.
+	Version int `bun:",scanonly" json:"-"`
+}
+
+// MarshalJSON adds the currency of Amount to the payload.
+func (e Expense) MarshalJSON() ([]byte, error) {
+	type alias Expense
+	return json.Marshal(struct {
+		alias
+		Currency string `json:"currency"`
+	}{alias(e), e.Amount.CurrencyCode()})
+}
+
+// UnmarshalJSON binds Amount to the optional "currency" field of the payload.
+func (e *Expense) UnmarshalJSON(data []byte) error {
+	type alias Expense
+	aux := struct {
+		*alias
+		Currency string `json:"currency"`
+	}{alias: (*alias)(e)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	e.Amount = e.Amount.In(aux.Currency)
+	return nil
 }
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// ExportDocument is the JSON backup of an account written by the export
+// endpoint and read back when restoring it. Expenses refer to the IDs of
+// the plans and categories of the same document.
+type ExportDocument struct {
+	Version    int          `json:"version"`
+	ExportedAt time.Time    `json:"exported_at"`
+	Categories []Category   `json:"categories"`
+	Plans      []BudgetPlan `json:"plans"`
+	Expenses   []Expense    `json:"expenses"`
+}
+
+// RestoreResult counts what restoring an ExportDocument created.
+type RestoreResult struct {
+	Categories int `json:"categories"`
+	Plans      int `json:"plans"`
+	Expenses   int `json:"expenses"`
+}
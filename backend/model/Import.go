@@ -0,0 +1,62 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ImportRow is a bank statement transaction ready to become an Expense.
+// DuplicateOf points at an existing expense with the same date, amount and
+// description; rows with a SkipReason are never imported.
+type ImportRow struct {
+	Line         int       `json:"line"`
+	Date         time.Time `json:"date"`
+	Amount       Money     `json:"amount"`
+	Description  string    `json:"description"`
+	CategoryID   int       `json:"category_id"`
+	CategoryName string    `json:"category_name"`
+	Tags         []string  `json:"tags,omitempty"`
+	DuplicateOf  *int      `json:"duplicate_of,omitempty"`
+	SkipReason   string    `json:"skip_reason,omitempty"`
+}
+
+// MarshalJSON adds the currency of Amount to the payload.
+func (r ImportRow) MarshalJSON() ([]byte, error) {
+	type alias ImportRow
+	return json.Marshal(struct {
+		alias
+		Currency string `json:"currency"`
+	}{alias(r), r.Amount.CurrencyCode()})
+}
+
+// UnmarshalJSON binds Amount to the optional "currency" field of the payload.
+func (r *ImportRow) UnmarshalJSON(data []byte) error {
+	type alias ImportRow
+	aux := struct {
+		*alias
+		Currency string `json:"currency"`
+	}{alias: (*alias)(r)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	r.Amount = r.Amount.In(aux.Currency)
+	return nil
+}
+
+// ImportPreview is what a statement would add to a plan if it were committed.
+type ImportPreview struct {
+	PlanID     int         `json:"plan_id"`
+	Format     string      `json:"format"`
+	Rows       []ImportRow `json:"rows"`
+	Duplicates int         `json:"duplicates"`
+	Skipped    int         `json:"skipped"`
+}
+
+// ImportResult summarizes a committed import.
+type ImportResult struct {
+	PlanID     int       `json:"plan_id"`
+	Imported   int       `json:"imported"`
+	Duplicates int       `json:"duplicates"`
+	Skipped    int       `json:"skipped"`
+	Expenses   []Expense `json:"expenses"`
+}
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"encoding/json"
+	"github.com/uptrace/bun"
+	"time"
+)
+
+// Income is money coming into a BudgetPlan, the counterpart of an Expense.
+type Income struct {
+	bun.BaseModel `bun:"table:incomes"`
+
+	ID           int       `bun:",pk,autoincrement" json:"id"`
+	BudgetID     int       `json:"budget_id"`
+	Description  string    `json:"description"`
+	Source       string    `json:"source"`
+	Amount       Money     `bun:"embed:amount_" json:"amount"`
+	Date         time.Time `json:"date"`
+	ExchangeRate float64   `bun:"exchange_rate" json:"exchange_rate"`
+	BaseAmount   Money     `bun:"embed:base_amount_" json:"base_amount"`
+}
+
+// MarshalJSON adds the currency of Amount to the payload.
+func (i Income) MarshalJSON() ([]byte, error) {
+	type alias Income
+	return json.Marshal(struct {
+		alias
+		Currency string `json:"currency"`
+	}{alias(i), i.Amount.CurrencyCode()})
+}
+
+// UnmarshalJSON binds Amount to the optional "currency" field of the payload.
+func (i *Income) UnmarshalJSON(data []byte) error {
+	type alias Income
+	aux := struct {
+		*alias
+		Currency string `json:"currency"`
+	}{alias: (*alias)(i)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	i.Amount = i.Amount.In(aux.Currency)
+	return nil
+}
+
+// Balance is the money in and out of a plan over a range of dates, in the
+// plan's base currency. Opening is the net of everything before From and
+// Closing adds the Net of the range to it.
+type Balance struct {
+	PlanID   int        `json:"plan_id"`
+	PeriodID int        `json:"period_id,omitempty"`
+	Currency string     `json:"currency"`
+	From     *time.Time `json:"from,omitempty"`
+	To       *time.Time `json:"to,omitempty"`
+	Opening  Money      `json:"opening"`
+	Income   Money      `json:"income"`
+	Expenses Money      `json:"expenses"`
+	Net      Money      `json:"net"`
+	Closing  Money      `json:"closing"`
+}
@@ -0,0 +1,100 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// DefaultPageLimit and MaxPageLimit bound the size of a page of a list.
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 200
+)
+
+// ErrInvalidSort is returned when a list cannot be sorted by the given field.
+var ErrInvalidSort = errors.New("invalid sort")
+
+// Fields lists can be sorted by.
+var (
+	PlanSortFields     = []string{"id", "name", "created", "total"}
+	ExpenseSortFields  = []string{"date", "amount", "description", "id"}
+	CategorySortFields = []string{"path", "name", "id"}
+)
+
+// ListQuery selects a page of a list. Sort names a field, in descending order
+// when prefixed with "-". Every list is served a page at a time, of
+// DefaultPageLimit items unless Limit says otherwise.
+type ListQuery struct {
+	Limit  int
+	Sort   string
+	Cursor *Cursor
+}
+
+// Paginated reports whether the client asked for a page with Limit or
+// Cursor, rather than for the list as the API served it before pagination.
+func (q ListQuery) Paginated() bool {
+	return q.Limit > 0 || q.Cursor != nil
+}
+
+// Field returns the field q sorts by and whether the order is descending.
+func (q ListQuery) Field() (string, bool) {
+	return strings.TrimPrefix(q.Sort, "-"), strings.HasPrefix(q.Sort, "-")
+}
+
+// Resolve checks q against the sort fields of a list, falls back to sort
+// def and bounds the page size, which defaults to DefaultPageLimit.
+func (q ListQuery) Resolve(fields []string, def string) (ListQuery, error) {
+	if q.Sort == "" {
+		q.Sort = def
+	}
+	if field, _ := q.Field(); !slices.Contains(fields, field) {
+		return q, fmt.Errorf("%w %q: must be one of %s, optionally prefixed with -", ErrInvalidSort, q.Sort, strings.Join(fields, ", "))
+	}
+	if q.Cursor != nil && q.Cursor.Sort != q.Sort {
+		return q, fmt.Errorf("%w: it was issued for sort %q", ErrInvalidCursor, q.Cursor.Sort)
+	}
+	switch {
+	case q.Limit <= 0:
+		q.Limit = DefaultPageLimit
+	case q.Limit > MaxPageLimit:
+		q.Limit = MaxPageLimit
+	}
+	return q, nil
+}
+
+// Cursor is the position of the last item of a page: the value of its sort
+// field and its ID, which breaks ties.
+type Cursor struct {
+	Sort string `json:"s"`
+	Key  string `json:"k"`
+	ID   int    `json:"i"`
+}
+
+// Encode returns the opaque form of the cursor handed to clients.
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParseCursor reads a cursor returned by Encode.
+func ParseCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID <= 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// Page is a page of a list. NextCursor is empty on the last page.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
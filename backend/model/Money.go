@@ -0,0 +1,249 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is used for amounts that arrive without an explicit currency.
+const DefaultCurrency = "BRL"
+
+// ErrCurrencyMismatch is returned when combining amounts in different currencies.
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// minorUnitExponents lists ISO-4217 currencies whose minor unit is not 1/100.
+var minorUnitExponents = map[string]int{
+	"BHD": 3, "CLP": 0, "IQD": 3, "ISK": 0, "JOD": 3, "JPY": 0, "KRW": 0,
+	"KWD": 3, "LYD": 3, "OMR": 3, "PYG": 0, "TND": 3, "UGX": 0, "VND": 0,
+}
+
+// Money is an amount stored as an integer number of minor units (e.g. cents)
+// of an ISO-4217 currency. It is persisted through bun's embed tag as two
+// columns, <prefix>minor and <prefix>currency, and serialized to JSON as a
+// plain decimal number so the API keeps its numeric shape.
+type Money struct {
+	Minor    int64  `bun:"minor,notnull"`
+	Currency string `bun:"currency,notnull"`
+	// raw is the decimal an amount without a currency was decoded from, so
+	// that In rounds it once, at the precision of the currency it is bound to.
+	raw string
+}
+
+// NewMoney builds a Money from a number of minor units.
+func NewMoney(minor int64, currency string) Money {
+	return Money{Minor: minor, Currency: normalizeCurrency(currency)}
+}
+
+// MoneyFromFloat converts a decimal amount into minor units, rounding half away from zero.
+func MoneyFromFloat(amount float64, currency string) Money {
+	currency = normalizeCurrency(currency)
+	minor, _ := parseMinor(strconv.FormatFloat(amount, 'f', -1, 64), MinorUnitExponent(currency))
+	return Money{Minor: minor, Currency: currency}
+}
+
+// MinorUnitExponent returns how many decimal digits the minor unit of currency has.
+func MinorUnitExponent(currency string) int {
+	if exp, ok := minorUnitExponents[normalizeCurrency(currency)]; ok {
+		return exp
+	}
+	return 2
+}
+
+// CurrencyCode returns the ISO-4217 code of the amount, DefaultCurrency when unset.
+func (m Money) CurrencyCode() string {
+	return m.currency()
+}
+
+// Float64 returns the amount in major units. It is meant for display only.
+func (m Money) Float64() float64 {
+	f, _ := strconv.ParseFloat(m.decimal(), 64)
+	return f
+}
+
+// IsZero reports whether the amount is zero.
+func (m Money) IsZero() bool {
+	return m.Minor == 0
+}
+
+// Add returns m + o. Both amounts must share the same currency.
+func (m Money) Add(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+	return Money{Minor: m.Minor + o.Minor, Currency: m.currency()}, nil
+}
+
+// Sub returns m - o. Both amounts must share the same currency.
+func (m Money) Sub(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+	return Money{Minor: m.Minor - o.Minor, Currency: m.currency()}, nil
+}
+
+// In binds an amount without a currency to currency. An amount decoded from
+// JSON is rounded from its decimal to that currency's precision; any other
+// has its minor units rescaled. Amounts that already carry a currency, and
+// empty targets, are returned unchanged.
+func (m Money) In(currency string) Money {
+	if m.Currency != "" || strings.TrimSpace(currency) == "" {
+		return m
+	}
+	currency = normalizeCurrency(currency)
+	if m.raw != "" {
+		if minor, err := parseMinor(m.raw, MinorUnitExponent(currency)); err == nil {
+			return Money{Minor: minor, Currency: currency}
+		}
+	}
+	from, to := MinorUnitExponent(""), MinorUnitExponent(currency)
+	r := new(big.Rat).SetInt64(m.Minor)
+	r.Mul(r, pow10Rat(to-from))
+	minor, _ := strconv.ParseInt(r.FloatString(0), 10, 64)
+	return Money{Minor: minor, Currency: currency}
+}
+
+// Convert multiplies the amount by rate and expresses the result in currency,
+// rounding half away from zero to its minor unit.
+func (m Money) Convert(rate float64, currency string) Money {
+	currency = normalizeCurrency(currency)
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(rate, 'f', -1, 64))
+	r.Mul(r, new(big.Rat).SetInt64(m.Minor))
+	r.Mul(r, pow10Rat(MinorUnitExponent(currency)-MinorUnitExponent(m.currency())))
+	minor, _ := strconv.ParseInt(r.FloatString(0), 10, 64)
+	return Money{Minor: minor, Currency: currency}
+}
+
+// Neg returns -m.
+func (m Money) Neg() Money {
+	return Money{Minor: -m.Minor, Currency: m.Currency}
+}
+
+// Decimal formats the amount in major units with every digit of the minor
+// unit, e.g. "12.30", without the currency code.
+func (m Money) Decimal() string {
+	return formatMinor(m.Minor, MinorUnitExponent(m.currency()), false)
+}
+
+// String formats the amount as "<decimal> <currency>", e.g. "12.30 BRL".
+func (m Money) String() string {
+	exp := MinorUnitExponent(m.currency())
+	return fmt.Sprintf("%s %s", formatMinor(m.Minor, exp, false), m.currency())
+}
+
+// MarshalJSON writes the amount as a JSON number in major units.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.decimal()), nil
+}
+
+// UnmarshalJSON reads a JSON number in major units. The currency is left
+// untouched; when it is empty the amount is parsed with the precision of
+// DefaultCurrency and keeps its decimal, so that binding it to its real
+// currency later with In loses no digit.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err != nil {
+		return fmt.Errorf("money: expected a number, got %s", data)
+	}
+	minor, err := parseMinor(number.String(), MinorUnitExponent(m.currency()))
+	if err != nil {
+		return err
+	}
+	m.Minor = minor
+	m.raw = ""
+	if m.Currency == "" {
+		m.raw = number.String()
+	}
+	return nil
+}
+
+func (m Money) decimal() string {
+	return formatMinor(m.Minor, MinorUnitExponent(m.currency()), true)
+}
+
+func (m Money) currency() string {
+	if m.Currency == "" {
+		return DefaultCurrency
+	}
+	return m.Currency
+}
+
+func (m Money) sameCurrency(o Money) error {
+	if m.currency() != o.currency() {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency(), o.currency())
+	}
+	return nil
+}
+
+func normalizeCurrency(currency string) string {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return DefaultCurrency
+	}
+	return currency
+}
+
+// parseMinor converts a decimal string into minor units with exp fractional
+// digits, rounding half away from zero.
+func parseMinor(s string, exp int) (int64, error) {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return 0, fmt.Errorf("money: invalid amount %q", s)
+	}
+	r.Mul(r, pow10Rat(exp))
+	minor, err := strconv.ParseInt(r.FloatString(0), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("money: amount %q out of range", s)
+	}
+	return minor, nil
+}
+
+// pow10Rat returns 10^exp, which may be negative.
+func pow10Rat(exp int) *big.Rat {
+	p := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(absInt(exp))), nil)
+	if exp < 0 {
+		return new(big.Rat).SetFrac(big.NewInt(1), p)
+	}
+	return new(big.Rat).SetInt(p)
+}
+
+func absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// formatMinor renders minor units as a decimal string. When trim is set,
+// trailing fractional zeros are dropped so 1230 renders as "12.3".
+func formatMinor(minor int64, exp int, trim bool) string {
+	sign := ""
+	abs := new(big.Int).SetInt64(minor)
+	if abs.Sign() < 0 {
+		sign = "-"
+		abs.Neg(abs)
+	}
+	digits := abs.String()
+	if exp == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+	whole, frac := digits[:len(digits)-exp], digits[len(digits)-exp:]
+	if trim {
+		frac = strings.TrimRight(frac, "0")
+		if frac == "" {
+			return sign + whole
+		}
+	}
+	return sign + whole + "." + frac
+}
@@ -0,0 +1,186 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestMoneyFromFloat(t *testing.T) {
+	tests := []struct {
+		amount   float64
+		currency string
+		want     int64
+	}{
+		{12.3, "BRL", 1230},
+		{0.1 + 0.2, "USD", 30},
+		{0.005, "USD", 1},
+		{-0.005, "USD", -1},
+		{1234.5, "JPY", 1235},
+		{1.2345, "KWD", 1235},
+		{0.0005, "BHD", 1},
+		{19.99, "", 1999},
+	}
+	for _, tt := range tests {
+		if got := MoneyFromFloat(tt.amount, tt.currency); got.Minor != tt.want {
+			t.Errorf("MoneyFromFloat(%v, %q) = %d, want %d", tt.amount, tt.currency, got.Minor, tt.want)
+		}
+	}
+}
+
+func TestMoneyFormat(t *testing.T) {
+	tests := []struct {
+		money   Money
+		decimal string
+		json    string
+	}{
+		{NewMoney(1230, "BRL"), "12.30", "12.3"},
+		{NewMoney(5, "USD"), "0.05", "0.05"},
+		{NewMoney(-5, "USD"), "-0.05", "-0.05"},
+		{NewMoney(1500, "JPY"), "1500", "1500"},
+		{NewMoney(1234, "KWD"), "1.234", "1.234"},
+		{NewMoney(1200, "OMR"), "1.200", "1.2"},
+		{NewMoney(7, "TND"), "0.007", "0.007"},
+		{Money{Minor: 100}, "1.00", "1"},
+	}
+	for _, tt := range tests {
+		if got := tt.money.Decimal(); got != tt.decimal {
+			t.Errorf("%v.Decimal() = %q, want %q", tt.money, got, tt.decimal)
+		}
+		data, err := json.Marshal(tt.money)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != tt.json {
+			t.Errorf("json.Marshal(%v) = %s, want %s", tt.money, data, tt.json)
+		}
+	}
+}
+
+func TestMoneyUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		data     string
+		currency string
+		want     int64
+		wantErr  bool
+	}{
+		{"12.3", "BRL", 1230, false},
+		{"12.345", "BRL", 1235, false},
+		{"1.2345", "KWD", 1235, false},
+		{"0.001", "BHD", 1, false},
+		{"1500.4", "JPY", 1500, false},
+		{"-2.5", "", -250, false},
+		{`"12.30"`, "BRL", 1230, false},
+		{`"twelve"`, "BRL", 0, true},
+		{"1e30", "BRL", 0, true},
+	}
+	for _, tt := range tests {
+		m := Money{Currency: tt.currency}
+		err := json.Unmarshal([]byte(tt.data), &m)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Unmarshal(%s) into %q: err = %v, wantErr %v", tt.data, tt.currency, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && m.Minor != tt.want {
+			t.Errorf("Unmarshal(%s) into %q = %d, want %d", tt.data, tt.currency, m.Minor, tt.want)
+		}
+	}
+}
+
+func TestMoneyIn(t *testing.T) {
+	tests := []struct {
+		json     string
+		currency string
+		want     Money
+	}{
+		{"12.34", "USD", NewMoney(1234, "USD")},
+		{"1.234", "KWD", NewMoney(1234, "KWD")},
+		{"0.001", "BHD", NewMoney(1, "BHD")},
+		{"1.2345", "OMR", NewMoney(1235, "OMR")},
+		{"12.5", "JPY", NewMoney(13, "JPY")},
+		{"12.49", "JPY", NewMoney(12, "JPY")},
+		{"1.234", "BRL", NewMoney(123, "BRL")},
+	}
+	for _, tt := range tests {
+		var m Money
+		if err := json.Unmarshal([]byte(tt.json), &m); err != nil {
+			t.Fatal(err)
+		}
+		if got := m.In(tt.currency); got != tt.want {
+			t.Errorf("%s.In(%q) = %#v, want %#v", tt.json, tt.currency, got, tt.want)
+		}
+	}
+
+	if got := NewMoney(1234, "EUR").In("KWD"); got != NewMoney(1234, "EUR") {
+		t.Errorf("In rebound an amount that has a currency: %#v", got)
+	}
+	if got := (Money{Minor: 1234}).In("KWD"); got != NewMoney(12340, "KWD") {
+		t.Errorf("In rescaled 12.34 to %#v, want 12.340 KWD", got)
+	}
+}
+
+// TestMoneyRoundTrip decodes payloads whose currency follows the amount and
+// expects every digit of the amount back.
+func TestMoneyRoundTrip(t *testing.T) {
+	tests := []struct {
+		payload string
+		want    string
+	}{
+		{`{"amount":1.234,"currency":"KWD"}`, "1.234"},
+		{`{"amount":0.005,"currency":"TND"}`, "0.005"},
+		{`{"amount":9.999,"currency":"OMR"}`, "9.999"},
+		{`{"amount":12.3,"currency":"BHD"}`, "12.3"},
+		{`{"amount":1500,"currency":"JPY"}`, "1500"},
+		{`{"amount":19.99}`, "19.99"},
+	}
+	for _, tt := range tests {
+		var expense Expense
+		if err := json.Unmarshal([]byte(tt.payload), &expense); err != nil {
+			t.Fatal(err)
+		}
+		data, err := json.Marshal(expense.Amount)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != tt.want {
+			t.Errorf("%s decoded to %s (%v), want %s", tt.payload, data, expense.Amount, tt.want)
+		}
+	}
+}
+
+func TestMoneyConvert(t *testing.T) {
+	tests := []struct {
+		money    Money
+		rate     float64
+		currency string
+		want     int64
+	}{
+		{NewMoney(10000, "USD"), 5.4321, "BRL", 54321},
+		{NewMoney(1, "USD"), 0.5, "BRL", 1},
+		{NewMoney(-1, "USD"), 0.5, "BRL", -1},
+		{NewMoney(10000, "USD"), 151.237, "JPY", 15124},
+		{NewMoney(1000, "JPY"), 0.00205, "KWD", 2050},
+		{NewMoney(1234, "KWD"), 3.25, "USD", 401},
+		{NewMoney(1999, "BRL"), 1, "BRL", 1999},
+	}
+	for _, tt := range tests {
+		got := tt.money.Convert(tt.rate, tt.currency)
+		if got.Minor != tt.want || got.Currency != tt.currency {
+			t.Errorf("%v.Convert(%v, %s) = %v, want %d %s", tt.money, tt.rate, tt.currency, got, tt.want, tt.currency)
+		}
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	sum, err := NewMoney(1005, "KWD").Add(NewMoney(995, "kwd"))
+	if err != nil || sum != NewMoney(2000, "KWD") {
+		t.Errorf("Add = %v, %v; want 2.000 KWD", sum, err)
+	}
+	diff, err := Money{Minor: 100}.Sub(NewMoney(250, DefaultCurrency))
+	if err != nil || diff != NewMoney(-150, DefaultCurrency) {
+		t.Errorf("Sub = %v, %v; want -1.50 %s", diff, err, DefaultCurrency)
+	}
+	if _, err := NewMoney(1, "USD").Add(NewMoney(1, "EUR")); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Add across currencies: err = %v, want ErrCurrencyMismatch", err)
+	}
+}
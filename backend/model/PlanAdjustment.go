@@ -0,0 +1,24 @@
+package model
+
+import (
+	"github.com/uptrace/bun"
+	"time"
+)
+
+// PlanAdjustment is one entry of the append-only history of changes to the
+// TotalAmount of a BudgetPlan. Amount is signed and in the plan's base
+// currency; OriginalAmount is what the author entered, before conversion.
+type PlanAdjustment struct {
+	bun.BaseModel `bun:"table:plan_amount_adjustments"`
+
+	ID             int       `bun:",pk,autoincrement" json:"id"`
+	PlanID         int       `json:"plan_id"`
+	Amount         Money     `bun:"embed:amount_" json:"amount"`
+	OriginalAmount Money     `bun:"embed:original_amount_" json:"original_amount"`
+	ExchangeRate   float64   `json:"exchange_rate"`
+	TotalAfter     Money     `bun:"embed:total_after_" json:"total_after"`
+	Reason         string    `json:"reason"`
+	AuthorID       *int      `json:"author_id"`
+	AuthorEmail    string    `bun:",scanonly" json:"author_email,omitempty"`
+	CreatedAt      time.Time `bun:",nullzero,default:current_timestamp" json:"created_at"`
+}
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"github.com/uptrace/bun"
+	"time"
+)
+
+// PlanRole is what a member may do in a shared BudgetPlan.
+type PlanRole string
+
+const (
+	// RoleOwner manages the plan and its members.
+	RoleOwner PlanRole = "owner"
+	// RoleEditor records and changes expenses, incomes and amounts.
+	RoleEditor PlanRole = "editor"
+	// RoleViewer only reads the plan.
+	RoleViewer PlanRole = "viewer"
+)
+
+var roleRank = map[PlanRole]int{RoleViewer: 1, RoleEditor: 2, RoleOwner: 3}
+
+// Valid reports whether the role is one of the known roles.
+func (r PlanRole) Valid() bool {
+	return roleRank[r] > 0
+}
+
+// AtLeast reports whether the role grants everything other grants.
+func (r PlanRole) AtLeast(other PlanRole) bool {
+	return roleRank[r] >= roleRank[other]
+}
+
+// PlanMember gives a user access to a BudgetPlan. The plan owner is a member too.
+type PlanMember struct {
+	bun.BaseModel `bun:"table:plan_members"`
+
+	PlanID    int       `bun:",pk" json:"plan_id"`
+	UserID    int       `bun:",pk" json:"user_id"`
+	Role      PlanRole  `json:"role"`
+	InvitedBy *int      `json:"invited_by"`
+	JoinedAt  time.Time `bun:",nullzero,default:current_timestamp" json:"joined_at"`
+	Name      string    `bun:",scanonly" json:"name"`
+	Email     string    `bun:",scanonly" json:"email"`
+}
+
+// InvitationStatus is the state of a PlanInvitation.
+type InvitationStatus string
+
+const (
+	InvitationPending  InvitationStatus = "pending"
+	InvitationAccepted InvitationStatus = "accepted"
+	InvitationDeclined InvitationStatus = "declined"
+	InvitationRevoked  InvitationStatus = "revoked"
+)
+
+// PlanInvitation offers a registered user, by email, to join a BudgetPlan with a role.
+type PlanInvitation struct {
+	bun.BaseModel `bun:"table:plan_invitations"`
+
+	ID          int              `bun:",pk,autoincrement" json:"id"`
+	PlanID      int              `json:"plan_id"`
+	PlanName    string           `bun:",scanonly" json:"plan_name"`
+	Email       string           `json:"email"`
+	Role        PlanRole         `json:"role"`
+	Status      InvitationStatus `json:"status"`
+	InvitedBy   *int             `json:"invited_by"`
+	CreatedAt   time.Time        `bun:",nullzero,default:current_timestamp" json:"created_at"`
+	RespondedAt *time.Time       `json:"responded_at"`
+}
@@ -0,0 +1,287 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"github.com/uptrace/bun"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is the unit a RecurringExpense repeats in.
+type Frequency string
+
+const (
+	Daily   Frequency = "daily"
+	Weekly  Frequency = "weekly"
+	Monthly Frequency = "monthly"
+	Yearly  Frequency = "yearly"
+)
+
+// maxOccurrenceScan bounds the number of candidate dates examined while
+// enumerating a rule, so that a malformed rule can never loop forever.
+const maxOccurrenceScan = 100000
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// RecurringExpense is the template the scheduler uses to materialize Expenses.
+// Occurrences up to NextOccurrence (exclusive) have already been created.
+type RecurringExpense struct {
+	bun.BaseModel `bun:"table:recurring_expenses"`
+
+	ID             int         `bun:",pk,autoincrement" json:"id"`
+	UserID         int         `json:"user_id"`
+	BudgetID       int         `json:"budget_id"`
+	CategoryID     int         `json:"category_id"`
+	CategoryName   string      `json:"category_name"`
+	Description    string      `json:"description"`
+	Amount         Money       `bun:"embed:amount_" json:"amount"`
+	Frequency      Frequency   `json:"frequency"`
+	Interval       int         `bun:"interval_count" json:"interval"`
+	ByDay          string      `json:"by_day"`
+	ByMonthDay     int         `json:"by_month_day"`
+	Count          int         `json:"count"`
+	StartDate      time.Time   `json:"start_date"`
+	EndDate        *time.Time  `json:"end_date"`
+	NextOccurrence *time.Time  `json:"next_occurrence"`
+	Paused         bool        `json:"paused"`
+	SkippedDates   []time.Time `bun:",array" json:"skipped_dates"`
+}
+
+// ApplyRRule sets the recurrence fields from an RFC 5545 RRULE. Only the
+// FREQ, INTERVAL, COUNT, UNTIL, BYDAY (weekly) and BYMONTHDAY (monthly)
+// parts are supported.
+func (r *RecurringExpense) ApplyRRule(rule string) error {
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+	for _, part := range strings.Split(rule, ";") {
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return fmt.Errorf("rrule: malformed part %q", part)
+		}
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			r.Frequency = Frequency(strings.ToLower(value))
+		case "INTERVAL", "COUNT", "BYMONTHDAY":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("rrule: invalid %s %q", key, value)
+			}
+			switch strings.ToUpper(key) {
+			case "INTERVAL":
+				r.Interval = n
+			case "COUNT":
+				r.Count = n
+			default:
+				r.ByMonthDay = n
+			}
+		case "UNTIL":
+			until, err := parseRRuleDate(value)
+			if err != nil {
+				return err
+			}
+			r.EndDate = &until
+		case "BYDAY":
+			r.ByDay = strings.ToUpper(value)
+		default:
+			return fmt.Errorf("rrule: unsupported part %q", key)
+		}
+	}
+	return nil
+}
+
+func parseRRuleDate(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("rrule: invalid UNTIL %q", value)
+}
+
+// Validate normalizes the rule and reports whether it can be scheduled.
+func (r *RecurringExpense) Validate() error {
+	if r.Interval == 0 {
+		r.Interval = 1
+	}
+	r.StartDate = truncateDay(r.StartDate)
+	if r.EndDate != nil {
+		end := truncateDay(*r.EndDate)
+		r.EndDate = &end
+	}
+	switch r.Frequency {
+	case Daily, Yearly:
+	case Weekly:
+		if _, err := r.weekdays(); err != nil {
+			return err
+		}
+	case Monthly:
+		if r.ByMonthDay < 0 || r.ByMonthDay > 31 {
+			return errors.New("by_month_day must be between 1 and 31")
+		}
+	default:
+		return fmt.Errorf("unknown frequency %q", r.Frequency)
+	}
+	if r.Interval < 1 {
+		return errors.New("interval must be positive")
+	}
+	if r.Count < 0 {
+		return errors.New("count must not be negative")
+	}
+	if r.StartDate.IsZero() {
+		return errors.New("start_date is required")
+	}
+	if r.EndDate != nil && r.EndDate.Before(r.StartDate) {
+		return errors.New("end_date must not be before start_date")
+	}
+	return nil
+}
+
+// NextAfter returns the first occurrence strictly after the given day, or
+// false when the rule has ended. Passing the zero time yields the first one.
+// Enumeration resumes near after instead of at the start date, so that
+// walking a long-running rule occurrence by occurrence stays linear.
+func (r *RecurringExpense) NextAfter(after time.Time) (time.Time, bool) {
+	after = truncateDay(after)
+	// candidates before the start date only lead the sequence
+	lead := 0
+	for lead < maxOccurrenceScan {
+		candidate, ok := r.candidate(lead)
+		if !ok || !candidate.Before(r.StartDate) {
+			break
+		}
+		lead++
+	}
+	first := r.indexNear(after)
+	if first < lead {
+		first = lead
+	}
+	seen := first - lead
+	for i := first; i < first+maxOccurrenceScan; i++ {
+		candidate, ok := r.candidate(i)
+		if !ok {
+			return time.Time{}, false
+		}
+		if candidate.Before(r.StartDate) {
+			continue
+		}
+		if r.EndDate != nil && candidate.After(*r.EndDate) {
+			return time.Time{}, false
+		}
+		seen++
+		if r.Count > 0 && seen > r.Count {
+			return time.Time{}, false
+		}
+		if after.IsZero() || candidate.After(after) {
+			return candidate, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// indexNear returns the index of a candidate such that every candidate
+// before it falls on or before after, in constant time.
+func (r *RecurringExpense) indexNear(after time.Time) int {
+	start := r.StartDate
+	if after.IsZero() || !after.After(start) || r.Interval < 1 {
+		return 0
+	}
+	switch r.Frequency {
+	case Daily:
+		days := int(after.Sub(start).Hours() / 24)
+		return days / r.Interval
+	case Weekly:
+		days, _ := r.weekdays()
+		monday := start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+		weeks := int(after.Sub(monday).Hours()/24) / 7
+		return weeks / r.Interval * len(days)
+	case Monthly:
+		months := (after.Year()-start.Year())*12 + int(after.Month()) - int(start.Month())
+		return months / r.Interval
+	case Yearly:
+		return (after.Year() - start.Year()) / r.Interval
+	}
+	return 0
+}
+
+// IsSkipped reports whether the occurrence on day was skipped by the user.
+func (r *RecurringExpense) IsSkipped(day time.Time) bool {
+	day = truncateDay(day)
+	for _, skipped := range r.SkippedDates {
+		if truncateDay(skipped).Equal(day) {
+			return true
+		}
+	}
+	return false
+}
+
+// candidate returns the i-th date produced by the frequency before COUNT and
+// UNTIL are applied. Dates are generated in ascending order.
+func (r *RecurringExpense) candidate(i int) (time.Time, bool) {
+	start := r.StartDate
+	switch r.Frequency {
+	case Daily:
+		return start.AddDate(0, 0, i*r.Interval), true
+	case Weekly:
+		days, _ := r.weekdays()
+		week, slot := i/len(days), i%len(days)
+		monday := start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+		offset := (int(days[slot]) + 6) % 7
+		return monday.AddDate(0, 0, week*7*r.Interval+offset), true
+	case Monthly:
+		day := r.ByMonthDay
+		if day == 0 {
+			day = start.Day()
+		}
+		first := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, i*r.Interval, 0)
+		return clampDay(first.Year(), first.Month(), day), true
+	case Yearly:
+		return clampDay(start.Year()+i*r.Interval, start.Month(), start.Day()), true
+	}
+	return time.Time{}, false
+}
+
+// weekdays returns the BYDAY days ordered from Monday, defaulting to the start day.
+func (r *RecurringExpense) weekdays() ([]time.Weekday, error) {
+	if r.ByDay == "" {
+		return []time.Weekday{r.StartDate.Weekday()}, nil
+	}
+	var set [7]bool
+	for _, code := range strings.Split(r.ByDay, ",") {
+		day, ok := weekdayCodes[strings.ToUpper(strings.TrimSpace(code))]
+		if !ok {
+			return nil, fmt.Errorf("invalid by_day %q", code)
+		}
+		set[day] = true
+	}
+	var days []time.Weekday
+	for _, day := range []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday} {
+		if set[day] {
+			days = append(days, day)
+		}
+	}
+	return days, nil
+}
+
+// clampDay builds a date, moving days past the end of the month to its last
+// day so that a rule on the 31st still fires in shorter months.
+func clampDay(year int, month time.Month, day int) time.Time {
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if day > last {
+		day = last
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func truncateDay(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
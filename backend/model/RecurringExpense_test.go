@@ -0,0 +1,164 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+// occurrences walks a rule the way the scheduler does, at most n times.
+func occurrences(r RecurringExpense, n int) []time.Time {
+	var dates []time.Time
+	var after time.Time
+	for len(dates) < n {
+		next, ok := r.NextAfter(after)
+		if !ok {
+			break
+		}
+		dates = append(dates, next)
+		after = next
+	}
+	return dates
+}
+
+func TestNextAfterOccurrences(t *testing.T) {
+	until := day(2024, 3, 31)
+	tests := []struct {
+		name string
+		rule RecurringExpense
+		want []time.Time
+	}{
+		{
+			name: "monthly on the 31st clamps to the end of shorter months",
+			rule: RecurringExpense{Frequency: Monthly, StartDate: day(2024, 1, 31)},
+			want: []time.Time{day(2024, 1, 31), day(2024, 2, 29), day(2024, 3, 31), day(2024, 4, 30), day(2024, 5, 31), day(2024, 6, 30)},
+		},
+		{
+			name: "monthly on the 31st outside a leap year",
+			rule: RecurringExpense{Frequency: Monthly, StartDate: day(2023, 1, 31)},
+			want: []time.Time{day(2023, 1, 31), day(2023, 2, 28), day(2023, 3, 31)},
+		},
+		{
+			name: "by month day 31 starting mid-month",
+			rule: RecurringExpense{Frequency: Monthly, ByMonthDay: 31, StartDate: day(2024, 4, 10)},
+			want: []time.Time{day(2024, 4, 30), day(2024, 5, 31), day(2024, 6, 30)},
+		},
+		{
+			name: "by month day before the start day skips the first month",
+			rule: RecurringExpense{Frequency: Monthly, ByMonthDay: 5, StartDate: day(2024, 4, 10)},
+			want: []time.Time{day(2024, 5, 5), day(2024, 6, 5)},
+		},
+		{
+			name: "every other month on the 30th",
+			rule: RecurringExpense{Frequency: Monthly, Interval: 2, StartDate: day(2023, 12, 30)},
+			want: []time.Time{day(2023, 12, 30), day(2024, 2, 29), day(2024, 4, 30)},
+		},
+		{
+			name: "yearly on the 29th of February",
+			rule: RecurringExpense{Frequency: Yearly, StartDate: day(2024, 2, 29)},
+			want: []time.Time{day(2024, 2, 29), day(2025, 2, 28), day(2026, 2, 28), day(2027, 2, 28), day(2028, 2, 29)},
+		},
+		{
+			name: "weekly on two days",
+			rule: RecurringExpense{Frequency: Weekly, ByDay: "TU,FR", StartDate: day(2024, 5, 1)},
+			want: []time.Time{day(2024, 5, 3), day(2024, 5, 7), day(2024, 5, 10), day(2024, 5, 14)},
+		},
+		{
+			name: "every three days",
+			rule: RecurringExpense{Frequency: Daily, Interval: 3, StartDate: day(2024, 2, 27)},
+			want: []time.Time{day(2024, 2, 27), day(2024, 3, 1), day(2024, 3, 4)},
+		},
+		{
+			name: "count stops the rule",
+			rule: RecurringExpense{Frequency: Monthly, Count: 2, StartDate: day(2024, 1, 31)},
+			want: []time.Time{day(2024, 1, 31), day(2024, 2, 29)},
+		},
+		{
+			name: "end date stops the rule",
+			rule: RecurringExpense{Frequency: Monthly, StartDate: day(2024, 1, 31), EndDate: &until},
+			want: []time.Time{day(2024, 1, 31), day(2024, 2, 29), day(2024, 3, 31)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.rule.Interval == 0 {
+				tt.rule.Interval = 1
+			}
+			// bounded rules are walked one step further to see them end
+			n := len(tt.want)
+			if tt.rule.Count > 0 || tt.rule.EndDate != nil {
+				n++
+			}
+			got := occurrences(tt.rule, n)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d occurrences %v, want %v", len(got), got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Equal(tt.want[i]) {
+					t.Errorf("occurrence %d = %s, want %s", i, got[i].Format(time.DateOnly), tt.want[i].Format(time.DateOnly))
+				}
+			}
+		})
+	}
+}
+
+// TestNextAfterResumes checks that resuming from an arbitrary day, as the
+// scheduler does after a restart, lands on the same occurrence as walking
+// the rule from its start.
+func TestNextAfterResumes(t *testing.T) {
+	rule := RecurringExpense{Frequency: Monthly, Interval: 1, StartDate: day(2020, 1, 31)}
+	tests := []struct {
+		after time.Time
+		want  time.Time
+	}{
+		{day(2020, 1, 30), day(2020, 1, 31)},
+		{day(2020, 1, 31), day(2020, 2, 29)},
+		{day(2021, 2, 27), day(2021, 2, 28)},
+		{day(2021, 2, 28), day(2021, 3, 31)},
+		{day(2030, 4, 30), day(2030, 5, 31)},
+		{day(2030, 4, 29), day(2030, 4, 30)},
+	}
+	for _, tt := range tests {
+		got, ok := rule.NextAfter(tt.after)
+		if !ok || !got.Equal(tt.want) {
+			t.Errorf("NextAfter(%s) = %s, %v; want %s", tt.after.Format(time.DateOnly), got.Format(time.DateOnly), ok, tt.want.Format(time.DateOnly))
+		}
+	}
+}
+
+func TestValidateRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		rule RecurringExpense
+	}{
+		{"unknown frequency", RecurringExpense{Frequency: "hourly", StartDate: day(2024, 1, 1)}},
+		{"month day past 31", RecurringExpense{Frequency: Monthly, ByMonthDay: 32, StartDate: day(2024, 1, 1)}},
+		{"invalid weekday", RecurringExpense{Frequency: Weekly, ByDay: "XX", StartDate: day(2024, 1, 1)}},
+		{"negative interval", RecurringExpense{Frequency: Daily, Interval: -1, StartDate: day(2024, 1, 1)}},
+		{"missing start date", RecurringExpense{Frequency: Daily}},
+		{"end before start", RecurringExpense{Frequency: Daily, StartDate: day(2024, 1, 2), EndDate: &[]time.Time{day(2024, 1, 1)}[0]}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.rule.Validate(); err == nil {
+				t.Error("Validate accepted the rule")
+			}
+		})
+	}
+}
+
+func TestApplyRRule(t *testing.T) {
+	var r RecurringExpense
+	if err := r.ApplyRRule("RRULE:FREQ=MONTHLY;INTERVAL=2;BYMONTHDAY=31;COUNT=6;UNTIL=20251231"); err != nil {
+		t.Fatal(err)
+	}
+	if r.Frequency != Monthly || r.Interval != 2 || r.ByMonthDay != 31 || r.Count != 6 || r.EndDate == nil || !r.EndDate.Equal(day(2025, 12, 31)) {
+		t.Errorf("ApplyRRule set %+v", r)
+	}
+	if err := r.ApplyRRule("FREQ=WEEKLY;BYSETPOS=1"); err == nil {
+		t.Error("ApplyRRule accepted an unsupported part")
+	}
+}
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// ReportRow is one group of an expense aggregation, shaped for the charts.
+// Category reports roll subcategories up into their parent's row and list
+// them as Children.
+type ReportRow struct {
+	Name     string      `json:"name"`
+	Value    Money       `json:"value"`
+	Count    int         `json:"count"`
+	Children []ReportRow `json:"children,omitempty"`
+}
+
+// Report aggregates the expenses of a plan, converted to its base currency.
+type Report struct {
+	PlanID   int         `json:"plan_id"`
+	GroupBy  string      `json:"group_by"`
+	Currency string      `json:"currency"`
+	From     *time.Time  `json:"from,omitempty"`
+	To       *time.Time  `json:"to,omitempty"`
+	Total    Money       `json:"total"`
+	Rows     []ReportRow `json:"rows"`
+}
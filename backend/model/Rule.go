@@ -0,0 +1,240 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/uptrace/bun"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ExpenseRule categorizes and tags the expenses that match all of its
+// conditions. Rules belong to a user and apply to every plan of that user,
+// or only to PlanID when it is set.
+type ExpenseRule struct {
+	bun.BaseModel `bun:"table:expense_rules"`
+
+	ID     int    `bun:",pk,autoincrement" json:"id"`
+	UserID int    `json:"user_id"`
+	PlanID *int   `json:"plan_id"`
+	Name   string `json:"name"`
+	// Priority orders the rules, lowest first.
+	Priority int  `json:"priority"`
+	Enabled  bool `json:"enabled"`
+
+	// DescriptionPattern is a regular expression matched against the
+	// description, ignoring case.
+	DescriptionPattern string `bun:",nullzero" json:"description_pattern"`
+	// MinAmount and MaxAmount bound the amount, both inclusive, in minor units
+	// of Currency. Only expenses with an amount in Currency can match them.
+	MinAmount *int64     `json:"-"`
+	MaxAmount *int64     `json:"-"`
+	Currency  string     `bun:",nullzero" json:"currency"`
+	DateFrom  *time.Time `json:"date_from"`
+	DateTo    *time.Time `json:"date_to"`
+
+	CategoryID   *int      `json:"category_id"`
+	CategoryName string    `bun:",scanonly" json:"category_name,omitempty"`
+	Tags         []string  `bun:",array" json:"tags"`
+	CreatedAt    time.Time `bun:",nullzero,default:current_timestamp" json:"created_at"`
+}
+
+// MarshalJSON writes the amount bounds as numbers in major units of Currency.
+func (r ExpenseRule) MarshalJSON() ([]byte, error) {
+	type alias ExpenseRule
+	bound := func(minor *int64) *Money {
+		if minor == nil {
+			return nil
+		}
+		m := NewMoney(*minor, r.Currency)
+		return &m
+	}
+	return json.Marshal(struct {
+		alias
+		MinAmount *Money `json:"min_amount"`
+		MaxAmount *Money `json:"max_amount"`
+	}{alias(r), bound(r.MinAmount), bound(r.MaxAmount)})
+}
+
+// UnmarshalJSON reads the amount bounds in major units of Currency.
+func (r *ExpenseRule) UnmarshalJSON(data []byte) error {
+	type alias ExpenseRule
+	aux := struct {
+		*alias
+		MinAmount *Money `json:"min_amount"`
+		MaxAmount *Money `json:"max_amount"`
+	}{alias: (*alias)(r)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	bound := func(m *Money) *int64 {
+		if m == nil {
+			return nil
+		}
+		minor := m.In(r.Currency).Minor
+		return &minor
+	}
+	r.MinAmount, r.MaxAmount = bound(aux.MinAmount), bound(aux.MaxAmount)
+	return nil
+}
+
+// Validate checks that the rule has at least one condition and one action
+// and that its conditions are well formed.
+func (r *ExpenseRule) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return errors.New("name is required")
+	}
+	if r.DescriptionPattern == "" && r.MinAmount == nil && r.MaxAmount == nil && r.DateFrom == nil && r.DateTo == nil {
+		return errors.New("a rule needs at least one condition")
+	}
+	if r.CategoryID == nil && len(r.Tags) == 0 {
+		return errors.New("a rule needs a category or tags")
+	}
+	if _, err := r.pattern(); err != nil {
+		return fmt.Errorf("invalid description_pattern: %v", err)
+	}
+	r.Currency = strings.ToUpper(strings.TrimSpace(r.Currency))
+	if (r.MinAmount != nil || r.MaxAmount != nil) && r.Currency == "" {
+		return errors.New("currency is required with min_amount or max_amount")
+	}
+	if r.MinAmount != nil && r.MaxAmount != nil && *r.MinAmount > *r.MaxAmount {
+		return errors.New("min_amount must not be greater than max_amount")
+	}
+	if r.DateFrom != nil && r.DateTo != nil && r.DateFrom.After(*r.DateTo) {
+		return errors.New("date_from must not be after date_to")
+	}
+	tags, err := NormalizeTags(r.Tags)
+	if err != nil {
+		return err
+	}
+	r.Tags = tags
+	return nil
+}
+
+func (r *ExpenseRule) pattern() (*regexp.Regexp, error) {
+	if r.DescriptionPattern == "" {
+		return nil, nil
+	}
+	return regexp.Compile("(?i)" + r.DescriptionPattern)
+}
+
+// RuleOutcome is what the matching rules decide for an expense.
+type RuleOutcome struct {
+	CategoryID *int
+	Tags       []string
+	RuleIDs    []int
+}
+
+// RuleSet evaluates rules in priority order.
+type RuleSet struct {
+	rules    []ExpenseRule
+	patterns []*regexp.Regexp
+}
+
+// NewRuleSet orders the enabled rules by priority and compiles their patterns.
+func NewRuleSet(rules []ExpenseRule) (*RuleSet, error) {
+	set := &RuleSet{}
+	for _, rule := range rules {
+		if rule.Enabled {
+			set.rules = append(set.rules, rule)
+		}
+	}
+	sort.SliceStable(set.rules, func(i, j int) bool {
+		if set.rules[i].Priority != set.rules[j].Priority {
+			return set.rules[i].Priority < set.rules[j].Priority
+		}
+		return set.rules[i].ID < set.rules[j].ID
+	})
+	set.patterns = make([]*regexp.Regexp, len(set.rules))
+	for i := range set.rules {
+		pattern, err := set.rules[i].pattern()
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %v", set.rules[i].ID, err)
+		}
+		set.patterns[i] = pattern
+	}
+	return set, nil
+}
+
+// Evaluate runs every rule against expense. The first matching rule with a
+// category decides it, and the tags of every matching rule are collected.
+func (s *RuleSet) Evaluate(expense *Expense) RuleOutcome {
+	var outcome RuleOutcome
+	for i := range s.rules {
+		rule := &s.rules[i]
+		if !s.matches(i, expense) {
+			continue
+		}
+		outcome.RuleIDs = append(outcome.RuleIDs, rule.ID)
+		if outcome.CategoryID == nil && rule.CategoryID != nil {
+			outcome.CategoryID = rule.CategoryID
+		}
+		outcome.Tags = append(outcome.Tags, rule.Tags...)
+	}
+	outcome.Tags, _ = NormalizeTags(outcome.Tags)
+	return outcome
+}
+
+func (s *RuleSet) matches(i int, expense *Expense) bool {
+	rule := &s.rules[i]
+	if rule.PlanID != nil && *rule.PlanID != expense.BudgetID {
+		return false
+	}
+	if s.patterns[i] != nil && !s.patterns[i].MatchString(expense.Description) {
+		return false
+	}
+	if rule.MinAmount != nil || rule.MaxAmount != nil {
+		amount, ok := amountIn(expense, rule.Currency)
+		if !ok {
+			return false
+		}
+		if rule.MinAmount != nil && amount < *rule.MinAmount {
+			return false
+		}
+		if rule.MaxAmount != nil && amount > *rule.MaxAmount {
+			return false
+		}
+	}
+	day := truncateDay(expense.Date)
+	if rule.DateFrom != nil && day.Before(truncateDay(*rule.DateFrom)) {
+		return false
+	}
+	if rule.DateTo != nil && day.After(truncateDay(*rule.DateTo)) {
+		return false
+	}
+	return true
+}
+
+// amountIn returns the amount of an expense in currency, from either its
+// original amount or its amount in the plan's base currency.
+func amountIn(expense *Expense, currency string) (int64, bool) {
+	switch strings.ToUpper(currency) {
+	case expense.Amount.CurrencyCode():
+		return expense.Amount.Minor, true
+	case expense.BaseAmount.CurrencyCode():
+		return expense.BaseAmount.Minor, true
+	}
+	return 0, false
+}
+
+// RuleChange describes how the rules change one expense.
+type RuleChange struct {
+	ExpenseID    int      `json:"expense_id"`
+	Description  string   `json:"description"`
+	RuleIDs      []int    `json:"rule_ids"`
+	FromCategory string   `json:"from_category,omitempty"`
+	ToCategory   string   `json:"to_category,omitempty"`
+	AddedTags    []string `json:"added_tags,omitempty"`
+}
+
+// RuleRun reports the result of running the rules over the expenses of a plan.
+type RuleRun struct {
+	PlanID  int          `json:"plan_id"`
+	DryRun  bool         `json:"dry_run"`
+	Scanned int          `json:"scanned"`
+	Changed int          `json:"changed"`
+	Changes []RuleChange `json:"changes"`
+}
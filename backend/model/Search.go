@@ -0,0 +1,73 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// DefaultSearchLimit and MaxSearchLimit bound the size of a page of search results.
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+)
+
+// ExpenseSearch is a full-text search over the expenses a user can see. Query
+// is matched against the description, the category name and the tag names;
+// when it is empty the filters alone select the expenses. From and To are
+// inclusive days and the amounts are compared in Currency, against either the
+// original amount of an expense or its amount in the plan's base currency.
+type ExpenseSearch struct {
+	Query      string
+	PlanID     int
+	CategoryID int
+	From       *time.Time
+	To         *time.Time
+	Currency   string
+	MinAmount  *Money
+	MaxAmount  *Money
+	Limit      int
+	After      *SearchCursor
+}
+
+// SearchCursor is the position of the last result of a page. Results are
+// ordered by rank, then date, then ID, all descending.
+type SearchCursor struct {
+	Rank float32   `json:"r"`
+	Date time.Time `json:"d"`
+	ID   int       `json:"i"`
+}
+
+// ErrInvalidCursor is returned when a cursor was not produced by Encode.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// CursorAt returns the cursor that resumes the search after expense.
+func CursorAt(expense *Expense) SearchCursor {
+	return SearchCursor{Rank: expense.Rank, Date: expense.Date, ID: expense.ID}
+}
+
+// Encode returns the opaque form of the cursor handed to clients.
+func (c SearchCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParseSearchCursor reads a cursor returned by Encode.
+func ParseSearchCursor(s string) (*SearchCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c SearchCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID <= 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// SearchPage is a page of search results. NextCursor is empty on the last page.
+type SearchPage struct {
+	Items      []Expense `json:"items"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
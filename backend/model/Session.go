@@ -0,0 +1,29 @@
+package model
+
+import (
+	"github.com/uptrace/bun"
+	"time"
+)
+
+// Session is a refresh token issued at login. Only the SHA-256 of the token is
+// stored. Refreshing revokes the session and points ReplacedBy at its successor,
+// so a replayed refresh token can be told apart from an unknown one.
+type Session struct {
+	bun.BaseModel `bun:"table:sessions"`
+
+	ID           int        `bun:",pk,autoincrement" json:"id"`
+	UserID       int        `json:"user_id"`
+	TokenHash    string     `json:"-"`
+	TokenVersion int        `json:"-"`
+	UserAgent    string     `json:"user_agent"`
+	CreatedAt    time.Time  `bun:"default:current_timestamp" json:"created_at"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	LastUsedAt   *time.Time `json:"last_used_at"`
+	RevokedAt    *time.Time `json:"revoked_at"`
+	ReplacedBy   *int       `json:"replaced_by"`
+}
+
+// IsActive reports whether the session can still be refreshed at now.
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,241 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"github.com/uptrace/bun"
+	"sort"
+	"time"
+)
+
+// SplitMethod is how the cost of an Expense is shared between plan members.
+type SplitMethod string
+
+const (
+	SplitEqual      SplitMethod = "equal"
+	SplitPercentage SplitMethod = "percentage"
+	SplitExact      SplitMethod = "exact"
+)
+
+// ExpenseSplit is the share of an Expense owed by one member, in the plan's
+// base currency. The shares of an expense add up to its BaseAmount.
+type ExpenseSplit struct {
+	bun.BaseModel `bun:"table:expense_splits"`
+
+	ExpenseID int    `bun:",pk" json:"expense_id"`
+	UserID    int    `bun:",pk" json:"user_id"`
+	Amount    Money  `bun:"embed:amount_" json:"amount"`
+	Name      string `bun:",scanonly" json:"name,omitempty"`
+}
+
+// SplitShare is one participant of a split. Percent is used by percentage
+// splits and Amount, in the plan's base currency, by exact splits.
+type SplitShare struct {
+	UserID  int     `json:"user_id"`
+	Percent float64 `json:"percent"`
+	Amount  Money   `json:"amount"`
+}
+
+// ComputeSplits divides total between the participants according to method.
+// Rounding leftovers go to the participants with the largest remainders, so
+// the shares always add up to total exactly.
+func ComputeSplits(total Money, method SplitMethod, shares []SplitShare) ([]ExpenseSplit, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("a split needs at least one participant")
+	}
+	seen := make(map[int]bool, len(shares))
+	for _, s := range shares {
+		if seen[s.UserID] {
+			return nil, fmt.Errorf("user %d appears more than once", s.UserID)
+		}
+		seen[s.UserID] = true
+	}
+
+	weights := make([]int64, len(shares))
+	switch method {
+	case SplitEqual:
+		for i := range weights {
+			weights[i] = 1
+		}
+	case SplitPercentage:
+		var sum int64
+		for i, s := range shares {
+			if s.Percent < 0 {
+				return nil, errors.New("percentages must not be negative")
+			}
+			// basis points keep two decimals of precision
+			weights[i] = int64(s.Percent*100 + 0.5)
+			sum += weights[i]
+		}
+		if sum != 10000 {
+			return nil, errors.New("percentages must add up to 100")
+		}
+	case SplitExact:
+		var sum int64
+		for i, s := range shares {
+			amount := s.Amount.In(total.CurrencyCode())
+			if amount.CurrencyCode() != total.CurrencyCode() {
+				return nil, fmt.Errorf("amounts must be in %s", total.CurrencyCode())
+			}
+			if amount.Minor < 0 {
+				return nil, errors.New("amounts must not be negative")
+			}
+			weights[i] = amount.Minor
+			sum += amount.Minor
+		}
+		if sum != total.Minor {
+			return nil, fmt.Errorf("amounts must add up to %s", total.Decimal())
+		}
+	default:
+		return nil, fmt.Errorf("unknown split method %q", method)
+	}
+
+	amounts := allocate(total.Minor, weights)
+	splits := make([]ExpenseSplit, len(shares))
+	for i, s := range shares {
+		splits[i] = ExpenseSplit{UserID: s.UserID, Amount: NewMoney(amounts[i], total.CurrencyCode())}
+	}
+	return splits, nil
+}
+
+// RescaleSplits divides a new total in the same proportions as splits.
+func RescaleSplits(splits []ExpenseSplit, total Money) []ExpenseSplit {
+	weights := make([]int64, len(splits))
+	for i, s := range splits {
+		weights[i] = s.Amount.Minor
+	}
+	amounts := allocate(total.Minor, weights)
+	rescaled := make([]ExpenseSplit, len(splits))
+	for i, s := range splits {
+		rescaled[i] = ExpenseSplit{ExpenseID: s.ExpenseID, UserID: s.UserID, Amount: NewMoney(amounts[i], total.CurrencyCode())}
+	}
+	return rescaled
+}
+
+// allocate divides total in proportion to weights using the largest
+// remainder method. Zero weights everywhere divide it equally.
+func allocate(total int64, weights []int64) []int64 {
+	var sum int64
+	for _, w := range weights {
+		sum += w
+	}
+	if sum == 0 {
+		for i := range weights {
+			weights[i] = 1
+		}
+		sum = int64(len(weights))
+	}
+
+	amounts := make([]int64, len(weights))
+	remainders := make([]int64, len(weights))
+	var given int64
+	for i, w := range weights {
+		amounts[i] = total * w / sum
+		remainders[i] = total * w % sum
+		given += amounts[i]
+	}
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return remainders[order[a]] > remainders[order[b]]
+	})
+	for i := 0; given < total; i++ {
+		amounts[order[i%len(order)]]++
+		given++
+	}
+	return amounts
+}
+
+// SettlementPayment records money one member paid another to settle up.
+type SettlementPayment struct {
+	bun.BaseModel `bun:"table:settlement_payments"`
+
+	ID         int       `bun:",pk,autoincrement" json:"id"`
+	PlanID     int       `json:"plan_id"`
+	FromUserID int       `json:"from_user_id"`
+	ToUserID   int       `json:"to_user_id"`
+	Amount     Money     `bun:"embed:amount_" json:"amount"`
+	Date       time.Time `json:"date"`
+	Note       string    `json:"note"`
+	CreatedBy  *int      `json:"created_by"`
+	CreatedAt  time.Time `bun:",nullzero,default:current_timestamp" json:"created_at"`
+}
+
+// MemberBalance is where a member stands in the shared expenses of a plan.
+// Balance is positive when the others owe the member money.
+type MemberBalance struct {
+	UserID   int    `json:"user_id"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Paid     Money  `json:"paid"`
+	Owed     Money  `json:"owed"`
+	Sent     Money  `json:"sent"`
+	Received Money  `json:"received"`
+	Balance  Money  `json:"balance"`
+}
+
+// Transfer is a payment that settles part of the balances of a plan.
+type Transfer struct {
+	FromUserID int   `json:"from_user_id"`
+	ToUserID   int   `json:"to_user_id"`
+	Amount     Money `json:"amount"`
+}
+
+// Settlement lists the balances of the members of a plan and the transfers
+// that bring them all to zero.
+type Settlement struct {
+	PlanID    int             `json:"plan_id"`
+	Currency  string          `json:"currency"`
+	Balances  []MemberBalance `json:"balances"`
+	Transfers []Transfer      `json:"transfers"`
+}
+
+// SettleBalances returns transfers that bring every balance to zero. It
+// repeatedly pays the largest creditor from the largest debtor, which settles
+// n non-zero balances in at most n-1 transfers. Balances must add up to zero.
+func SettleBalances(balances map[int]int64, currency string) []Transfer {
+	type party struct {
+		userID int
+		amount int64
+	}
+	var creditors, debtors []party
+	for userID, amount := range balances {
+		switch {
+		case amount > 0:
+			creditors = append(creditors, party{userID, amount})
+		case amount < 0:
+			debtors = append(debtors, party{userID, -amount})
+		}
+	}
+	byAmount := func(parties []party) {
+		sort.Slice(parties, func(i, j int) bool {
+			if parties[i].amount != parties[j].amount {
+				return parties[i].amount > parties[j].amount
+			}
+			return parties[i].userID < parties[j].userID
+		})
+	}
+
+	transfers := []Transfer{}
+	for len(creditors) > 0 && len(debtors) > 0 {
+		byAmount(creditors)
+		byAmount(debtors)
+		c, d := &creditors[0], &debtors[0]
+		amount := c.amount
+		if d.amount < amount {
+			amount = d.amount
+		}
+		transfers = append(transfers, Transfer{FromUserID: d.userID, ToUserID: c.userID, Amount: NewMoney(amount, currency)})
+		c.amount -= amount
+		d.amount -= amount
+		if c.amount == 0 {
+			creditors = creditors[1:]
+		}
+		if d.amount == 0 {
+			debtors = debtors[1:]
+		}
+	}
+	return transfers
+}
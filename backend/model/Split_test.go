@@ -0,0 +1,285 @@
+package model
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   int64
+		weights []int64
+		want    []int64
+	}{
+		{"even", 900, []int64{1, 1, 1}, []int64{300, 300, 300}},
+		{"one cent over three", 100, []int64{1, 1, 1}, []int64{34, 33, 33}},
+		{"two cents over three", 101, []int64{1, 1, 1}, []int64{34, 34, 33}},
+		{"largest remainder wins", 1000, []int64{3333, 3333, 3334}, []int64{333, 333, 334}},
+		{"remainder ties go first come", 10, []int64{1, 1, 1, 1, 1, 1, 1}, []int64{2, 2, 2, 1, 1, 1, 1}},
+		{"zero weight gets nothing", 500, []int64{0, 2, 3}, []int64{0, 200, 300}},
+		{"all zero weights split equally", 7, []int64{0, 0}, []int64{4, 3}},
+		{"less than one unit each", 2, []int64{1, 1, 1, 1}, []int64{1, 1, 0, 0}},
+		{"zero total", 0, []int64{5, 5}, []int64{0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := allocate(tt.total, append([]int64{}, tt.weights...))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("allocate(%d, %v) = %v, want %v", tt.total, tt.weights, got, tt.want)
+			}
+			var sum int64
+			for _, amount := range got {
+				sum += amount
+			}
+			if sum != tt.total {
+				t.Errorf("shares add up to %d, want %d", sum, tt.total)
+			}
+		})
+	}
+}
+
+func TestComputeSplits(t *testing.T) {
+	tests := []struct {
+		name   string
+		total  Money
+		method SplitMethod
+		shares []SplitShare
+		want   []int64
+		err    string
+	}{
+		{
+			name:   "equal with a leftover cent",
+			total:  NewMoney(1000, "BRL"),
+			method: SplitEqual,
+			shares: []SplitShare{{UserID: 1}, {UserID: 2}, {UserID: 3}},
+			want:   []int64{334, 333, 333},
+		},
+		{
+			name:   "equal in a 3 decimal currency",
+			total:  NewMoney(10000, "KWD"),
+			method: SplitEqual,
+			shares: []SplitShare{{UserID: 1}, {UserID: 2}, {UserID: 3}},
+			want:   []int64{3334, 3333, 3333},
+		},
+		{
+			name:   "equal in a currency without decimals",
+			total:  NewMoney(1000, "JPY"),
+			method: SplitEqual,
+			shares: []SplitShare{{UserID: 1}, {UserID: 2}, {UserID: 3}},
+			want:   []int64{334, 333, 333},
+		},
+		{
+			name:   "percentages with two decimals",
+			total:  NewMoney(10000, "USD"),
+			method: SplitPercentage,
+			shares: []SplitShare{{UserID: 1, Percent: 33.33}, {UserID: 2, Percent: 33.33}, {UserID: 3, Percent: 33.34}},
+			want:   []int64{3333, 3333, 3334},
+		},
+		{
+			name:   "percentages that round",
+			total:  NewMoney(999, "USD"),
+			method: SplitPercentage,
+			shares: []SplitShare{{UserID: 1, Percent: 50}, {UserID: 2, Percent: 25}, {UserID: 3, Percent: 25}},
+			want:   []int64{499, 250, 250},
+		},
+		{
+			name:   "percentages under 100",
+			total:  NewMoney(10000, "USD"),
+			method: SplitPercentage,
+			shares: []SplitShare{{UserID: 1, Percent: 33.33}, {UserID: 2, Percent: 33.33}, {UserID: 3, Percent: 33.33}},
+			err:    "add up to 100",
+		},
+		{
+			name:   "percentages over 100",
+			total:  NewMoney(10000, "USD"),
+			method: SplitPercentage,
+			shares: []SplitShare{{UserID: 1, Percent: 60}, {UserID: 2, Percent: 50}},
+			err:    "add up to 100",
+		},
+		{
+			name:   "negative percentage",
+			total:  NewMoney(10000, "USD"),
+			method: SplitPercentage,
+			shares: []SplitShare{{UserID: 1, Percent: 120}, {UserID: 2, Percent: -20}},
+			err:    "negative",
+		},
+		{
+			name:   "exact amounts",
+			total:  NewMoney(2500, "BRL"),
+			method: SplitExact,
+			shares: []SplitShare{{UserID: 1, Amount: Money{Minor: 1000}}, {UserID: 2, Amount: Money{Minor: 1500}}},
+			want:   []int64{1000, 1500},
+		},
+		{
+			name:   "exact amounts rescaled to 3 decimals",
+			total:  NewMoney(2500, "KWD"),
+			method: SplitExact,
+			shares: []SplitShare{{UserID: 1, Amount: Money{Minor: 100}}, {UserID: 2, Amount: Money{Minor: 150}}},
+			want:   []int64{1000, 1500},
+		},
+		{
+			name:   "exact amounts short of the total",
+			total:  NewMoney(2500, "BRL"),
+			method: SplitExact,
+			shares: []SplitShare{{UserID: 1, Amount: Money{Minor: 1000}}, {UserID: 2, Amount: Money{Minor: 1499}}},
+			err:    "add up to 25.00",
+		},
+		{
+			name:   "exact amount in another currency",
+			total:  NewMoney(2500, "BRL"),
+			method: SplitExact,
+			shares: []SplitShare{{UserID: 1, Amount: NewMoney(2500, "USD")}},
+			err:    "must be in BRL",
+		},
+		{
+			name:   "participant twice",
+			total:  NewMoney(100, "BRL"),
+			method: SplitEqual,
+			shares: []SplitShare{{UserID: 1}, {UserID: 1}},
+			err:    "more than once",
+		},
+		{
+			name:   "no participants",
+			total:  NewMoney(100, "BRL"),
+			method: SplitEqual,
+			err:    "at least one",
+		},
+		{
+			name:   "unknown method",
+			total:  NewMoney(100, "BRL"),
+			method: "shares",
+			shares: []SplitShare{{UserID: 1}},
+			err:    "unknown split method",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splits, err := ComputeSplits(tt.total, tt.method, tt.shares)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("err = %v, want one mentioning %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var sum int64
+			got := make([]int64, len(splits))
+			for i, s := range splits {
+				if s.UserID != tt.shares[i].UserID || s.Amount.Currency != tt.total.Currency {
+					t.Errorf("split %d = %+v, want user %d in %s", i, s, tt.shares[i].UserID, tt.total.Currency)
+				}
+				got[i] = s.Amount.Minor
+				sum += s.Amount.Minor
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("shares = %v, want %v", got, tt.want)
+			}
+			if sum != tt.total.Minor {
+				t.Errorf("shares add up to %d, want %d", sum, tt.total.Minor)
+			}
+		})
+	}
+}
+
+func TestRescaleSplits(t *testing.T) {
+	splits := []ExpenseSplit{
+		{ExpenseID: 9, UserID: 1, Amount: NewMoney(5000, "BRL")},
+		{ExpenseID: 9, UserID: 2, Amount: NewMoney(3000, "BRL")},
+		{ExpenseID: 9, UserID: 3, Amount: NewMoney(2000, "BRL")},
+	}
+	rescaled := RescaleSplits(splits, NewMoney(999, "BRL"))
+	want := []int64{499, 300, 200}
+	for i, s := range rescaled {
+		if s.ExpenseID != 9 || s.UserID != splits[i].UserID || s.Amount.Minor != want[i] {
+			t.Errorf("rescaled %d = %+v, want user %d with %d", i, s, splits[i].UserID, want[i])
+		}
+	}
+}
+
+func TestSettleBalances(t *testing.T) {
+	tests := []struct {
+		name     string
+		balances map[int]int64
+		want     []Transfer
+	}{
+		{
+			name:     "settled",
+			balances: map[int]int64{1: 0, 2: 0},
+			want:     []Transfer{},
+		},
+		{
+			name:     "two parties",
+			balances: map[int]int64{1: 500, 2: -500},
+			want:     []Transfer{{FromUserID: 2, ToUserID: 1, Amount: NewMoney(500, "BRL")}},
+		},
+		{
+			name:     "one creditor, two debtors",
+			balances: map[int]int64{1: 667, 2: -334, 3: -333},
+			want: []Transfer{
+				{FromUserID: 2, ToUserID: 1, Amount: NewMoney(334, "BRL")},
+				{FromUserID: 3, ToUserID: 1, Amount: NewMoney(333, "BRL")},
+			},
+		},
+		{
+			name:     "two creditors, one debtor",
+			balances: map[int]int64{1: 300, 2: 700, 3: -1000},
+			want: []Transfer{
+				{FromUserID: 3, ToUserID: 2, Amount: NewMoney(700, "BRL")},
+				{FromUserID: 3, ToUserID: 1, Amount: NewMoney(300, "BRL")},
+			},
+		},
+		{
+			name:     "four parties",
+			balances: map[int]int64{1: 1000, 2: 200, 3: -600, 4: -600},
+			want: []Transfer{
+				{FromUserID: 3, ToUserID: 1, Amount: NewMoney(600, "BRL")},
+				{FromUserID: 4, ToUserID: 1, Amount: NewMoney(400, "BRL")},
+				{FromUserID: 4, ToUserID: 2, Amount: NewMoney(200, "BRL")},
+			},
+		},
+		{
+			name:     "five parties with a settled member",
+			balances: map[int]int64{1: 450, 2: 450, 3: 0, 4: -300, 5: -600},
+			want: []Transfer{
+				{FromUserID: 5, ToUserID: 1, Amount: NewMoney(450, "BRL")},
+				{FromUserID: 4, ToUserID: 2, Amount: NewMoney(300, "BRL")},
+				{FromUserID: 5, ToUserID: 2, Amount: NewMoney(150, "BRL")},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SettleBalances(tt.balances, "BRL")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("transfers = %+v, want %+v", got, tt.want)
+			}
+
+			nonZero := 0
+			for _, amount := range tt.balances {
+				if amount != 0 {
+					nonZero++
+				}
+			}
+			if nonZero > 0 && len(got) > nonZero-1 {
+				t.Errorf("%d transfers settle %d balances, want at most %d", len(got), nonZero, nonZero-1)
+			}
+			left := make(map[int]int64, len(tt.balances))
+			for userID, amount := range tt.balances {
+				left[userID] = amount
+			}
+			for _, transfer := range got {
+				left[transfer.FromUserID] += transfer.Amount.Minor
+				left[transfer.ToUserID] -= transfer.Amount.Minor
+			}
+			for userID, amount := range left {
+				if amount != 0 {
+					t.Errorf("user %d is left with %d", userID, amount)
+				}
+			}
+		})
+	}
+}
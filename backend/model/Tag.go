@@ -0,0 +1,70 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"github.com/uptrace/bun"
+	"strings"
+	"time"
+)
+
+// MaxTagLength is the longest tag name accepted, in characters.
+const MaxTagLength = 50
+
+// Tag is a free-form label shared by the members of a plan. Names are unique
+// within a plan regardless of case.
+type Tag struct {
+	bun.BaseModel `bun:"table:tags"`
+
+	ID        int       `bun:",pk,autoincrement" json:"id"`
+	PlanID    int       `json:"plan_id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `bun:",nullzero,default:current_timestamp" json:"created_at"`
+	// Usage is the number of expenses carrying the tag, filled when listing.
+	Usage int `bun:",scanonly" json:"usage"`
+}
+
+// ExpenseTag links an Expense to one of its tags.
+type ExpenseTag struct {
+	bun.BaseModel `bun:"table:expense_tags"`
+
+	ExpenseID int      `bun:"expense_id,pk"`
+	Expense   *Expense `bun:"rel:belongs-to,join:expense_id=id"`
+
+	TagID int  `bun:"tag_id,pk"`
+	Tag   *Tag `bun:"rel:belongs-to,join:tag_id=id"`
+}
+
+// TagFilter restricts expenses to those carrying any, or with All every, tag
+// in Tags. An empty filter matches every expense.
+type TagFilter struct {
+	Tags []string
+	All  bool
+}
+
+func (f TagFilter) IsZero() bool {
+	return len(f.Tags) == 0
+}
+
+// NormalizeTags trims names and drops duplicates, ignoring case, keeping the
+// first spelling of each.
+func NormalizeTags(names []string) ([]string, error) {
+	seen := make(map[string]bool, len(names))
+	tags := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, errors.New("tag names must not be empty")
+		}
+		if len([]rune(name)) > MaxTagLength {
+			return nil, fmt.Errorf("tag %q is longer than %d characters", name, MaxTagLength)
+		}
+		key := strings.ToLower(name)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		tags = append(tags, name)
+	}
+	return tags, nil
+}
@@ -13,4 +13,6 @@ type User struct {
 	Email       string    `json:"email"`
 	Password    string    `json:"-"`
 	CreatedDate time.Time `bun:"default:current_timestamp" json:"created_date"`
+	// TokenVersion is embedded in access tokens; bumping it invalidates all of them.
+	TokenVersion int `bun:",notnull,default:0" json:"-"`
 }
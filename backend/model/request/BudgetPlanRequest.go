@@ -1,12 +1,45 @@
 package request
 
-import "time"
+import (
+	"backend/model"
+	"time"
+)
 
+// BudgetPlanRequest creates a plan. Period is one_off (default), weekly,
+// monthly or custom, the latter lasting PeriodDays. StartDate defaults to now.
 type BudgetPlanRequest struct {
-	Name        string    `json:"name"`
-	TotalAmount float64   `json:"totalAmount"`
-	Description string    `json:"description"`
-	CreatedDate time.Time `json:"startDate"`
-	UserID      int       `json:"userID"`
-	Expenses    []int     `json:"expenses"`
+	Name         string      `json:"name" validate:"required,max=100"`
+	TotalAmount  model.Money `json:"totalAmount" validate:"gte=0"`
+	Description  string      `json:"description" validate:"max=500"`
+	CreatedDate  time.Time   `json:"startDate"`
+	UserID       int         `json:"userID"`
+	Expenses     []int       `json:"expenses"`
+	BaseCurrency string      `json:"baseCurrency" validate:"currency"`
+	Period       string      `json:"period" validate:"oneof=one_off weekly monthly custom"`
+	PeriodDays   int         `json:"periodDays" validate:"gte=0"`
+	EndDate      *time.Time  `json:"endDate"`
+	Rollover     bool        `json:"rollover"`
+}
+
+// BudgetPlanPatchRequest edits some fields of a plan. Fields left out, or
+// null, keep their current value.
+type BudgetPlanPatchRequest struct {
+	Name        *string    `json:"name" validate:"min=1,max=100"`
+	Description *string    `json:"description" validate:"max=500"`
+	Period      *string    `json:"period" validate:"oneof=one_off weekly monthly custom"`
+	PeriodDays  *int       `json:"periodDays" validate:"gte=0"`
+	EndDate     *time.Time `json:"endDate"`
+	Rollover    *bool      `json:"rollover"`
+}
+
+// BudgetPlanUpdateRequest edits the plan ID. An empty Period keeps the
+// current period settings; the total is changed through PlanAmountRequest.
+type BudgetPlanUpdateRequest struct {
+	ID          int        `json:"id" validate:"required"`
+	Name        string     `json:"name" validate:"required,max=100"`
+	Description string     `json:"description" validate:"max=500"`
+	Period      string     `json:"period" validate:"oneof=one_off weekly monthly custom"`
+	PeriodDays  int        `json:"periodDays" validate:"gte=0"`
+	EndDate     *time.Time `json:"endDate"`
+	Rollover    bool       `json:"rollover"`
 }
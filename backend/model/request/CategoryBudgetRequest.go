@@ -0,0 +1,12 @@
+package request
+
+import "backend/model"
+
+// CategoryBudgetRequest allocates part of a plan to a category.
+// Currency defaults to the plan's base currency and must match it when given.
+type CategoryBudgetRequest struct {
+	PlanID     int         `json:"plan_id" validate:"required"`
+	CategoryID int         `json:"category_id" validate:"required"`
+	Amount     model.Money `json:"amount" validate:"gte=0"`
+	Currency   string      `json:"currency" validate:"currency"`
+}
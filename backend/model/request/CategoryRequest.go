@@ -1,5 +1,14 @@
 package request
 
 type CategoryRequest struct {
-	Name string `json:"name"`
+	Name     string `json:"name" validate:"required,max=50"`
+	ParentID *int   `json:"parent_id" validate:"gt=0"`
+}
+
+// CategoryPatchRequest renames or moves a category. Fields left out, or
+// null, keep their current value; a ParentID of 0 makes it a top-level
+// category.
+type CategoryPatchRequest struct {
+	Name     *string `json:"name" validate:"min=1,max=50"`
+	ParentID *int    `json:"parent_id" validate:"gte=0"`
 }
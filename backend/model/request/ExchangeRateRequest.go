@@ -0,0 +1,11 @@
+package request
+
+import "time"
+
+// ExchangeRateRequest stores the price of one unit of Base in Quote on Date.
+type ExchangeRateRequest struct {
+	Base  string    `json:"base" validate:"required,currency"`
+	Quote string    `json:"quote" validate:"required,currency"`
+	Rate  float64   `json:"rate" validate:"gt=0"`
+	Date  time.Time `json:"date"`
+}
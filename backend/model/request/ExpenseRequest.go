@@ -1,13 +1,52 @@
 package request
 
-import "time"
+import (
+	"backend/model"
+	"time"
+)
 
 type ExpenseRequest struct {
-	Amount       float64   `json:"amount"`
-	Description  string    `json:"description"`
-	CategoryID   int       `json:"category_id"`
-	CategoryName string    `json:"category_name"`
-	Date         time.Time `json:"date"`
-	IsRecurring  bool      `json:"is_recurring"`
-	BudgetID     int       `json:"budget_id"`
+	Amount       model.Money `json:"amount" validate:"gt=0"`
+	Description  string      `json:"description" validate:"max=255"`
+	CategoryID   int         `json:"category_id" validate:"gte=0"`
+	CategoryName string      `json:"category_name" validate:"max=50"`
+	Date         time.Time   `json:"date"`
+	IsRecurring  bool        `json:"is_recurring"`
+	BudgetID     int         `json:"budget_id" validate:"required"`
+	Currency     string      `json:"currency" validate:"currency"`
+	PaidBy       *int        `json:"paid_by" validate:"gt=0"`
+	Tags         []string    `json:"tags" validate:"max=20"`
+}
+
+// DeleteExpenseRequest deletes the expense ID of the plan PlanID.
+type DeleteExpenseRequest struct {
+	ID     int `json:"id" validate:"required"`
+	PlanID int `json:"plan_id" validate:"required"`
+}
+
+// ExpensePatchRequest edits some fields of an expense. Fields left out, or
+// null, keep their current value; the currency of an expense cannot change.
+type ExpensePatchRequest struct {
+	Amount      *model.Money `json:"amount" validate:"gt=0"`
+	Description *string      `json:"description" validate:"max=255"`
+	CategoryID  *int         `json:"category_id" validate:"gt=0"`
+	Date        *time.Time   `json:"date"`
+	IsRecurring *bool        `json:"is_recurring"`
+	PaidBy      *int         `json:"paid_by" validate:"gt=0"`
+	Tags        *[]string    `json:"tags" validate:"max=20"`
+}
+
+// ExpenseUpdateRequest edits the expense ID, keeping its plan and currency.
+// A CategoryID or PaidBy of 0 keeps the current value; nil Tags keep the
+// current tags and an empty list removes them.
+type ExpenseUpdateRequest struct {
+	ID           int         `json:"id" validate:"required"`
+	Amount       model.Money `json:"amount" validate:"gt=0"`
+	Description  string      `json:"description" validate:"max=255"`
+	CategoryID   int         `json:"category_id" validate:"gte=0"`
+	CategoryName string      `json:"category_name" validate:"max=50"`
+	Date         time.Time   `json:"date"`
+	IsRecurring  bool        `json:"is_recurring"`
+	PaidBy       *int        `json:"paid_by" validate:"gt=0"`
+	Tags         []string    `json:"tags" validate:"max=20"`
 }
@@ -0,0 +1,12 @@
+package request
+
+import "backend/model"
+
+// ImportCommitRequest carries the rows of a preview, possibly edited by the
+// user, to be inserted into a plan.
+type ImportCommitRequest struct {
+	PlanID            int               `json:"plan_id" validate:"required"`
+	Rows              []model.ImportRow `json:"rows" validate:"required,max=5000"`
+	DefaultCategoryID int               `json:"default_category_id" validate:"gte=0"`
+	AllowDuplicates   bool              `json:"allow_duplicates"`
+}
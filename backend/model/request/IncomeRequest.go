@@ -0,0 +1,27 @@
+package request
+
+import (
+	"backend/model"
+	"time"
+)
+
+// IncomeRequest adds an income to the plan BudgetID. Currency defaults to
+// the plan's base currency.
+type IncomeRequest struct {
+	BudgetID    int         `json:"budget_id" validate:"required"`
+	Description string      `json:"description" validate:"max=255"`
+	Source      string      `json:"source" validate:"max=100"`
+	Amount      model.Money `json:"amount" validate:"gt=0"`
+	Currency    string      `json:"currency" validate:"currency"`
+	Date        time.Time   `json:"date"`
+}
+
+// IncomeUpdateRequest edits the income ID. Its plan cannot change.
+type IncomeUpdateRequest struct {
+	ID          int         `json:"id" validate:"required"`
+	Description string      `json:"description" validate:"max=255"`
+	Source      string      `json:"source" validate:"max=100"`
+	Amount      model.Money `json:"amount" validate:"gt=0"`
+	Currency    string      `json:"currency" validate:"currency"`
+	Date        time.Time   `json:"date"`
+}
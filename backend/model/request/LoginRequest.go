@@ -1,6 +1,8 @@
 package request
 
 type LoginRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email    string `json:"email" validate:"required"`
+	Password string `json:"password" validate:"required"`
+	// UserAgent is filled from the request headers to label the session.
+	UserAgent string `json:"-"`
 }
@@ -0,0 +1,16 @@
+package request
+
+// InvitationRequest invites the registered user with Email to a plan as an
+// editor or a viewer.
+type InvitationRequest struct {
+	PlanID int    `json:"plan_id" validate:"required"`
+	Email  string `json:"email" validate:"required,email"`
+	Role   string `json:"role" validate:"required"`
+}
+
+// MemberRoleRequest changes the role of a member of a plan.
+type MemberRoleRequest struct {
+	PlanID int    `json:"plan_id" validate:"required"`
+	UserID int    `json:"user_id" validate:"required"`
+	Role   string `json:"role" validate:"required"`
+}
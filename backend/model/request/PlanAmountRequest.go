@@ -0,0 +1,18 @@
+package request
+
+import (
+	"backend/model"
+	"time"
+)
+
+// PlanAmountRequest adds to or subtracts from the total of a BudgetPlan.
+// Currency defaults to the plan's base currency and Date, the day of the
+// exchange rate, to the current day. Reason is kept in the plan history.
+type PlanAmountRequest struct {
+	ID       int         `json:"id" validate:"required"`
+	Amount   model.Money `json:"amount" validate:"gt=0"`
+	Add      bool        `json:"add"`
+	Currency string      `json:"currency" validate:"currency"`
+	Date     time.Time   `json:"date"`
+	Reason   string      `json:"reason" validate:"max=255"`
+}
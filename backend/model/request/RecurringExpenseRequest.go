@@ -0,0 +1,25 @@
+package request
+
+import (
+	"backend/model"
+	"time"
+)
+
+// RecurringExpenseRequest describes a recurring expense either with the
+// frequency fields or with an RRULE, which takes precedence when present.
+type RecurringExpenseRequest struct {
+	BudgetID     int         `json:"budget_id" validate:"required"`
+	CategoryID   int         `json:"category_id" validate:"gte=0"`
+	CategoryName string      `json:"category_name" validate:"max=50"`
+	Description  string      `json:"description" validate:"max=255"`
+	Amount       model.Money `json:"amount" validate:"gt=0"`
+	Currency     string      `json:"currency" validate:"currency"`
+	Frequency    string      `json:"frequency"`
+	Interval     int         `json:"interval" validate:"gte=0"`
+	ByDay        string      `json:"by_day"`
+	ByMonthDay   int         `json:"by_month_day" validate:"min=1,max=31"`
+	Count        int         `json:"count" validate:"gte=0"`
+	StartDate    time.Time   `json:"start_date"`
+	EndDate      *time.Time  `json:"end_date"`
+	RRule        string      `json:"rrule" validate:"max=500"`
+}
@@ -0,0 +1,6 @@
+package request
+
+// RuleRunRequest runs the rules of the caller over the expenses of a plan.
+type RuleRunRequest struct {
+	PlanID int `json:"plan_id" validate:"required"`
+}
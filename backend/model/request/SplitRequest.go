@@ -0,0 +1,25 @@
+package request
+
+import (
+	"backend/model"
+	"time"
+)
+
+// SplitRequest shares an expense between plan members. An equal split
+// without shares is divided between every member of the plan.
+type SplitRequest struct {
+	ExpenseID int                `json:"expense_id" validate:"required"`
+	Method    model.SplitMethod  `json:"method" validate:"required,oneof=equal percentage exact"`
+	Shares    []model.SplitShare `json:"shares"`
+}
+
+// SettlementPaymentRequest records that FromUserID paid ToUserID to settle up.
+type SettlementPaymentRequest struct {
+	PlanID     int         `json:"plan_id" validate:"required"`
+	FromUserID int         `json:"from_user_id" validate:"required"`
+	ToUserID   int         `json:"to_user_id" validate:"required"`
+	Amount     model.Money `json:"amount" validate:"gt=0"`
+	Currency   string      `json:"currency" validate:"currency"`
+	Date       time.Time   `json:"date"`
+	Note       string      `json:"note" validate:"max=255"`
+}
@@ -0,0 +1,16 @@
+package request
+
+// TagRequest renames the tag ID of a plan.
+type TagRequest struct {
+	ID     int    `json:"id" validate:"required"`
+	PlanID int    `json:"plan_id" validate:"required"`
+	Name   string `json:"name" validate:"required,max=50"`
+}
+
+// MergeTagsRequest moves the expenses of the SourceIDs tags to TargetID and
+// deletes the sources.
+type MergeTagsRequest struct {
+	PlanID    int   `json:"plan_id" validate:"required"`
+	SourceIDs []int `json:"source_ids" validate:"required"`
+	TargetID  int   `json:"target_id" validate:"required"`
+}
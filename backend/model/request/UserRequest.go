@@ -1,7 +1,18 @@
 package request
 
 type UserRequest struct {
-	Name     string `json:"name"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Name     string `json:"name" validate:"required,max=100"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,max=72"`
+}
+
+// PasswordRequest changes the password of the caller.
+type PasswordRequest struct {
+	NewPassword string `json:"new_password" validate:"required"`
+}
+
+// UserUpdateRequest changes the name and email of the caller.
+type UserUpdateRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	Name  string `json:"name" validate:"required,max=100"`
 }
@@ -0,0 +1,178 @@
+package request
+
+import (
+	"backend/model"
+	"fmt"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// FieldError is a field of a request that breaks one of its rules. Field is
+// the JSON name of the field and Rule the name of the broken rule, so that
+// clients can show their own message next to the field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists every field of a request that breaks its rules.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		parts[i] = f.Field + " " + f.Message
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Validate checks the fields of the struct v points to against the rules of
+// their validate tags, a comma separated list of:
+//
+//	required     the field is not empty, zero nor null
+//	min=n, max=n a string has at least or at most n characters, a slice n
+//	             items and an integer is at least or at most n
+//	gt=n, gte=n  a number or an amount is greater than, or at least, n
+//	oneof=a b c  a string is one of the space separated values
+//	email        a string is an email address
+//	currency     a string is an ISO-4217 currency code
+//
+// Fields left out of the request are only checked by required and, for
+// values that are not null, gt, so optional fields are checked when given.
+// It returns a *ValidationError listing every broken rule.
+func Validate(v interface{}) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	var fields []FieldError
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		tag := rt.Field(i).Tag.Get("validate")
+		if tag == "" {
+			continue
+		}
+		name := fieldName(rt.Field(i))
+		for _, rule := range strings.Split(tag, ",") {
+			rule, arg, _ := strings.Cut(rule, "=")
+			if message, ok := check(rv.Field(i), rule, arg); !ok {
+				fields = append(fields, FieldError{Field: name, Rule: rule, Message: message})
+				break
+			}
+		}
+	}
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// fieldName returns the name of f in JSON.
+func fieldName(f reflect.StructField) string {
+	if name, _, _ := strings.Cut(f.Tag.Get("json"), ","); name != "" && name != "-" {
+		return name
+	}
+	return f.Name
+}
+
+// check reports whether the value of a field follows rule, and the message
+// describing the rule when it does not.
+func check(v reflect.Value, rule, arg string) (string, bool) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "is required", rule != "required"
+		}
+		v = v.Elem()
+	} else if rule != "required" && rule != "gt" && empty(v) {
+		return "", true
+	}
+	switch rule {
+	case "required":
+		return "is required", !empty(v)
+	case "min", "max":
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			panic(fmt.Sprintf("request: invalid %s rule %q", rule, arg))
+		}
+		size, unit := measure(v)
+		if rule == "min" {
+			return fmt.Sprintf("must be at least %d%s", n, unit), size >= n
+		}
+		return fmt.Sprintf("must be at most %d%s", n, unit), size <= n
+	case "gt", "gte":
+		n, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			panic(fmt.Sprintf("request: invalid %s rule %q", rule, arg))
+		}
+		value := number(v)
+		if rule == "gt" {
+			return "must be greater than " + arg, value > n
+		}
+		return "must be greater than or equal to " + arg, value >= n
+	case "oneof":
+		values := strings.Fields(arg)
+		for _, value := range values {
+			if v.String() == value {
+				return "", true
+			}
+		}
+		return "must be one of " + strings.Join(values, ", "), false
+	case "email":
+		address, err := mail.ParseAddress(v.String())
+		return "must be a valid email address", err == nil && address.Address == strings.TrimSpace(v.String())
+	case "currency":
+		code := strings.TrimSpace(v.String())
+		valid := len(code) == 3
+		for _, c := range code {
+			valid = valid && (c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z')
+		}
+		return "must be a 3-letter ISO-4217 currency code", valid
+	}
+	panic(fmt.Sprintf("request: unknown validation rule %q", rule))
+}
+
+// empty reports whether v holds nothing: a blank string, an empty slice or a
+// zero value.
+func empty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(v.String()) == ""
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+// measure returns the length of a string or slice, with the unit the min and
+// max rules count it in, or the value of an integer.
+func measure(v reflect.Value) (int, string) {
+	switch v.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(v.String()), " characters"
+	case reflect.Slice, reflect.Map:
+		return v.Len(), " items"
+	}
+	return int(number(v)), ""
+}
+
+// number returns the value of an integer, a float or an amount in major units.
+func number(v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	}
+	if m, ok := v.Interface().(model.Money); ok {
+		return m.Float64()
+	}
+	panic(fmt.Sprintf("request: %s is not a number", v.Type()))
+}
@@ -0,0 +1,10 @@
+package response
+
+// TokenResponse is returned by login and refresh. Token is the short-lived
+// access token; RefreshToken can be exchanged once for a new pair.
+type TokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+}
@@ -0,0 +1,271 @@
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// clientHeader opens the module written by Client. The operations call the
+// API through the axios instance of the frontend, which adds the tokens of
+// the session.
+const clientHeader = `// Code generated by backend/cmd/genclient from the OpenAPI document. DO NOT EDIT.
+// Regenerate it with npm run generate:client after changing the API.
+
+import api from './API.jsx';
+`
+
+// methods orders the operations of a path in the client.
+var methods = []string{"get", "post", "put", "patch", "delete"}
+
+// identifier matches the names that need no quotes in a JavaScript type.
+var identifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// operation is an operation of the document with the path and method it is
+// served at.
+type operation struct {
+	*Operation
+	Path   string
+	Method string
+}
+
+// Client returns a JavaScript module with a function for every operation of
+// doc, named after its operationId. The schemas of doc become JSDoc
+// typedefs, so that editors check the payloads of the calls. Each function
+// takes the path parameters, then the query parameters as an object, then
+// the body, and last an axios config, and resolves to the axios response.
+func Client(doc *Document) []byte {
+	var b bytes.Buffer
+	b.WriteString(clientHeader)
+	for _, name := range slices.Sorted(maps.Keys(doc.Components.Schemas)) {
+		writeTypedef(&b, name, doc.Components.Schemas[name])
+	}
+	for _, op := range operations(doc) {
+		writeOperation(&b, op)
+	}
+	return b.Bytes()
+}
+
+// operations lists the operations of doc by tag, in the order of the tags
+// of doc, then by path and method.
+func operations(doc *Document) []operation {
+	tagOrder := map[string]int{}
+	for i, t := range doc.Tags {
+		tagOrder[t.Name] = i
+	}
+	methodOrder := map[string]int{}
+	for i, m := range methods {
+		methodOrder[m] = i
+	}
+	var ops []operation
+	for path, item := range doc.Paths {
+		for method, op := range item {
+			ops = append(ops, operation{Operation: op, Path: path, Method: method})
+		}
+	}
+	tag := func(op operation) int {
+		if len(op.Tags) == 0 {
+			return len(doc.Tags)
+		}
+		return tagOrder[op.Tags[0]]
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		a, b := ops[i], ops[j]
+		if tag(a) != tag(b) {
+			return tag(a) < tag(b)
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return methodOrder[a.Method] < methodOrder[b.Method]
+	})
+	return ops
+}
+
+func writeTypedef(b *bytes.Buffer, name string, s *Schema) {
+	b.WriteString("\n/**\n")
+	if s.Description != "" {
+		fmt.Fprintf(b, " * %s\n *\n", s.Description)
+	}
+	if len(s.Properties) == 0 {
+		fmt.Fprintf(b, " * @typedef {%s} %s\n */\n", jsType(s), name)
+		return
+	}
+	fmt.Fprintf(b, " * @typedef {Object} %s\n", name)
+	for _, prop := range slices.Sorted(maps.Keys(s.Properties)) {
+		fmt.Fprintf(b, " * @property {%s} %s\n", jsType(s.Properties[prop]), optional(prop, slices.Contains(s.Required, prop)))
+	}
+	b.WriteString(" */\n")
+}
+
+func writeOperation(b *bytes.Buffer, op operation) {
+	var path, query, headers []Parameter
+	for _, p := range op.Parameters {
+		switch p.In {
+		case "path":
+			path = append(path, p)
+		case "query":
+			query = append(query, p)
+		case "header":
+			headers = append(headers, p)
+		}
+	}
+
+	b.WriteString("\n/**\n")
+	if op.Summary != "" {
+		fmt.Fprintf(b, " * %s.\n *\n", op.Summary)
+	}
+	var args []string
+	for _, p := range path {
+		fmt.Fprintf(b, " * @param {%s} %s%s\n", jsType(p.Schema), p.Name, describe(p.Description))
+		args = append(args, p.Name)
+	}
+	if len(query) > 0 {
+		required := false
+		for _, p := range query {
+			required = required || p.Required
+		}
+		fmt.Fprintf(b, " * @param {Object} %s\n", optional("params", required))
+		for _, p := range query {
+			fmt.Fprintf(b, " * @param {%s} %s%s\n", jsType(p.Schema), optional("params."+p.Name, p.Required), describe(p.Description))
+		}
+		args = append(args, "params")
+	}
+	if op.RequestBody != nil {
+		fmt.Fprintf(b, " * @param {%s} body\n", bodyType(op.RequestBody.Content))
+		args = append(args, "body")
+	}
+	config := " * @param {import('axios').AxiosRequestConfig} [config]"
+	if len(headers) > 0 {
+		names := make([]string, len(headers))
+		for i, h := range headers {
+			names[i] = h.Name
+		}
+		config += " - Sends the " + strings.Join(names, " and ") + " headers."
+	}
+	b.WriteString(config + "\n")
+	fmt.Fprintf(b, " * @returns {Promise<import('axios').AxiosResponse<%s>>}\n", responseType(op.Responses))
+	b.WriteString(" */\n")
+
+	request := []string{"...config", "method: '" + op.Method + "'", "url: " + urlOf(op.Path)}
+	if len(query) > 0 {
+		request = append(request, "params")
+	}
+	if op.RequestBody != nil {
+		request = append(request, "data: body")
+	}
+	fmt.Fprintf(b, "export const %s = (%s) =>\n", op.OperationID, strings.Join(append(args, "config = {}"), ", "))
+	fmt.Fprintf(b, "    api.request({ %s });\n", strings.Join(request, ", "))
+}
+
+// urlOf writes path as a JavaScript string, filling in its parameters.
+func urlOf(path string) string {
+	if !strings.Contains(path, "{") {
+		return "'" + path + "'"
+	}
+	return "`" + variablePattern.ReplaceAllString(path, "$${$1}") + "`"
+}
+
+func bodyType(content map[string]*MediaType) string {
+	if media, ok := content["application/json"]; ok {
+		return jsType(media.Schema)
+	}
+	if _, ok := content["multipart/form-data"]; ok {
+		return "FormData"
+	}
+	return "*"
+}
+
+// responseType is the type of the body written by the operation on success.
+func responseType(responses map[string]*Response) string {
+	for status, r := range responses {
+		if status == "default" {
+			continue
+		}
+		if media, ok := r.Content["application/json"]; ok {
+			return jsType(media.Schema)
+		}
+		if _, ok := r.Content["text/plain"]; ok {
+			return "string"
+		}
+		if len(r.Content) > 0 {
+			return "Blob"
+		}
+	}
+	return "void"
+}
+
+// jsType writes s as a type of JSDoc.
+func jsType(s *Schema) string {
+	var t string
+	switch {
+	case s.Ref != "":
+		t = strings.TrimPrefix(s.Ref, "#/components/schemas/")
+	case len(s.OneOf) > 0:
+		types := make([]string, len(s.OneOf))
+		for i, o := range s.OneOf {
+			types[i] = jsType(o)
+		}
+		t = "(" + strings.Join(types, "|") + ")"
+	case len(s.Enum) > 0:
+		values := make([]string, len(s.Enum))
+		for i, v := range s.Enum {
+			values[i] = strconv.Quote(v)
+		}
+		t = "(" + strings.Join(values, "|") + ")"
+	case s.Type == "array":
+		t = "Array<" + jsType(s.Items) + ">"
+	case s.Type == "object" && s.AdditionalProperties != nil:
+		t = "Object<string, " + jsType(s.AdditionalProperties) + ">"
+	case s.Type == "object" && len(s.Properties) > 0:
+		props := make([]string, 0, len(s.Properties))
+		for _, name := range slices.Sorted(maps.Keys(s.Properties)) {
+			key := name
+			if !identifier.MatchString(key) {
+				key = strconv.Quote(key)
+			}
+			if !slices.Contains(s.Required, name) {
+				key += "?"
+			}
+			props = append(props, key+": "+jsType(s.Properties[name]))
+		}
+		t = "{" + strings.Join(props, ", ") + "}"
+	case s.Type == "object":
+		t = "Object"
+	case s.Type == "string" && s.Format == "binary":
+		t = "Blob"
+	case s.Type == "string":
+		t = "string"
+	case s.Type == "integer", s.Type == "number":
+		t = "number"
+	case s.Type == "boolean":
+		t = "boolean"
+	default:
+		return "*"
+	}
+	if s.Nullable {
+		return "(" + t + "|null)"
+	}
+	return t
+}
+
+// optional writes name as an optional parameter or property of JSDoc unless
+// it is required.
+func optional(name string, required bool) string {
+	if required {
+		return name
+	}
+	return "[" + name + "]"
+}
+
+func describe(description string) string {
+	if description == "" {
+		return ""
+	}
+	return " - " + description
+}
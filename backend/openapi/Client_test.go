@@ -0,0 +1,40 @@
+package openapi_test
+
+import (
+	"backend/openapi"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+)
+
+// client is the generated client checked in with the frontend.
+const client = "../../frontend/src/services/client.js"
+
+// TestClientIsGenerated fails when the checked-in client was not
+// regenerated after a change to the document.
+func TestClientIsGenerated(t *testing.T) {
+	checkedIn, err := os.ReadFile(client)
+	if errors.Is(err, os.ErrNotExist) {
+		t.Skip("the frontend is not part of this checkout")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(checkedIn, openapi.Client(openapi.Spec())) {
+		t.Fatalf("%s is out of date, run npm run generate:client in frontend", client)
+	}
+}
+
+// TestClientFromServedDocument checks that the document served at
+// /openapi.json generates the same client as the built-in one.
+func TestClientFromServedDocument(t *testing.T) {
+	doc := &openapi.Document{}
+	if err := json.Unmarshal(openapi.JSON(), doc); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(openapi.Client(doc), openapi.Client(openapi.Spec())) {
+		t.Fatal("the served document generates a different client")
+	}
+}
@@ -0,0 +1,397 @@
+package openapi
+
+import (
+	"backend/model"
+	"backend/model/request"
+	"backend/model/response"
+	"net/http"
+)
+
+// endpoint describes an operation of the API. Body and Response are values
+// of the types read from and written to JSON bodies, or a content for other
+// media types; a nil Response means the operation writes no body.
+type endpoint struct {
+	Method   string
+	Path     string
+	ID       string
+	Tag      string
+	Summary  string
+	Public   bool
+	Params   []Parameter
+	Body     interface{}
+	Status   int
+	Response interface{}
+}
+
+// content is a body given by its media types rather than by a Go type.
+type content map[string]*Schema
+
+// list is the response of a list that is written as a plain array unless it
+// is paginated with the limit or cursor parameters.
+type list struct {
+	item interface{}
+	page interface{}
+}
+
+// message is the body of the operations that answer with a confirmation.
+type message struct {
+	Message string `json:"message"`
+}
+
+// imported is the body written by the import of exchange rates.
+type imported struct {
+	Imported int `json:"imported"`
+}
+
+func param(name, typ, description string) Parameter {
+	s := &Schema{Type: typ}
+	if typ == "date" {
+		s = &Schema{Type: "string", Format: "date"}
+	}
+	return Parameter{Name: name, In: "query", Description: description, Schema: s}
+}
+
+// pathParam is a path parameter holding the ID of a resource.
+func pathParam(name, description string) Parameter {
+	return Parameter{Name: name, In: "path", Description: description, Required: true, Schema: &Schema{Type: "integer"}}
+}
+
+func header(name, description string) Parameter {
+	return Parameter{Name: name, In: "header", Description: description, Schema: &Schema{Type: "string"}}
+}
+
+func required(name, typ, description string) Parameter {
+	p := param(name, typ, description)
+	p.Required = true
+	return p
+}
+
+func params(groups ...[]Parameter) []Parameter {
+	var all []Parameter
+	for _, g := range groups {
+		all = append(all, g...)
+	}
+	return all
+}
+
+func multipart(fields map[string]*Schema, required ...string) content {
+	return content{"multipart/form-data": {Type: "object", Properties: fields, Required: required}}
+}
+
+var binary = &Schema{Type: "string", Format: "binary"}
+
+var (
+	listParams = []Parameter{
+		param("limit", "integer", "Page size, 50 by default and at most 200. Without limit nor cursor the first page is written as a plain array, with the cursor of the next one in the X-Next-Cursor header."),
+		param("sort", "string", "Field to sort by, prefixed with - for descending order."),
+		param("cursor", "string", "next_cursor of the previous page."),
+	}
+	reportParams = []Parameter{
+		required("plan", "integer", "ID of the plan."),
+		param("period_id", "integer", "Budget period to report on, instead of from and to."),
+		param("from", "date", "First day, inclusive."),
+		param("to", "date", "Last day, inclusive."),
+		param("recurring", "boolean", "Only recurring or only one-off expenses."),
+	}
+	categoryParams = []Parameter{
+		param("tree", "boolean", "Nest subcategories under their parents instead of listing them."),
+		param("parent", "integer", "Only the subcategories of this category."),
+		param("name", "string", "Only categories whose name contains this text."),
+	}
+	expenseParams = []Parameter{
+		param("period_id", "integer", "Only the expenses of this budget period."),
+		param("category", "integer", "Only the expenses of this category."),
+		param("paid_by", "integer", "Only the expenses paid by this member."),
+		param("recurring", "boolean", "Only recurring or only one-off expenses."),
+		param("from", "date", "First day, inclusive."),
+		param("to", "date", "Last day, inclusive."),
+		param("min_amount", "number", "Smallest amount, inclusive."),
+		param("max_amount", "number", "Largest amount, inclusive."),
+		param("tags", "string", "Comma separated tag names."),
+		param("match", "string", "any (default) or all of the tags."),
+	}
+	planParams = []Parameter{
+		param("name", "string", "Only plans whose name contains this text."),
+		param("role", "string", "Only plans where the caller is owner, editor or viewer."),
+		param("period", "string", "Only plans with this period."),
+	}
+	id       = []Parameter{required("id", "integer", "ID of the resource.")}
+	plan     = []Parameter{required("plan", "integer", "ID of the plan.")}
+	planByID = []Parameter{required("id", "integer", "ID of the plan.")}
+	expense  = []Parameter{required("expense", "integer", "ID of the expense.")}
+
+	planPath     = []Parameter{pathParam("id", "ID of the plan.")}
+	expensePath  = []Parameter{pathParam("id", "ID of the plan."), pathParam("expenseId", "ID of the expense.")}
+	categoryPath = []Parameter{pathParam("id", "ID of the category.")}
+	ifMatch      = header("If-Match", "ETag of the resource as last read; the request fails with 412 when the resource changed since.")
+	ifNoneMatch  = header("If-None-Match", "ETag of the copy held by the client; answered with 304 when it is still current.")
+)
+
+// endpoints lists every route of routes.SetupRoutes. Check fails when they
+// drift apart.
+var endpoints = []endpoint{
+	{Method: http.MethodPost, Path: "/users", ID: "createUser", Tag: "users", Summary: "Register a user", Public: true,
+		Body: request.UserRequest{}, Status: http.StatusCreated, Response: response.UserResponse{}},
+	{Method: http.MethodGet, Path: "/users", ID: "findUserByEmail", Tag: "users", Summary: "Find a user by email", Public: true,
+		Params: []Parameter{required("email", "string", "Email of the user.")}, Response: response.UserResponse{}},
+	{Method: http.MethodPost, Path: "/users/login", ID: "login", Tag: "users", Summary: "Log in and open a session", Public: true,
+		Body: request.LoginRequest{}, Response: response.TokenResponse{}},
+	{Method: http.MethodPost, Path: "/users/refresh", ID: "refreshTokens", Tag: "users", Summary: "Exchange a refresh token for new tokens", Public: true,
+		Body: request.RefreshRequest{}, Response: response.TokenResponse{}},
+	{Method: http.MethodPost, Path: "/users/logout", ID: "logout", Tag: "users", Summary: "Revoke the current session",
+		Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/users/logout/all", ID: "logoutAll", Tag: "users", Summary: "Revoke every session of the caller",
+		Status: http.StatusNoContent},
+	{Method: http.MethodPut, Path: "/users/password", ID: "updatePassword", Tag: "users", Summary: "Change the password of the caller",
+		Body: request.PasswordRequest{}, Response: content{"text/plain": {Type: "string"}}},
+	{Method: http.MethodDelete, Path: "/users", ID: "deleteUser", Tag: "users", Summary: "Delete the account of the caller"},
+	{Method: http.MethodPut, Path: "/users", ID: "updateUser", Tag: "users", Summary: "Change the name and email of the caller",
+		Body: request.UserUpdateRequest{}},
+
+	{Method: http.MethodPost, Path: "/category", ID: "createCategory", Tag: "categories", Summary: "Create a category",
+		Body: request.CategoryRequest{}, Status: http.StatusCreated, Response: model.Category{}},
+	{Method: http.MethodGet, Path: "/category/id", ID: "getCategory", Tag: "categories", Summary: "Get a category by ID",
+		Params: id, Response: model.Category{}},
+	{Method: http.MethodGet, Path: "/category/name", ID: "getCategoryByName", Tag: "categories", Summary: "Get a category by name",
+		Params: []Parameter{required("name", "string", "Name of the category.")}, Response: model.Category{}},
+	{Method: http.MethodPut, Path: "/category", ID: "updateCategory", Tag: "categories", Summary: "Rename or move a category",
+		Params: id, Body: request.CategoryRequest{}, Response: model.Category{}},
+	{Method: http.MethodDelete, Path: "/category", ID: "deleteCategory", Tag: "categories", Summary: "Delete a category",
+		Params: id},
+	{Method: http.MethodGet, Path: "/category", ID: "listCategories", Tag: "categories", Summary: "List the categories of the caller",
+		Params:   params(categoryParams, listParams),
+		Response: list{model.Category{}, model.Page[model.Category]{}}},
+
+	{Method: http.MethodPost, Path: "/expense", ID: "createExpense", Tag: "expenses", Summary: "Add an expense to a plan",
+		Body: request.ExpenseRequest{}, Status: http.StatusCreated, Response: model.Expense{}},
+	{Method: http.MethodGet, Path: "/expense/plan", ID: "listExpensesByPlan", Tag: "expenses", Summary: "List the expenses of a plan",
+		Params:   params(planByID, expenseParams, listParams),
+		Response: list{model.Expense{}, model.Page[model.Expense]{}}},
+	{Method: http.MethodGet, Path: "/expense/category", ID: "listExpensesByCategory", Tag: "expenses", Summary: "List the expenses of a category",
+		Params: params(id, listParams), Response: list{model.Expense{}, model.Page[model.Expense]{}}},
+	{Method: http.MethodGet, Path: "/expense/search", ID: "searchExpenses", Tag: "expenses", Summary: "Search expenses by text",
+		Params: []Parameter{
+			param("q", "string", "Words to search for in descriptions, categories and tags."),
+			param("plan", "integer", "Only the expenses of this plan."),
+			param("category", "integer", "Only the expenses of this category."),
+			param("from", "date", "First day, inclusive."),
+			param("to", "date", "Last day, inclusive."),
+			param("min_amount", "number", "Smallest amount, inclusive."),
+			param("max_amount", "number", "Largest amount, inclusive."),
+			param("currency", "string", "Currency of the amount bounds."),
+			param("limit", "integer", "Page size."),
+			param("cursor", "string", "next_cursor of the previous page."),
+		},
+		Response: model.SearchPage{}},
+	{Method: http.MethodPut, Path: "/expense", ID: "updateExpense", Tag: "expenses", Summary: "Edit an expense",
+		Body: request.ExpenseUpdateRequest{}},
+	{Method: http.MethodDelete, Path: "/expense", ID: "deleteExpense", Tag: "expenses", Summary: "Delete an expense",
+		Body: request.DeleteExpenseRequest{}, Response: message{}},
+
+	{Method: http.MethodPost, Path: "/income", ID: "createIncome", Tag: "incomes", Summary: "Add an income to a plan",
+		Body: request.IncomeRequest{}, Status: http.StatusCreated, Response: model.Income{}},
+	{Method: http.MethodGet, Path: "/income/plan", ID: "listIncomesByPlan", Tag: "incomes", Summary: "List the incomes of a plan",
+		Params: reportParams, Response: []model.Income{}},
+	{Method: http.MethodPut, Path: "/income", ID: "updateIncome", Tag: "incomes", Summary: "Edit an income",
+		Body: request.IncomeUpdateRequest{}, Response: model.Income{}},
+	{Method: http.MethodDelete, Path: "/income", ID: "deleteIncome", Tag: "incomes", Summary: "Delete an income",
+		Params: id},
+	{Method: http.MethodGet, Path: "/plan/balance", ID: "getBalance", Tag: "incomes", Summary: "Compare the incomes and expenses of a plan",
+		Params: reportParams, Response: model.Balance{}},
+
+	{Method: http.MethodPost, Path: "/import/preview", ID: "previewImport", Tag: "import", Summary: "Parse a bank statement without importing it",
+		Params: []Parameter{
+			required("plan", "integer", "ID of the plan to import into."),
+			param("format", "string", "csv or ofx; defaults to the extension of the file."),
+		},
+		Body: multipart(map[string]*Schema{
+			"file":    binary,
+			"mapping": {Type: "string", Description: "JSON column mapping of a CSV statement."},
+		}, "file"),
+		Response: model.ImportPreview{}},
+	{Method: http.MethodPost, Path: "/import/commit", ID: "commitImport", Tag: "import", Summary: "Import the rows of a preview",
+		Body: request.ImportCommitRequest{}, Status: http.StatusCreated, Response: model.ImportResult{}},
+	{Method: http.MethodPost, Path: "/import/restore", ID: "restoreBackup", Tag: "import", Summary: "Restore a JSON export",
+		Body: model.ExportDocument{}, Status: http.StatusCreated, Response: model.RestoreResult{}},
+
+	{Method: http.MethodGet, Path: "/export", ID: "exportData", Tag: "import", Summary: "Export the data of the caller",
+		Params: []Parameter{
+			param("format", "string", "csv, json (default) or ofx."),
+			param("plan", "integer", "Only the expenses of this plan."),
+			param("from", "date", "First day, inclusive."),
+			param("to", "date", "Last day, inclusive."),
+		},
+		Response: content{"application/json": {Ref: "#/components/schemas/ExportDocument"}, "text/csv": binary, "application/x-ofx": binary}},
+
+	{Method: http.MethodPost, Path: "/plan", ID: "createPlan", Tag: "plans", Summary: "Create a plan",
+		Body: request.BudgetPlanRequest{}, Status: http.StatusCreated, Response: model.BudgetPlan{}},
+	{Method: http.MethodGet, Path: "/plan/user", ID: "listPlans", Tag: "plans", Summary: "List the plans of the caller",
+		Params: params(planParams, []Parameter{
+			param("expenses", "string", "summary (default), full or none."),
+		}, listParams),
+		Response: list{model.BudgetPlan{}, model.Page[model.BudgetPlan]{}}},
+	{Method: http.MethodDelete, Path: "/plan", ID: "deletePlan", Tag: "plans", Summary: "Delete a plan",
+		Params: planByID},
+	{Method: http.MethodPut, Path: "/plan/amount", ID: "updatePlanAmount", Tag: "plans", Summary: "Add to or subtract from the total of a plan",
+		Body: request.PlanAmountRequest{}, Response: model.PlanAdjustment{}},
+	{Method: http.MethodGet, Path: "/plan/amount/history", ID: "getPlanAmountHistory", Tag: "plans", Summary: "List the changes to the total of a plan",
+		Params: planByID, Response: []model.PlanAdjustment{}},
+	{Method: http.MethodPut, Path: "/plan", ID: "updatePlan", Tag: "plans", Summary: "Edit a plan",
+		Body: request.BudgetPlanUpdateRequest{}},
+
+	{Method: http.MethodGet, Path: "/plan/members", ID: "listMembers", Tag: "members", Summary: "List the members of a plan",
+		Params: plan, Response: []model.PlanMember{}},
+	{Method: http.MethodPut, Path: "/plan/members", ID: "updateMemberRole", Tag: "members", Summary: "Change the role of a member",
+		Body: request.MemberRoleRequest{}},
+	{Method: http.MethodDelete, Path: "/plan/members", ID: "removeMember", Tag: "members", Summary: "Remove a member from a plan",
+		Params: params(plan, []Parameter{required("user", "integer", "ID of the member.")})},
+	{Method: http.MethodPost, Path: "/plan/invitations", ID: "invite", Tag: "members", Summary: "Invite a user to a plan",
+		Body: request.InvitationRequest{}, Status: http.StatusCreated, Response: model.PlanInvitation{}},
+	{Method: http.MethodGet, Path: "/plan/invitations", ID: "listInvitations", Tag: "members", Summary: "List the pending invitations of a plan",
+		Params: plan, Response: []model.PlanInvitation{}},
+	{Method: http.MethodDelete, Path: "/plan/invitations", ID: "revokeInvitation", Tag: "members", Summary: "Revoke an invitation",
+		Params: id},
+	{Method: http.MethodGet, Path: "/plan/invitations/pending", ID: "listPendingInvitations", Tag: "members", Summary: "List the invitations addressed to the caller",
+		Response: []model.PlanInvitation{}},
+	{Method: http.MethodPost, Path: "/plan/invitations/accept", ID: "acceptInvitation", Tag: "members", Summary: "Accept an invitation",
+		Params: id, Response: model.PlanInvitation{}},
+	{Method: http.MethodPost, Path: "/plan/invitations/decline", ID: "declineInvitation", Tag: "members", Summary: "Decline an invitation",
+		Params: id, Response: model.PlanInvitation{}},
+
+	{Method: http.MethodPost, Path: "/expense/attachments", ID: "uploadAttachment", Tag: "attachments", Summary: "Attach a receipt to an expense",
+		Params: expense, Body: multipart(map[string]*Schema{"file": binary}, "file"), Status: http.StatusCreated, Response: model.Attachment{}},
+	{Method: http.MethodGet, Path: "/expense/attachments", ID: "listAttachments", Tag: "attachments", Summary: "List the attachments of an expense",
+		Params: expense, Response: []model.Attachment{}},
+	{Method: http.MethodDelete, Path: "/expense/attachments", ID: "deleteAttachment", Tag: "attachments", Summary: "Delete an attachment",
+		Params: id},
+	{Method: http.MethodGet, Path: "/expense/attachments/download", ID: "downloadAttachment", Tag: "attachments", Summary: "Download the file of an attachment",
+		Params: id, Response: content{"application/octet-stream": binary}},
+
+	{Method: http.MethodGet, Path: "/tags", ID: "listTags", Tag: "tags", Summary: "List the tags of a plan",
+		Params: plan, Response: []model.Tag{}},
+	{Method: http.MethodPut, Path: "/tags", ID: "renameTag", Tag: "tags", Summary: "Rename a tag",
+		Body: request.TagRequest{}, Response: model.Tag{}},
+	{Method: http.MethodDelete, Path: "/tags", ID: "deleteTag", Tag: "tags", Summary: "Delete a tag",
+		Params: params(id, plan)},
+	{Method: http.MethodPost, Path: "/tags/merge", ID: "mergeTags", Tag: "tags", Summary: "Merge tags into another",
+		Body: request.MergeTagsRequest{}},
+
+	{Method: http.MethodPost, Path: "/rules", ID: "createRule", Tag: "rules", Summary: "Create a rule",
+		Body: model.ExpenseRule{}, Status: http.StatusCreated, Response: model.ExpenseRule{}},
+	{Method: http.MethodGet, Path: "/rules", ID: "listRules", Tag: "rules", Summary: "List the rules of the caller",
+		Response: []model.ExpenseRule{}},
+	{Method: http.MethodPut, Path: "/rules", ID: "updateRule", Tag: "rules", Summary: "Edit a rule",
+		Body: model.ExpenseRule{}, Response: model.ExpenseRule{}},
+	{Method: http.MethodDelete, Path: "/rules", ID: "deleteRule", Tag: "rules", Summary: "Delete a rule",
+		Params: id},
+	{Method: http.MethodPost, Path: "/rules/apply", ID: "applyRules", Tag: "rules", Summary: "Apply the rules to the expenses of a plan",
+		Body: request.RuleRunRequest{}, Response: model.RuleRun{}},
+	{Method: http.MethodPost, Path: "/rules/dry-run", ID: "dryRunRules", Tag: "rules", Summary: "Report what applying the rules would change",
+		Body: request.RuleRunRequest{}, Response: model.RuleRun{}},
+
+	{Method: http.MethodPut, Path: "/expense/splits", ID: "splitExpense", Tag: "splits", Summary: "Share an expense between members",
+		Body: request.SplitRequest{}, Response: []model.ExpenseSplit{}},
+	{Method: http.MethodGet, Path: "/expense/splits", ID: "listSplits", Tag: "splits", Summary: "List the shares of an expense",
+		Params: id, Response: []model.ExpenseSplit{}},
+	{Method: http.MethodDelete, Path: "/expense/splits", ID: "unsplitExpense", Tag: "splits", Summary: "Stop sharing an expense",
+		Params: id},
+	{Method: http.MethodGet, Path: "/plan/settlement", ID: "getSettlement", Tag: "splits", Summary: "Compute who owes whom in a plan",
+		Params: plan, Response: model.Settlement{}},
+	{Method: http.MethodPost, Path: "/plan/settlement/payments", ID: "recordPayment", Tag: "splits", Summary: "Record a payment between members",
+		Body: request.SettlementPaymentRequest{}, Status: http.StatusCreated, Response: model.SettlementPayment{}},
+	{Method: http.MethodGet, Path: "/plan/settlement/payments", ID: "listPayments", Tag: "splits", Summary: "List the payments between members",
+		Params: plan, Response: []model.SettlementPayment{}},
+	{Method: http.MethodDelete, Path: "/plan/settlement/payments", ID: "deletePayment", Tag: "splits", Summary: "Delete a payment",
+		Params: id},
+
+	{Method: http.MethodGet, Path: "/plan/periods", ID: "listPeriods", Tag: "plans", Summary: "List the budget periods of a plan",
+		Params: plan, Response: []model.BudgetPeriod{}},
+	{Method: http.MethodGet, Path: "/plan/periods/current", ID: "getCurrentPeriod", Tag: "plans", Summary: "Get the current budget period of a plan",
+		Params: plan, Response: model.BudgetPeriod{}},
+
+	{Method: http.MethodGet, Path: "/plan/budgets", ID: "listCategoryBudgets", Tag: "budgets", Summary: "List the category allocations of a plan",
+		Params: plan, Response: []model.CategoryBudget{}},
+	{Method: http.MethodPut, Path: "/plan/budgets", ID: "saveCategoryBudget", Tag: "budgets", Summary: "Allocate part of a plan to a category",
+		Body: request.CategoryBudgetRequest{}, Response: model.CategoryBudget{}},
+	{Method: http.MethodDelete, Path: "/plan/budgets", ID: "deleteCategoryBudget", Tag: "budgets", Summary: "Remove the allocation of a category",
+		Params: params(plan, []Parameter{required("category", "integer", "ID of the category.")})},
+	{Method: http.MethodGet, Path: "/plan/budgets/status", ID: "getBudgetStatus", Tag: "budgets", Summary: "Compare the allocations of a plan with its expenses",
+		Params: reportParams, Response: model.BudgetStatus{}},
+
+	{Method: http.MethodPost, Path: "/recurring", ID: "createRecurringExpense", Tag: "recurring", Summary: "Create a recurring expense",
+		Body: request.RecurringExpenseRequest{}, Status: http.StatusCreated, Response: model.RecurringExpense{}},
+	{Method: http.MethodGet, Path: "/recurring/plan", ID: "listRecurringExpenses", Tag: "recurring", Summary: "List the recurring expenses of a plan",
+		Params: planByID, Response: []model.RecurringExpense{}},
+	{Method: http.MethodPut, Path: "/recurring", ID: "updateRecurringExpense", Tag: "recurring", Summary: "Edit the future occurrences of a recurring expense",
+		Params: id, Body: request.RecurringExpenseRequest{}, Response: model.RecurringExpense{}},
+	{Method: http.MethodDelete, Path: "/recurring", ID: "deleteRecurringExpense", Tag: "recurring", Summary: "Delete a recurring expense",
+		Params: id},
+	{Method: http.MethodPut, Path: "/recurring/pause", ID: "pauseRecurringExpense", Tag: "recurring", Summary: "Pause a recurring expense",
+		Params: id},
+	{Method: http.MethodPut, Path: "/recurring/resume", ID: "resumeRecurringExpense", Tag: "recurring", Summary: "Resume a recurring expense",
+		Params: id},
+	{Method: http.MethodPut, Path: "/recurring/skip", ID: "skipOccurrence", Tag: "recurring", Summary: "Skip an occurrence of a recurring expense",
+		Params: params(id, []Parameter{param("date", "date", "Day of the occurrence; defaults to the next one.")})},
+
+	{Method: http.MethodGet, Path: "/reports/category", ID: "reportByCategory", Tag: "reports", Summary: "Sum the expenses of a plan by category",
+		Params: reportParams, Response: model.Report{}},
+	{Method: http.MethodGet, Path: "/reports/period", ID: "reportByPeriod", Tag: "reports", Summary: "Sum the expenses of a plan by day, week or month",
+		Params: params(reportParams, []Parameter{param("period", "string", "day, week or month (default).")}), Response: model.Report{}},
+	{Method: http.MethodGet, Path: "/reports/recurrence", ID: "reportByRecurrence", Tag: "reports", Summary: "Sum the recurring and one-off expenses of a plan",
+		Params: reportParams, Response: model.Report{}},
+	{Method: http.MethodGet, Path: "/reports/tag", ID: "reportByTag", Tag: "reports", Summary: "Sum the expenses of a plan by tag",
+		Params: reportParams, Response: model.Report{}},
+
+	{Method: http.MethodPost, Path: "/rates", ID: "createExchangeRate", Tag: "rates", Summary: "Store an exchange rate",
+		Body: request.ExchangeRateRequest{}, Status: http.StatusCreated, Response: model.ExchangeRate{}},
+	{Method: http.MethodPost, Path: "/rates/import", ID: "importExchangeRates", Tag: "rates", Summary: "Store the exchange rates of a CSV file",
+		Body: content{"text/csv": {Type: "string", Description: "Rows of date,base,quote,rate."}}, Status: http.StatusCreated, Response: imported{}},
+	{Method: http.MethodGet, Path: "/rates", ID: "listExchangeRates", Tag: "rates", Summary: "List exchange rates",
+		Params:   []Parameter{param("base", "string", "Base currency."), param("quote", "string", "Quote currency.")},
+		Response: []model.ExchangeRate{}},
+
+	{Method: http.MethodGet, Path: "/openapi.json", ID: "getOpenAPI", Tag: "docs", Summary: "This document", Public: true,
+		Response: content{"application/json": {Type: "object"}}},
+	{Method: http.MethodGet, Path: "/docs", ID: "getDocs", Tag: "docs", Summary: "Browse this document", Public: true,
+		Response: content{"text/html": {Type: "string"}}},
+
+	{Method: http.MethodGet, Path: "/api/v2/plans", ID: "v2ListPlans", Tag: "v2", Summary: "List the plans of the caller",
+		Params: params(planParams, []Parameter{
+			param("expenses", "string", "none (default), summary or full."),
+		}, listParams),
+		Response: list{model.BudgetPlan{}, model.Page[model.BudgetPlan]{}}},
+	{Method: http.MethodPost, Path: "/api/v2/plans", ID: "v2CreatePlan", Tag: "v2", Summary: "Create a plan",
+		Body: request.BudgetPlanRequest{}, Status: http.StatusCreated, Response: model.BudgetPlan{}},
+	{Method: http.MethodGet, Path: "/api/v2/plans/{id}", ID: "v2GetPlan", Tag: "v2", Summary: "Get a plan",
+		Params: params(planPath, []Parameter{ifNoneMatch}), Response: model.BudgetPlan{}},
+	{Method: http.MethodPatch, Path: "/api/v2/plans/{id}", ID: "v2PatchPlan", Tag: "v2", Summary: "Edit some fields of a plan",
+		Params: params(planPath, []Parameter{ifMatch}), Body: request.BudgetPlanPatchRequest{}, Response: model.BudgetPlan{}},
+	{Method: http.MethodDelete, Path: "/api/v2/plans/{id}", ID: "v2DeletePlan", Tag: "v2", Summary: "Delete a plan",
+		Params: params(planPath, []Parameter{ifMatch}), Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/api/v2/plans/{id}/adjustments", ID: "v2ListPlanAdjustments", Tag: "v2", Summary: "List the changes to the total of a plan",
+		Params: planPath, Response: []model.PlanAdjustment{}},
+	{Method: http.MethodPost, Path: "/api/v2/plans/{id}/adjustments", ID: "v2AdjustPlan", Tag: "v2", Summary: "Add to or subtract from the total of a plan",
+		Params: planPath, Body: request.PlanAmountRequest{}, Status: http.StatusCreated, Response: model.PlanAdjustment{}},
+	{Method: http.MethodGet, Path: "/api/v2/plans/{id}/expenses", ID: "v2ListExpenses", Tag: "v2", Summary: "List the expenses of a plan",
+		Params:   params(planPath, expenseParams, listParams),
+		Response: list{model.Expense{}, model.Page[model.Expense]{}}},
+	{Method: http.MethodPost, Path: "/api/v2/plans/{id}/expenses", ID: "v2CreateExpense", Tag: "v2", Summary: "Add an expense to a plan",
+		Params: planPath, Body: request.ExpenseRequest{}, Status: http.StatusCreated, Response: model.Expense{}},
+	{Method: http.MethodGet, Path: "/api/v2/plans/{id}/expenses/{expenseId}", ID: "v2GetExpense", Tag: "v2", Summary: "Get an expense",
+		Params: params(expensePath, []Parameter{ifNoneMatch}), Response: model.Expense{}},
+	{Method: http.MethodPatch, Path: "/api/v2/plans/{id}/expenses/{expenseId}", ID: "v2PatchExpense", Tag: "v2", Summary: "Edit some fields of an expense",
+		Params: params(expensePath, []Parameter{ifMatch}), Body: request.ExpensePatchRequest{}, Response: model.Expense{}},
+	{Method: http.MethodDelete, Path: "/api/v2/plans/{id}/expenses/{expenseId}", ID: "v2DeleteExpense", Tag: "v2", Summary: "Delete an expense",
+		Params: params(expensePath, []Parameter{ifMatch}), Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/api/v2/categories", ID: "v2ListCategories", Tag: "v2", Summary: "List the categories of the caller",
+		Params:   params(categoryParams, listParams),
+		Response: list{model.Category{}, model.Page[model.Category]{}}},
+	{Method: http.MethodPost, Path: "/api/v2/categories", ID: "v2CreateCategory", Tag: "v2", Summary: "Create a category",
+		Body: request.CategoryRequest{}, Status: http.StatusCreated, Response: model.Category{}},
+	{Method: http.MethodGet, Path: "/api/v2/categories/{id}", ID: "v2GetCategory", Tag: "v2", Summary: "Get a category",
+		Params: params(categoryPath, []Parameter{ifNoneMatch}), Response: model.Category{}},
+	{Method: http.MethodPatch, Path: "/api/v2/categories/{id}", ID: "v2PatchCategory", Tag: "v2", Summary: "Rename or move a category",
+		Params: params(categoryPath, []Parameter{ifMatch}), Body: request.CategoryPatchRequest{}, Response: model.Category{}},
+	{Method: http.MethodDelete, Path: "/api/v2/categories/{id}", ID: "v2DeleteCategory", Tag: "v2", Summary: "Delete a category",
+		Params: params(categoryPath, []Parameter{ifMatch}), Status: http.StatusNoContent},
+}
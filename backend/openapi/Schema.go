@@ -0,0 +1,202 @@
+package openapi
+
+import (
+	"backend/model"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// marshalled lists the properties that types add to their fields, or
+// replace, in their own MarshalJSON and UnmarshalJSON.
+var marshalled = map[reflect.Type]map[string]interface{}{
+	reflect.TypeOf(model.BudgetPlan{}):  {"baseCurrency": ""},
+	reflect.TypeOf(model.Expense{}):     {"currency": ""},
+	reflect.TypeOf(model.Income{}):      {"currency": ""},
+	reflect.TypeOf(model.ImportRow{}):   {"currency": ""},
+	reflect.TypeOf(model.ExpenseRule{}): {"min_amount": (*model.Money)(nil), "max_amount": (*model.Money)(nil)},
+}
+
+// generator builds the schemas of Go types. Named structs are added to
+// schemas once and referred to by $ref everywhere else.
+type generator struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newGenerator() *generator {
+	return &generator{schemas: map[string]*Schema{}, names: map[reflect.Type]string{}}
+}
+
+// schema returns the schema of the JSON encoding of t.
+func (g *generator) schema(t reflect.Type) *Schema {
+	switch t {
+	case reflect.TypeOf(time.Time{}):
+		return &Schema{Type: "string", Format: "date-time"}
+	case reflect.TypeOf(model.Money{}):
+		return &Schema{Type: "number", Description: "Amount in major units of its currency."}
+	case reflect.TypeOf(json.RawMessage{}):
+		return &Schema{}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := g.schema(t.Elem())
+		if s.Ref == "" {
+			s.Nullable = true
+		}
+		return s
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		return g.ref(t)
+	}
+	return &Schema{}
+}
+
+// ref adds the schema of the named struct t to the components and returns
+// a reference to it.
+func (g *generator) ref(t reflect.Type) *Schema {
+	name, ok := g.names[t]
+	if !ok {
+		name = typeName(t)
+		if _, taken := g.schemas[name]; taken {
+			pkg := pkgName(t)
+			name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+		}
+		g.names[t] = name
+		// registered before its fields so that recursive types end
+		g.schemas[name] = &Schema{}
+		*g.schemas[name] = *g.object(t)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// object builds the schema of the fields of struct t, following the rules
+// of encoding/json for tags and embedded structs and the validate tags of
+// the request package for constraints.
+func (g *generator) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	g.fields(t, s)
+	for name, v := range marshalled[t] {
+		s.Properties[name] = g.schema(reflect.TypeOf(v))
+	}
+	return s
+}
+
+func (g *generator) fields(t reflect.Type, s *Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.fields(ft, s)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		prop := g.schema(f.Type)
+		if rules := f.Tag.Get("validate"); rules != "" {
+			if constrain(prop, rules) {
+				s.Required = append(s.Required, name)
+			}
+		}
+		s.Properties[name] = prop
+	}
+}
+
+// constrain adds the rules of a validate tag to s and reports whether the
+// field is required.
+func constrain(s *Schema, rules string) bool {
+	required := false
+	for _, rule := range strings.Split(rules, ",") {
+		rule, arg, _ := strings.Cut(rule, "=")
+		n, _ := strconv.ParseFloat(arg, 64)
+		count := int(n)
+		switch rule {
+		case "required":
+			required = true
+		case "min":
+			switch s.Type {
+			case "string":
+				s.MinLength = &count
+			case "array":
+				s.MinItems = &count
+			default:
+				s.Minimum = &n
+			}
+		case "max":
+			switch s.Type {
+			case "string":
+				s.MaxLength = &count
+			case "array":
+				s.MaxItems = &count
+			default:
+				s.Maximum = &n
+			}
+		case "gt":
+			s.Minimum, s.ExclusiveMinimum = &n, true
+		case "gte":
+			s.Minimum = &n
+		case "oneof":
+			s.Enum = strings.Fields(arg)
+		case "email":
+			s.Format = "email"
+		case "currency":
+			s.Pattern = "^([A-Za-z]{3})?$"
+			s.Description = "ISO-4217 currency code."
+		}
+	}
+	return required
+}
+
+// typeName names the schema of t, capitalized. Instances of generic types
+// are named after their type argument, as in ExpensePage for Page[Expense].
+func typeName(t reflect.Type) string {
+	name := t.Name()
+	if i := strings.Index(name, "["); i >= 0 {
+		arg := name[i+1 : len(name)-1]
+		arg = arg[strings.LastIndex(arg, ".")+1:]
+		name = arg + name[:i]
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+func pkgName(t reflect.Type) string {
+	path := t.PkgPath()
+	return path[strings.LastIndex(path, "/")+1:]
+}
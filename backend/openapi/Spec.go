@@ -0,0 +1,196 @@
+package openapi
+
+import (
+	"backend/middleware"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+)
+
+// Version is the version of the API described by the document.
+const Version = "1.0.0"
+
+// DocsPage is a page that browses the document served next to it as openapi.json.
+//
+//go:embed docs.html
+var DocsPage []byte
+
+var tags = []Tag{
+	{Name: "users", Description: "Accounts and sessions."},
+	{Name: "plans", Description: "Budget plans, their totals and periods."},
+	{Name: "members", Description: "Sharing plans with other users."},
+	{Name: "categories"},
+	{Name: "expenses"},
+	{Name: "attachments", Description: "Receipts attached to expenses."},
+	{Name: "tags", Description: "Labels on the expenses of a plan."},
+	{Name: "rules", Description: "Categorizing and tagging expenses automatically."},
+	{Name: "splits", Description: "Sharing expenses between members and settling up."},
+	{Name: "budgets", Description: "Allocating a plan to categories."},
+	{Name: "recurring", Description: "Expenses created on a schedule."},
+	{Name: "incomes"},
+	{Name: "reports"},
+	{Name: "import", Description: "Bank statements, backups and exports."},
+	{Name: "rates", Description: "Exchange rates between currencies."},
+	{Name: "docs"},
+	{Name: "v2", Description: "Resources under /api/v2, addressed by path parameters. Single resources are written with an ETag holding their version; PATCH and DELETE honour If-Match and answer 412 when the resource changed, which PATCH also does when a concurrent write lands between its read and its write."},
+}
+
+var (
+	once    sync.Once
+	spec    *Document
+	encoded []byte
+)
+
+// Spec returns the OpenAPI document of the API.
+func Spec() *Document {
+	once.Do(func() {
+		spec = build()
+		encoded, _ = json.MarshalIndent(spec, "", "  ")
+	})
+	return spec
+}
+
+// JSON returns the OpenAPI document encoded as JSON.
+func JSON() []byte {
+	Spec()
+	return encoded
+}
+
+func build() *Document {
+	g := newGenerator()
+	errorRef := g.schema(reflect.TypeOf(middleware.ErrorResponse{}))
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title:       "GastoZero API",
+			Description: "Budget plans and expenses. Errors are written as an ErrorResponse with a stable code.",
+			Version:     Version,
+		},
+		Tags:  tags,
+		Paths: map[string]PathItem{},
+		Components: Components{
+			Schemas: g.schemas,
+			SecuritySchemes: map[string]*SecurityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+	for _, e := range endpoints {
+		op := &Operation{
+			OperationID: e.ID,
+			Summary:     e.Summary,
+			Tags:        []string{e.Tag},
+			Parameters:  e.Params,
+			Responses:   map[string]*Response{},
+		}
+		if !e.Public {
+			op.Security = []map[string][]string{{"bearerAuth": {}}}
+		}
+		if e.Body != nil {
+			op.RequestBody = &RequestBody{Required: true, Content: g.content(e.Body)}
+		}
+		status := e.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		ok := &Response{Description: http.StatusText(status)}
+		if e.Response != nil {
+			ok.Content = g.content(e.Response)
+		}
+		op.Responses[strconv.Itoa(status)] = ok
+		op.Responses["default"] = &Response{
+			Description: "Error",
+			Content:     map[string]*MediaType{"application/json": {Schema: errorRef}},
+		}
+		if doc.Paths[e.Path] == nil {
+			doc.Paths[e.Path] = PathItem{}
+		}
+		doc.Paths[e.Path][strings.ToLower(e.Method)] = op
+	}
+	return doc
+}
+
+// content returns the media types of a body or response of an endpoint.
+func (g *generator) content(v interface{}) map[string]*MediaType {
+	media := map[string]*MediaType{}
+	switch v := v.(type) {
+	case content:
+		for mediaType, s := range v {
+			media[mediaType] = &MediaType{Schema: s}
+		}
+	case list:
+		media["application/json"] = &MediaType{Schema: &Schema{OneOf: []*Schema{
+			{Type: "array", Items: g.schema(reflect.TypeOf(v.item))},
+			g.schema(reflect.TypeOf(v.page)),
+		}}}
+	default:
+		media["application/json"] = &MediaType{Schema: g.schema(reflect.TypeOf(v))}
+	}
+	return media
+}
+
+// variablePattern matches a variable of a route template, such as {id:[0-9]+}.
+var variablePattern = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+// Check compares the routes of router with the operations of the document
+// and describes the routes missing from either.
+func Check(router *mux.Router) error {
+	documented := map[string]bool{}
+	for path, item := range Spec().Paths {
+		for method := range item {
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+	var undocumented []string
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil || route.GetHandler() == nil {
+			// subrouters are walked through their own routes
+			return nil
+		}
+		path = variablePattern.ReplaceAllString(path, "{$1}")
+		methods, err := route.GetMethods()
+		if err != nil {
+			undocumented = append(undocumented, "* "+path)
+			return nil
+		}
+		for _, method := range methods {
+			key := method + " " + path
+			if documented[key] {
+				delete(documented, key)
+			} else {
+				undocumented = append(undocumented, key)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	var missing []string
+	for key := range documented {
+		missing = append(missing, key)
+	}
+	sort.Strings(undocumented)
+	sort.Strings(missing)
+	var problems []string
+	if len(undocumented) > 0 {
+		problems = append(problems, "routes not documented: "+strings.Join(undocumented, ", "))
+	}
+	if len(missing) > 0 {
+		problems = append(problems, "documented operations without a route: "+strings.Join(missing, ", "))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("openapi: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
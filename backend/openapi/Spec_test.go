@@ -0,0 +1,60 @@
+package openapi_test
+
+import (
+	"backend/openapi"
+	"backend/repository"
+	"backend/routes"
+	"backend/service"
+	"testing"
+)
+
+// TestSpecMatchesRoutes fails when a route is added without documenting it,
+// or an operation stays documented after its route is gone. The services
+// are wired as in main, without a database, since no handler is called.
+func TestSpecMatchesRoutes(t *testing.T) {
+	repoFactory := repository.NewBase()
+	repoFactory.Init(
+		repository.NewUserRepository(nil),
+		repository.NewSessionRepository(nil),
+		repository.NewBudgetPlanRepository(nil),
+		repository.NewBudgetPeriodRepository(nil),
+		repository.NewPlanMemberRepository(nil),
+		repository.NewCategoryRepository(nil),
+		repository.NewExpensesRepository(nil),
+		repository.NewSplitRepository(nil),
+		repository.NewTagRepository(nil),
+		repository.NewRuleRepository(nil),
+		repository.NewAttachmentRepository(nil, nil),
+		repository.NewIncomeRepository(nil),
+		repository.NewExchangeRateRepository(nil),
+		repository.NewRecurringExpenseRepository(nil),
+		repository.NewReportRepository(nil),
+		repository.NewCategoryBudgetRepository(nil),
+		repository.NewRestoreRepository(nil),
+	)
+	serviceFactory := service.NewBase()
+	serviceFactory.Init(
+		service.NewUserService(repoFactory),
+		service.NewSessionService(repoFactory),
+		service.NewCategoryService(repoFactory),
+		service.NewExpensesService(repoFactory),
+		service.NewSplitService(repoFactory),
+		service.NewTagService(repoFactory),
+		service.NewRuleService(repoFactory),
+		service.NewAttachmentService(repoFactory),
+		service.NewIncomeService(repoFactory),
+		service.NewBudgetPlanService(repoFactory),
+		service.NewBudgetPeriodService(repoFactory),
+		service.NewPlanMemberService(repoFactory),
+		service.NewExchangeRateService(repoFactory),
+		service.NewRecurringExpenseService(repoFactory),
+		service.NewReportService(repoFactory),
+		service.NewCategoryBudgetService(repoFactory),
+		service.NewImportService(repoFactory),
+		service.NewExportService(repoFactory),
+	)
+
+	if err := openapi.Check(routes.SetupRoutes(serviceFactory)); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"backend/model"
+	"backend/storage"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+
+	"github.com/rs/zerolog/log"
+	"github.com/uptrace/bun"
+)
+
+type AttachmentRepository interface {
+	Create(attachment *model.Attachment, data []byte) error
+	Open(attachment *model.Attachment) (io.ReadCloser, error)
+	GetByID(id int, userID int) (*model.Attachment, error)
+	GetByExpense(expenseID int) ([]model.Attachment, error)
+	Delete(id int) error
+	Sweep(limit int) (int, error)
+}
+
+type attachmentRepository struct {
+	db    *bun.DB
+	store storage.BlobStore
+}
+
+func NewAttachmentRepository(db *bun.DB, store storage.BlobStore) AttachmentRepository {
+	log.Info().Msg("Initializing AttachmentRepository")
+	return &attachmentRepository{db: db, store: store}
+}
+
+// Create uploads the blob of an attachment and then records it. The blob is
+// removed again when the record cannot be inserted.
+func (r *attachmentRepository) Create(attachment *model.Attachment, data []byte) error {
+	log.Info().Int("expense_id", attachment.ExpenseID).Str("key", attachment.StorageKey).Msg("Creating attachment")
+	ctx := context.Background()
+	if err := r.store.Put(ctx, attachment.StorageKey, bytes.NewReader(data), int64(len(data)), attachment.ContentType); err != nil {
+		log.Error().Err(err).Str("key", attachment.StorageKey).Msg("Failed to store attachment blob")
+		return err
+	}
+	if err := r.db.NewInsert().Model(attachment).Returning("*").Scan(ctx, attachment); err != nil {
+		log.Error().Err(err).Int("expense_id", attachment.ExpenseID).Msg("Failed to create attachment")
+		if err := r.store.Delete(ctx, attachment.StorageKey); err != nil {
+			log.Error().Err(err).Str("key", attachment.StorageKey).Msg("Failed to remove orphaned attachment blob")
+		}
+		return err
+	}
+	return nil
+}
+
+// Open streams the blob of an attachment. The caller must close it.
+func (r *attachmentRepository) Open(attachment *model.Attachment) (io.ReadCloser, error) {
+	blob, err := r.store.Get(context.Background(), attachment.StorageKey)
+	if err != nil {
+		log.Error().Err(err).Int("id", attachment.ID).Msg("Failed to open attachment blob")
+	}
+	return blob, err
+}
+
+// GetByID retrieves an Attachment of an expense of a plan userID is a member of.
+func (r *attachmentRepository) GetByID(id int, userID int) (*model.Attachment, error) {
+	ctx := context.Background()
+	attachment := new(model.Attachment)
+	err := r.db.NewSelect().
+		Model(attachment).
+		ColumnExpr("attachment.*").
+		ColumnExpr("e.budget_id AS plan_id").
+		Join("JOIN expenses AS e ON e.id = attachment.expense_id").
+		Where("attachment.id = ? AND e.budget_id IN (?)", id, memberPlans(r.db, userID)).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// GetByExpense lists the attachments of an expense, oldest first.
+func (r *attachmentRepository) GetByExpense(expenseID int) ([]model.Attachment, error) {
+	ctx := context.Background()
+	var attachments []model.Attachment
+	err := r.db.NewSelect().
+		Model(&attachments).
+		Where("expense_id = ?", expenseID).
+		OrderExpr("created_at, id").
+		Scan(ctx)
+	if err != nil {
+		log.Error().Err(err).Int("expense_id", expenseID).Msg("Failed to fetch attachments")
+		return nil, err
+	}
+	return attachments, nil
+}
+
+// Delete removes the record of an attachment. Its blob is queued for Sweep by
+// a trigger, as are the blobs of attachments deleted by cascade.
+func (r *attachmentRepository) Delete(id int) error {
+	log.Info().Int("id", id).Msg("Deleting attachment")
+	ctx := context.Background()
+	res, err := r.db.NewDelete().Model((*model.Attachment)(nil)).Where("id = ?", id).Exec(ctx)
+	return checkAffected(res, err, "Failed to delete attachment", id)
+}
+
+// Sweep removes up to limit blobs queued for deletion and returns how many
+// were removed. Blobs that fail stay queued for the next sweep.
+func (r *attachmentRepository) Sweep(limit int) (int, error) {
+	ctx := context.Background()
+	var keys []string
+	err := r.db.NewSelect().
+		TableExpr("blob_deletions").
+		Column("storage_key").
+		OrderExpr("queued_at").
+		Limit(limit).
+		Scan(ctx, &keys)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to fetch queued blob deletions")
+		return 0, err
+	}
+
+	removed := 0
+	var errs []error
+	for _, key := range keys {
+		if err := r.store.Delete(ctx, key); err != nil {
+			log.Error().Err(err).Str("key", key).Msg("Failed to delete attachment blob")
+			errs = append(errs, err)
+			continue
+		}
+		if _, err := r.db.NewDelete().TableExpr("blob_deletions").Where("storage_key = ?", key).Exec(ctx); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		removed++
+	}
+	return removed, errors.Join(errs...)
+}
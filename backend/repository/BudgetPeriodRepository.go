@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"backend/model"
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/uptrace/bun"
+)
+
+type BudgetPeriodRepository interface {
+	Create(period *model.BudgetPeriod) error
+	GetByID(id int, planID int) (*model.BudgetPeriod, error)
+	GetByPlan(planID int) ([]model.BudgetPeriod, error)
+	Latest(planID int) (*model.BudgetPeriod, error)
+	Due(now time.Time) ([]model.BudgetPeriod, error)
+	SetEnd(id int, end *time.Time) error
+}
+
+type budgetPeriodRepository struct {
+	db *bun.DB
+}
+
+func NewBudgetPeriodRepository(db *bun.DB) BudgetPeriodRepository {
+	log.Info().Msg("Initializing BudgetPeriodRepository")
+	return &budgetPeriodRepository{db: db}
+}
+
+func (r *budgetPeriodRepository) Create(period *model.BudgetPeriod) error {
+	log.Info().Int("plan_id", period.PlanID).Time("start_date", period.StartDate).Msg("Creating budget period")
+	ctx := context.Background()
+	err := r.db.NewInsert().Model(period).Returning("*").Scan(ctx, period)
+	if err != nil {
+		log.Error().Err(err).Int("plan_id", period.PlanID).Msg("Failed to create budget period")
+	}
+	return err
+}
+
+// GetByID fetches a period of the plan along with what was spent in it.
+func (r *budgetPeriodRepository) GetByID(id int, planID int) (*model.BudgetPeriod, error) {
+	ctx := context.Background()
+	period := new(model.BudgetPeriod)
+	err := r.withSpent(r.db.NewSelect().Model(period)).
+		Where("budget_period.id = ? AND budget_period.plan_id = ?", id, planID).
+		Scan(ctx)
+	if err != nil {
+		log.Error().Err(err).Int("id", id).Msg("Failed to fetch budget period")
+		return nil, err
+	}
+	return period, nil
+}
+
+// GetByPlan lists the periods of the plan, most recent first, along with
+// what was spent in each.
+func (r *budgetPeriodRepository) GetByPlan(planID int) ([]model.BudgetPeriod, error) {
+	ctx := context.Background()
+	var periods []model.BudgetPeriod
+	err := r.withSpent(r.db.NewSelect().Model(&periods)).
+		Where("budget_period.plan_id = ?", planID).
+		Order("budget_period.start_date DESC").
+		Scan(ctx)
+	if err != nil {
+		log.Error().Err(err).Int("plan_id", planID).Msg("Failed to fetch budget periods")
+		return nil, err
+	}
+	return periods, nil
+}
+
+// Latest fetches the most recent period of the plan.
+func (r *budgetPeriodRepository) Latest(planID int) (*model.BudgetPeriod, error) {
+	ctx := context.Background()
+	period := new(model.BudgetPeriod)
+	err := r.withSpent(r.db.NewSelect().Model(period)).
+		Where("budget_period.plan_id = ?", planID).
+		Order("budget_period.start_date DESC").
+		Limit(1).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return period, nil
+}
+
+// Due lists the latest period of every periodic plan when it has ended by
+// now and the plan itself has not, along with the plan and what was spent.
+func (r *budgetPeriodRepository) Due(now time.Time) ([]model.BudgetPeriod, error) {
+	ctx := context.Background()
+	later := r.db.NewSelect().
+		TableExpr("budget_periods AS next").
+		ColumnExpr("1").
+		Where("next.plan_id = budget_period.plan_id").
+		Where("next.start_date > budget_period.start_date")
+	var periods []model.BudgetPeriod
+	err := r.withSpent(r.db.NewSelect().Model(&periods)).
+		Relation("Plan").
+		Where("budget_period.end_date <= ?", now).
+		Where("plan.period <> ?", model.PeriodOneOff).
+		Where("plan.end_date IS NULL OR plan.end_date > budget_period.end_date").
+		Where("NOT EXISTS (?)", later).
+		Scan(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to fetch due budget periods")
+		return nil, err
+	}
+	return periods, nil
+}
+
+// SetEnd moves the end of a period, used when the plan's period changes.
+func (r *budgetPeriodRepository) SetEnd(id int, end *time.Time) error {
+	ctx := context.Background()
+	res, err := r.db.NewUpdate().
+		Model((*model.BudgetPeriod)(nil)).
+		Set("end_date = ?", end).
+		Where("id = ?", id).
+		Exec(ctx)
+	return checkAffected(res, err, "Failed to update budget period end", id)
+}
+
+// withSpent adds the total of the plan's expenses within each period, in
+// minor units of the plan's base currency.
+func (r *budgetPeriodRepository) withSpent(q *bun.SelectQuery) *bun.SelectQuery {
+	spent := r.db.NewSelect().
+		Model((*model.Expense)(nil)).
+		ColumnExpr("COALESCE(SUM(expense.base_amount_minor), 0)").
+		Where("expense.budget_id = budget_period.plan_id").
+		Where("expense.date >= budget_period.start_date").
+		Where("budget_period.end_date IS NULL OR expense.date < budget_period.end_date")
+	return q.ColumnExpr("budget_period.*").ColumnExpr("(?) AS spent_minor", spent)
+}
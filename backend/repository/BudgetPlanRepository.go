@@ -5,16 +5,22 @@ import (
 	"context"
 	"github.com/rs/zerolog/log"
 	"github.com/uptrace/bun"
+	"strconv"
+	"strings"
+	"time"
 	//"log"
 )
 
 type BudgetPlanRepository interface {
 	Create(plan *model.BudgetPlan) error
-	Delete(id int) error
-	GetByUser(id int) ([]model.BudgetPlan, error)
-	UpdateAmount(id int, newAmount float64) error
+	Delete(id int, userID int, version int) error
+	GetByUser(userID int, filter PlanFilter, list model.ListQuery) (*model.Page[model.BudgetPlan], error)
+	Totals(ids []int) ([]PlanTotal, error)
+	ListByUser(userID int) ([]model.BudgetPlan, error)
+	AdjustAmount(adjustment *model.PlanAdjustment, userID int) error
+	GetAdjustments(id int, userID int) ([]model.PlanAdjustment, error)
 	Update(model *model.BudgetPlan) error
-	GetByID(id int) (*model.BudgetPlan, error)
+	GetAccessible(id int, userID int) (*model.BudgetPlan, error)
 	DeleteExpense(id int, expenseID int) error
 }
 
@@ -26,13 +32,17 @@ func NewBudgetPlanRepository(db *bun.DB) BudgetPlanRepository {
 	return &budgetPlanRepository{db: db}
 }
 
-// Create inserts a new BudgetPlan into the database and returns the created plan.
+// Create inserts a new BudgetPlan along with its owner membership and first
+// period, records a non-zero starting total in its amount history and
+// returns the created plan.
 func (r *budgetPlanRepository) Create(plan *model.BudgetPlan) error {
 	ctx := context.Background()
 	logger := log.With().Str("component", "BudgetPlanRepository").Str("method", "Create").Logger()
 	logger.Info().Int("user_id", plan.UserID).Msg("Creating Budget Plan")
 
-	err := r.db.NewInsert().Model(plan).Returning("*").Scan(ctx, plan)
+	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		return insertPlan(ctx, tx, plan)
+	})
 	if err != nil {
 		logger.Error().Err(err).Msg("Failed to create Budget Plan")
 		return err
@@ -42,24 +52,62 @@ func (r *budgetPlanRepository) Create(plan *model.BudgetPlan) error {
 	return nil
 }
 
-// Delete removes a BudgetPlan by ID and deletes all associated BudgetPlanExpense links.
-func (r *budgetPlanRepository) Delete(id int) error {
-	ctx := context.Background()
-	logger := log.With().Str("component", "BudgetPlanRepository").Str("method", "Delete").Int("budget_plan_id", id).Logger()
-	logger.Info().Msg("Deleting Budget Plan")
-
-	if _, err := r.db.NewDelete().
-		Model((*model.BudgetPlanExpense)(nil)).
-		Where("budget_plan_id = ?", id).
-		Exec(ctx); err != nil {
-		logger.Error().Err(err).Msg("Failed to delete related BudgetPlanExpense")
+// insertPlan inserts a BudgetPlan within tx with its owner, its first period
+// and, when it starts with a total, the adjustment that opens it.
+func insertPlan(ctx context.Context, tx bun.Tx, plan *model.BudgetPlan) error {
+	if err := tx.NewInsert().Model(plan).Returning("*").Scan(ctx, plan); err != nil {
+		return err
+	}
+	owner := &model.PlanMember{PlanID: plan.ID, UserID: plan.UserID, Role: model.RoleOwner}
+	if _, err := tx.NewInsert().Model(owner).Exec(ctx); err != nil {
 		return err
 	}
+	plan.Role = model.RoleOwner
+	if _, err := tx.NewInsert().Model(plan.FirstPeriod()).Exec(ctx); err != nil {
+		return err
+	}
+	if plan.TotalAmount.IsZero() {
+		return nil
+	}
+	_, err := tx.NewInsert().Model(&model.PlanAdjustment{
+		PlanID:         plan.ID,
+		Amount:         plan.TotalAmount,
+		OriginalAmount: plan.TotalAmount,
+		ExchangeRate:   1,
+		TotalAfter:     plan.TotalAmount,
+		Reason:         "Opening balance",
+		AuthorID:       &plan.UserID,
+	}).Exec(ctx)
+	return err
+}
 
-	if _, err := r.db.NewDelete().
-		Model(&model.BudgetPlan{}).
-		Where("id = ?", id).
-		Exec(ctx); err != nil {
+// Delete removes a BudgetPlan owned by userID and deletes all associated BudgetPlanExpense links.
+func (r *budgetPlanRepository) Delete(id int, userID int, version int) error {
+	ctx := context.Background()
+	logger := log.With().Str("component", "BudgetPlanRepository").Str("method", "Delete").Int("budget_plan_id", id).Int("user_id", userID).Logger()
+	logger.Info().Msg("Deleting Budget Plan")
+
+	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		owned := tx.NewSelect().
+			Model((*model.BudgetPlan)(nil)).
+			Column("id").
+			Where("id = ? AND user_id = ?", id, userID)
+		if _, err := tx.NewDelete().
+			Model((*model.BudgetPlanExpense)(nil)).
+			Where("budget_plan_id IN (?)", owned).
+			Exec(ctx); err != nil {
+			return err
+		}
+		res, err := atVersion(tx.NewDelete().
+			Model(&model.BudgetPlan{}).
+			Where("id = ? AND user_id = ?", id, userID), version).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		return checkVersion(res, version)
+	})
+	if err != nil {
 		logger.Error().Err(err).Msg("Failed to delete Budget Plan")
 		return err
 	}
@@ -68,71 +116,207 @@ func (r *budgetPlanRepository) Delete(id int) error {
 	return nil
 }
 
-// GetByUser fetches all BudgetPlans that belong to a specific user.
-func (r *budgetPlanRepository) GetByUser(userID int) ([]model.BudgetPlan, error) {
+// PlanFilter selects the plans of a user. Name matches part of the plan
+// name, ignoring case. Empty fields match every plan. WithExpenses loads the
+// Expenses of each plan.
+type PlanFilter struct {
+	Name         string
+	Role         model.PlanRole
+	Period       model.PeriodType
+	WithExpenses bool
+}
+
+// PlanTotal counts and sums the expenses of a plan, in minor units of its base currency.
+type PlanTotal struct {
+	PlanID int   `bun:"plan_id"`
+	Count  int   `bun:"count"`
+	Total  int64 `bun:"total"`
+}
+
+// planSorting orders the lists of BudgetPlans.
+var planSorting = sorting[model.BudgetPlan]{
+	id:    "budget_plan.id",
+	rowID: func(p *model.BudgetPlan) int { return p.ID },
+	keys: map[string]sortKey[model.BudgetPlan]{
+		"id":      {"budget_plan.id", func(p *model.BudgetPlan) string { return strconv.Itoa(p.ID) }, intKey},
+		"name":    {"lower(budget_plan.name)", func(p *model.BudgetPlan) string { return strings.ToLower(p.Name) }, textKey},
+		"created": {"budget_plan.created_date", func(p *model.BudgetPlan) string { return p.CreatedDate.Format(time.RFC3339Nano) }, timeKey},
+		"total":   {"budget_plan.total_amount_minor", func(p *model.BudgetPlan) string { return strconv.FormatInt(p.TotalAmount.Minor, 10) }, intKey},
+	},
+}
+
+// GetByUser fetches a page of the BudgetPlans a specific user is a member of,
+// along with their role, matched by filter.
+func (r *budgetPlanRepository) GetByUser(userID int, filter PlanFilter, list model.ListQuery) (*model.Page[model.BudgetPlan], error) {
 	ctx := context.Background()
 	logger := log.With().Str("component", "BudgetPlanRepository").Str("method", "GetByUser").Int("user_id", userID).Logger()
 	logger.Info().Msg("Fetching Budget Plans by user")
 
 	var plans []model.BudgetPlan
-	err := r.db.NewSelect().
-		Model(&plans).
-		Relation("Expenses").
-		Where("budget_plan.user_id = ?", userID).
-		Scan(ctx)
+	q := withRole(r.db.NewSelect().Model(&plans), userID)
+	if filter.Name != "" {
+		q = q.Where("budget_plan.name ILIKE ?", "%"+escapeLike(filter.Name)+"%")
+	}
+	if filter.Role != "" {
+		q = q.Where("pm.role = ?", filter.Role)
+	}
+	if filter.Period != "" {
+		q = q.Where("budget_plan.period = ?", filter.Period)
+	}
+	if filter.WithExpenses {
+		q = q.Relation("Expenses")
+	}
+	q, err := planSorting.apply(q, list)
 	if err != nil {
+		return nil, err
+	}
+	if err := q.Scan(ctx); err != nil {
 		logger.Error().Err(err).Msg("Failed to fetch Budget Plans by user")
 		return nil, err
 	}
 
 	logger.Info().Int("count", len(plans)).Msg("Fetched Budget Plans successfully")
+	return planSorting.page(plans, list), nil
+}
+
+// Totals counts and sums the expenses of the given plans. Plans without
+// expenses are left out.
+func (r *budgetPlanRepository) Totals(ids []int) ([]PlanTotal, error) {
+	var totals []PlanTotal
+	if len(ids) == 0 {
+		return totals, nil
+	}
+	ctx := context.Background()
+	err := r.db.NewSelect().
+		Model((*model.Expense)(nil)).
+		ColumnExpr("expense.budget_id AS plan_id").
+		ColumnExpr("count(*) AS count").
+		ColumnExpr("COALESCE(SUM(expense.base_amount_minor), 0) AS total").
+		Where("expense.budget_id IN (?)", bun.In(ids)).
+		Group("expense.budget_id").
+		Scan(ctx, &totals)
+	if err != nil {
+		log.Error().Err(err).Ints("plan_ids", ids).Msg("Failed to total Budget Plan expenses")
+		return nil, err
+	}
+	return totals, nil
+}
+
+// ListByUser fetches the BudgetPlans a user is a member of without loading their Expenses.
+func (r *budgetPlanRepository) ListByUser(userID int) ([]model.BudgetPlan, error) {
+	ctx := context.Background()
+	logger := log.With().Str("component", "BudgetPlanRepository").Str("method", "ListByUser").Int("user_id", userID).Logger()
+	logger.Info().Msg("Listing Budget Plans by user")
+
+	var plans []model.BudgetPlan
+	err := withRole(r.db.NewSelect().Model(&plans), userID).
+		Order("budget_plan.id").
+		Scan(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to list Budget Plans by user")
+		return nil, err
+	}
 	return plans, nil
 }
 
-// UpdateAmount updates only the total amount of a BudgetPlan.
-func (r *budgetPlanRepository) UpdateAmount(id int, newAmount float64) error {
+// AdjustAmount adds adjustment.Amount to the total of a BudgetPlan userID is
+// a member of in SQL, so that concurrent adjustments never overwrite each other,
+// and appends the adjustment with the resulting total to the plan history.
+// The period containing the day of the adjustment follows the change.
+func (r *budgetPlanRepository) AdjustAmount(adjustment *model.PlanAdjustment, userID int) error {
 	ctx := context.Background()
-	logger := log.With().Str("component", "BudgetPlanRepository").Str("method", "UpdateAmount").Int("budget_plan_id", id).Float64("new_amount", newAmount).Logger()
-	logger.Info().Msg("Updating Budget Plan amount")
+	logger := log.With().Str("component", "BudgetPlanRepository").Str("method", "AdjustAmount").Int("budget_plan_id", adjustment.PlanID).Int("user_id", userID).Stringer("amount", adjustment.Amount).Logger()
+	logger.Info().Msg("Adjusting Budget Plan amount")
 
-	plan := &model.BudgetPlan{ID: id, TotalAmount: newAmount}
-	if _, err := r.db.NewUpdate().Model(plan).Column("total_amount").Where("id = ?", id).Exec(ctx); err != nil {
-		logger.Error().Err(err).Msg("Failed to update Budget Plan amount")
+	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		var total int64
+		err := tx.NewUpdate().
+			Model((*model.BudgetPlan)(nil)).
+			Set("total_amount_minor = total_amount_minor + ?", adjustment.Amount.Minor).
+			Where("id = ? AND id IN (?)", adjustment.PlanID, memberPlans(tx, userID)).
+			Returning("total_amount_minor").
+			Scan(ctx, &total)
+		if err != nil {
+			return err
+		}
+		adjustment.TotalAfter = model.NewMoney(total, adjustment.Amount.CurrencyCode())
+		if err := tx.NewInsert().Model(adjustment).Returning("*").Scan(ctx, adjustment); err != nil {
+			return err
+		}
+		_, err = tx.NewUpdate().
+			Model((*model.BudgetPeriod)(nil)).
+			Set("amount_minor = amount_minor + ?", adjustment.Amount.Minor).
+			Where("plan_id = ?", adjustment.PlanID).
+			Where("start_date <= ?", adjustment.CreatedAt).
+			Where("end_date IS NULL OR end_date > ?", adjustment.CreatedAt).
+			Exec(ctx)
+		return err
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to adjust Budget Plan amount")
 		return err
 	}
 
-	logger.Info().Msg("Budget Plan amount updated successfully")
+	logger.Info().Msg("Budget Plan amount adjusted successfully")
 	return nil
 }
 
-// Update replaces the existing BudgetPlan data and its related expenses.
+// GetAdjustments lists the amount history of a BudgetPlan userID is a member of, newest first.
+func (r *budgetPlanRepository) GetAdjustments(id int, userID int) ([]model.PlanAdjustment, error) {
+	ctx := context.Background()
+	logger := log.With().Str("component", "BudgetPlanRepository").Str("method", "GetAdjustments").Int("budget_plan_id", id).Int("user_id", userID).Logger()
+	logger.Info().Msg("Fetching Budget Plan amount history")
+
+	var adjustments []model.PlanAdjustment
+	err := r.db.NewSelect().
+		Model(&adjustments).
+		ColumnExpr("plan_adjustment.*").
+		ColumnExpr("u.email AS author_email").
+		Join("JOIN budget_plan AS bp ON bp.id = plan_adjustment.plan_id").
+		Join("LEFT JOIN users AS u ON u.id = plan_adjustment.author_id").
+		Where("plan_adjustment.plan_id = ? AND bp.id IN (?)", id, memberPlans(r.db, userID)).
+		Order("plan_adjustment.id DESC").
+		Scan(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to fetch Budget Plan amount history")
+		return nil, err
+	}
+	return adjustments, nil
+}
+
+// Update replaces the name, description and period settings of a BudgetPlan owned by plan.UserID.
 func (r *budgetPlanRepository) Update(plan *model.BudgetPlan) error {
 	ctx := context.Background()
-	logger := log.With().Str("component", "BudgetPlanRepository").Str("method", "Update").Int("budget_plan_id", plan.ID).Logger()
+	logger := log.With().Str("component", "BudgetPlanRepository").Str("method", "Update").Int("budget_plan_id", plan.ID).Int("user_id", plan.UserID).Logger()
 	logger.Info().Msg("Updating Budget Plan")
 
-	if _, err := r.db.NewUpdate().Model(plan).
-		Column("name", "description").
-		Where("id = ?", plan.ID).
-		Exec(ctx); err != nil {
+	res, err := atVersion(r.db.NewUpdate().Model(plan).
+		Column("name", "description", "period", "period_days", "end_date", "rollover").
+		Where("id = ? AND user_id = ?", plan.ID, plan.UserID), plan.Version).
+		Exec(ctx)
+	if err != nil {
 		logger.Error().Err(err).Msg("Failed to update Budget Plan")
 		return err
 	}
+	if err := checkVersion(res, plan.Version); err != nil {
+		logger.Warn().Err(err).Msg("Budget Plan not found for user or changed")
+		return err
+	}
 
 	logger.Info().Msg("Budget Plan updated successfully")
 	return nil
 }
 
-// GetByID retrieves a BudgetPlan by its ID along with its related expenses.
-func (r *budgetPlanRepository) GetByID(id int) (*model.BudgetPlan, error) {
+// GetAccessible retrieves a BudgetPlan userID is a member of along with
+// their role, without its expenses, which makes it cheap enough for every
+// permission check.
+func (r *budgetPlanRepository) GetAccessible(id int, userID int) (*model.BudgetPlan, error) {
 	ctx := context.Background()
-	logger := log.With().Str("component", "BudgetPlanRepository").Str("method", "GetByID").Int("budget_plan_id", id).Logger()
+	logger := log.With().Str("component", "BudgetPlanRepository").Str("method", "GetAccessible").Int("budget_plan_id", id).Int("user_id", userID).Logger()
 	logger.Info().Msg("Fetching Budget Plan by ID")
 
 	plan := new(model.BudgetPlan)
-	err := r.db.NewSelect().
-		Model(plan).
-		Relation("Expenses").
+	err := withRole(r.db.NewSelect().Model(plan), userID).
 		Where("budget_plan.id = ?", id).
 		Scan(ctx)
 	if err != nil {
@@ -162,3 +346,11 @@ func (r *budgetPlanRepository) DeleteExpense(budgetID int, expenseID int) error
 	logger.Info().Msg("Expense deleted from Budget Plan successfully")
 	return nil
 }
+
+// withRole restricts a BudgetPlan query to the plans userID is a member of
+// and fills in their Role.
+func withRole(q *bun.SelectQuery, userID int) *bun.SelectQuery {
+	return q.ColumnExpr("budget_plan.*").
+		ColumnExpr("pm.role").
+		Join("JOIN plan_members AS pm ON pm.plan_id = budget_plan.id AND pm.user_id = ?", userID)
+}
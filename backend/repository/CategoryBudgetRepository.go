@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"backend/model"
+	"context"
+
+	"github.com/rs/zerolog/log"
+	"github.com/uptrace/bun"
+)
+
+// CategorySpending is the amount spent in a category, subcategories included,
+// in minor units of the plan's base currency.
+type CategorySpending struct {
+	CategoryID int   `bun:"category_id"`
+	Spent      int64 `bun:"spent"`
+}
+
+type CategoryBudgetRepository interface {
+	Save(budget *model.CategoryBudget) error
+	Delete(planID int, categoryID int) error
+	GetByPlan(planID int) ([]model.CategoryBudget, error)
+	Spending(filter ReportFilter) ([]CategorySpending, error)
+	Unallocated(filter ReportFilter) (int64, error)
+	Total(filter ReportFilter) (int64, error)
+}
+
+type categoryBudgetRepository struct {
+	db *bun.DB
+}
+
+func NewCategoryBudgetRepository(db *bun.DB) CategoryBudgetRepository {
+	log.Info().Msg("Initializing CategoryBudgetRepository")
+	return &categoryBudgetRepository{db: db}
+}
+
+// Save creates the allocation of a category in a plan or replaces its amount.
+func (r *categoryBudgetRepository) Save(budget *model.CategoryBudget) error {
+	log.Info().Int("plan_id", budget.PlanID).Int("category_id", budget.CategoryID).Msg("Saving category budget")
+	ctx := context.Background()
+	err := r.db.NewInsert().
+		Model(budget).
+		On("CONFLICT (plan_id, category_id) DO UPDATE").
+		Set("amount_minor = EXCLUDED.amount_minor").
+		Set("amount_currency = EXCLUDED.amount_currency").
+		Returning("*").
+		Scan(ctx, budget)
+	if err != nil {
+		log.Error().Err(err).Int("plan_id", budget.PlanID).Msg("Failed to save category budget")
+	}
+	return err
+}
+
+// Delete removes the allocation of a category from a plan.
+func (r *categoryBudgetRepository) Delete(planID int, categoryID int) error {
+	log.Info().Int("plan_id", planID).Int("category_id", categoryID).Msg("Deleting category budget")
+	ctx := context.Background()
+	res, err := r.db.NewDelete().
+		Model((*model.CategoryBudget)(nil)).
+		Where("plan_id = ? AND category_id = ?", planID, categoryID).
+		Exec(ctx)
+	return checkAffected(res, err, "Failed to delete category budget", planID)
+}
+
+// GetByPlan lists the allocations of a plan along with their category names.
+func (r *categoryBudgetRepository) GetByPlan(planID int) ([]model.CategoryBudget, error) {
+	log.Info().Int("plan_id", planID).Msg("Fetching category budgets by plan")
+	ctx := context.Background()
+	var budgets []model.CategoryBudget
+	err := r.db.NewSelect().
+		Model(&budgets).
+		ColumnExpr("category_budget.*").
+		ColumnExpr("c.name AS category_name").
+		Join("JOIN category AS c ON c.id = category_budget.category_id").
+		Where("category_budget.plan_id = ?", planID).
+		OrderExpr("lower(c.name)").
+		Scan(ctx)
+	if err != nil {
+		log.Error().Err(err).Int("plan_id", planID).Msg("Failed to fetch category budgets")
+		return nil, err
+	}
+	return budgets, nil
+}
+
+// Spending sums the expenses of the plan per category. Each expense counts
+// toward its own category and toward the parent of that category.
+func (r *categoryBudgetRepository) Spending(filter ReportFilter) ([]CategorySpending, error) {
+	ctx := context.Background()
+	var spending []CategorySpending
+	err := r.expenses(filter).
+		ColumnExpr("k.category_id").
+		ColumnExpr("SUM(expense.base_amount_minor) AS spent").
+		Join("CROSS JOIN LATERAL (VALUES (c.id), (c.parent_id)) AS k (category_id)").
+		Where("k.category_id IS NOT NULL").
+		Group("k.category_id").
+		Scan(ctx, &spending)
+	if err != nil {
+		log.Error().Err(err).Int("plan_id", filter.PlanID).Msg("Failed to sum spending per category")
+		return nil, err
+	}
+	return spending, nil
+}
+
+// Unallocated sums the expenses of the plan whose category, and its parent,
+// have no allocation.
+func (r *categoryBudgetRepository) Unallocated(filter ReportFilter) (int64, error) {
+	ctx := context.Background()
+	allocated := r.db.NewSelect().
+		Model((*model.CategoryBudget)(nil)).
+		ColumnExpr("1").
+		Where("category_budget.plan_id = expense.budget_id").
+		Where("category_budget.category_id IN (c.id, c.parent_id)")
+	var total int64
+	err := r.expenses(filter).
+		ColumnExpr("COALESCE(SUM(expense.base_amount_minor), 0)").
+		Where("NOT EXISTS (?)", allocated).
+		Scan(ctx, &total)
+	if err != nil {
+		log.Error().Err(err).Int("plan_id", filter.PlanID).Msg("Failed to sum unallocated spending")
+	}
+	return total, err
+}
+
+// Total sums every expense of the plan matched by filter.
+func (r *categoryBudgetRepository) Total(filter ReportFilter) (int64, error) {
+	ctx := context.Background()
+	var total int64
+	err := r.expenses(filter).
+		ColumnExpr("COALESCE(SUM(expense.base_amount_minor), 0)").
+		Scan(ctx, &total)
+	if err != nil {
+		log.Error().Err(err).Int("plan_id", filter.PlanID).Msg("Failed to sum spending")
+	}
+	return total, err
+}
+
+// expenses selects the expenses matched by filter joined with their category as c.
+func (r *categoryBudgetRepository) expenses(filter ReportFilter) *bun.SelectQuery {
+	q := r.db.NewSelect().
+		Model((*model.Expense)(nil)).
+		Join("JOIN budget_plan AS bp ON bp.id = expense.budget_id").
+		Join("LEFT JOIN category AS c ON c.id = expense.category_id").
+		Where("bp.id IN (?)", memberPlans(r.db, filter.UserID)).
+		Where("expense.budget_id = ?", filter.PlanID)
+	if !filter.From.IsZero() {
+		q.Where("expense.date >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		q.Where("expense.date < ?", filter.To)
+	}
+	return q
+}
@@ -7,16 +7,21 @@ import (
 	"errors"
 	"github.com/rs/zerolog/log"
 	"github.com/uptrace/bun"
+	"strconv"
 	"strings"
 )
 
 type CategoryRepository interface {
 	Create(category *model.Category) error
 	Update(category *model.Category) error
-	Delete(id int) error
-	FindById(id int) (*model.Category, error)
-	FindAll() ([]model.Category, error)
-	GetByName(name string) (*model.Category, error)
+	Delete(id int, userID int, version int) error
+	FindById(id int, userID int) (*model.Category, error)
+	FindAll(userID int) ([]model.Category, error)
+	List(userID int, filter CategoryFilter, list model.ListQuery) (*model.Page[model.Category], error)
+	GetByName(name string, parentID *int, userID int) (*model.Category, error)
+	IsUsed(id int, userID int) (bool, error)
+	HasChildren(id int, userID int) (bool, error)
+	Seed(userID int) error
 }
 
 type categoryRepository struct {
@@ -30,7 +35,7 @@ func NewCategoryRepository(db *bun.DB) CategoryRepository {
 
 // Create inserts a new Category into the database and returns the created record.
 func (r *categoryRepository) Create(category *model.Category) error {
-	log.Info().Str("name", category.Name).Msg("Creating category")
+	log.Info().Str("name", category.Name).Int("user_id", category.UserID).Msg("Creating category")
 	ctx := context.Background()
 	err := r.db.NewInsert().Model(category).Returning("*").Scan(ctx, category)
 	if err != nil {
@@ -41,11 +46,30 @@ func (r *categoryRepository) Create(category *model.Category) error {
 	return err
 }
 
-// Update modifies an existing Category based on its ID.
+// Update renames or moves a Category owned by category.UserID.
 func (r *categoryRepository) Update(category *model.Category) error {
 	log.Info().Int("id", category.ID).Msg("Updating category")
 	ctx := context.Background()
-	err := r.db.NewUpdate().Model(category).Where("id = ?", category.ID).Scan(ctx)
+	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		res, err := atVersion(tx.NewUpdate().
+			Model(category).
+			Column("name", "parent_id").
+			Where("id = ? AND user_id = ?", category.ID, category.UserID), category.Version).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(res, category.Version); err != nil {
+			return err
+		}
+		// keep the name copied onto expenses in sync
+		_, err = tx.NewUpdate().
+			Model((*model.Expense)(nil)).
+			Set("category_name = ?", category.Name).
+			Where("category_id = ?", category.ID).
+			Exec(ctx)
+		return err
+	})
 	if err != nil {
 		log.Error().Err(err).Int("id", category.ID).Msg("Failed to update category")
 	} else {
@@ -54,25 +78,33 @@ func (r *categoryRepository) Update(category *model.Category) error {
 	return err
 }
 
-// Delete removes a Category by its ID.
-func (r *categoryRepository) Delete(id int) error {
+// Delete removes a Category owned by userID along with its subcategories.
+// A version other than 0 fails with ErrStaleVersion unless the category is
+// still at that version.
+func (r *categoryRepository) Delete(id int, userID int, version int) error {
 	log.Info().Int("id", id).Msg("Deleting category")
 	ctx := context.Background()
-	err := r.db.NewDelete().Model(&model.Category{}).Where("id = ?", id).Scan(ctx)
+	res, err := atVersion(r.db.NewDelete().
+		Model((*model.Category)(nil)).
+		Where("id = ? AND user_id = ?", id, userID), version).
+		Exec(ctx)
+	if err == nil {
+		err = checkVersion(res, version)
+	}
 	if err != nil {
 		log.Error().Err(err).Int("id", id).Msg("Failed to delete category")
-	} else {
-		log.Info().Int("id", id).Msg("Category deleted successfully")
+		return err
 	}
-	return err
+	log.Info().Int("id", id).Msg("Category deleted successfully")
+	return nil
 }
 
-// FindById retrieves a Category by its ID.
-func (r *categoryRepository) FindById(id int) (*model.Category, error) {
+// FindById retrieves a Category owned by userID.
+func (r *categoryRepository) FindById(id int, userID int) (*model.Category, error) {
 	log.Info().Int("id", id).Msg("Fetching category by ID")
 	ctx := context.Background()
 	category := new(model.Category)
-	err := r.db.NewSelect().Model(category).Where("id = ?", id).Scan(ctx, category)
+	err := r.db.NewSelect().Model(category).ColumnExpr("category.*").Where("id = ? AND user_id = ?", id, userID).Scan(ctx)
 	if err != nil {
 		log.Error().Err(err).Int("id", id).Msg("Failed to fetch category by ID")
 		return nil, err
@@ -81,12 +113,16 @@ func (r *categoryRepository) FindById(id int) (*model.Category, error) {
 	return category, nil
 }
 
-// FindAll fetches all categories from the database.
-func (r *categoryRepository) FindAll() ([]model.Category, error) {
-	log.Info().Msg("Fetching all categories")
+// FindAll fetches the categories of userID, top-level ones before their subcategories.
+func (r *categoryRepository) FindAll(userID int) ([]model.Category, error) {
+	log.Info().Int("user_id", userID).Msg("Fetching all categories")
 	ctx := context.Background()
 	var categories []model.Category
-	err := r.db.NewSelect().Model(&categories).Scan(ctx)
+	err := r.db.NewSelect().
+		Model(&categories).
+		Where("user_id = ?", userID).
+		OrderExpr("parent_id NULLS FIRST, lower(name)").
+		Scan(ctx)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to fetch categories")
 	} else {
@@ -95,26 +131,166 @@ func (r *categoryRepository) FindAll() ([]model.Category, error) {
 	return categories, err
 }
 
-// GetByName fetches a Category by its name.
-func (r *categoryRepository) GetByName(name string) (*model.Category, error) {
-	log.Info().Str("name", name).Msg("Fetching category by name")
-	ctx := context.Background()
-	category := new(model.Category)
+// CategoryFilter selects the categories of a user. A ParentID of zero
+// selects the top-level categories, any other the subcategories of that
+// category. Name matches part of the category name, ignoring case.
+type CategoryFilter struct {
+	ParentID *int
+	Name     string
+}
 
-	err := r.db.NewSelect().
-		Model(category).
-		Where("name = ?", name).
-		Scan(ctx)
+// categoryPath is the SQL of the full name of a category, as in "Food > Restaurants".
+const categoryPath = "concat_ws('" + model.CategoryPathSeparator + "', parent.name, category.name)"
+
+// categorySorting orders the lists of Categories.
+var categorySorting = sorting[model.Category]{
+	id:    "category.id",
+	rowID: func(c *model.Category) int { return c.ID },
+	keys: map[string]sortKey[model.Category]{
+		"path": {"lower(" + categoryPath + ")", func(c *model.Category) string { return strings.ToLower(c.Path) }, textKey},
+		"name": {"lower(category.name)", func(c *model.Category) string { return strings.ToLower(c.Name) }, textKey},
+		"id":   {"category.id", func(c *model.Category) string { return strconv.Itoa(c.ID) }, intKey},
+	},
+}
 
+// List fetches a page of the categories of userID matched by filter, along
+// with their paths.
+func (r *categoryRepository) List(userID int, filter CategoryFilter, list model.ListQuery) (*model.Page[model.Category], error) {
+	log.Info().Int("user_id", userID).Msg("Listing categories")
+	ctx := context.Background()
+	var categories []model.Category
+	q := r.db.NewSelect().
+		Model(&categories).
+		ColumnExpr("category.*").
+		ColumnExpr(categoryPath+" AS path").
+		Join("LEFT JOIN category AS parent ON parent.id = category.parent_id").
+		Where("category.user_id = ?", userID)
+	switch {
+	case filter.ParentID == nil:
+	case *filter.ParentID == 0:
+		q = q.Where("category.parent_id IS NULL")
+	default:
+		q = q.Where("category.parent_id = ?", *filter.ParentID)
+	}
+	if filter.Name != "" {
+		q = q.Where("category.name ILIKE ?", "%"+escapeLike(filter.Name)+"%")
+	}
+	q, err := categorySorting.apply(q, list)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) || strings.Contains(err.Error(), "no rows in result set") {
-			log.Warn().Str("name", name).Msg("Category not found")
-			return nil, nil
-		}
-		log.Error().Err(err).Str("name", name).Msg("Failed to fetch category by name")
 		return nil, err
 	}
+	if err := q.Scan(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to list categories")
+		return nil, err
+	}
+	log.Info().Int("count", len(categories)).Msg("Categories listed successfully")
+	return categorySorting.page(categories, list), nil
+}
 
+// GetByName fetches a Category of userID by its name, ignoring case. A nil
+// parentID matches at any level, preferring top-level categories; otherwise
+// only the subcategories of parentID are searched. It returns nil when none matches.
+func (r *categoryRepository) GetByName(name string, parentID *int, userID int) (*model.Category, error) {
+	log.Info().Str("name", name).Msg("Fetching category by name")
+	category, err := categoryByName(context.Background(), r.db, name, parentID, userID)
+	if err != nil {
+		log.Error().Err(err).Str("name", name).Msg("Failed to fetch category by name")
+		return nil, err
+	}
+	if category == nil {
+		log.Warn().Str("name", name).Msg("Category not found")
+		return nil, nil
+	}
 	log.Info().Int("id", category.ID).Str("name", name).Msg("Category fetched successfully")
 	return category, nil
 }
+
+// categoryByName looks up a Category of userID by name within db, which may
+// be a transaction. It returns nil, nil when there is none.
+func categoryByName(ctx context.Context, db bun.IDB, name string, parentID *int, userID int) (*model.Category, error) {
+	category := new(model.Category)
+	q := db.NewSelect().
+		Model(category).
+		Where("user_id = ?", userID).
+		Where("lower(name) = lower(?)", name)
+	if parentID != nil {
+		q = q.Where("parent_id = ?", *parentID)
+	}
+	err := q.OrderExpr("parent_id NULLS FIRST").Limit(1).Scan(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// IsUsed reports whether an Expense or RecurringExpense of userID references
+// the Category or one of its subcategories.
+func (r *categoryRepository) IsUsed(id int, userID int) (bool, error) {
+	log.Info().Int("id", id).Int("user_id", userID).Msg("Checking category usage")
+	ctx := context.Background()
+	subtree := r.db.NewSelect().
+		Model((*model.Category)(nil)).
+		Column("id").
+		Where("(id = ? OR parent_id = ?) AND user_id = ?", id, id, userID)
+
+	used, err := r.db.NewSelect().
+		Model((*model.Expense)(nil)).
+		Where("expense.category_id IN (?)", subtree).
+		Exists(ctx)
+	if err == nil && !used {
+		used, err = r.db.NewSelect().
+			Model((*model.RecurringExpense)(nil)).
+			Where("category_id IN (?)", subtree).
+			Exists(ctx)
+	}
+	if err != nil {
+		log.Error().Err(err).Int("id", id).Msg("Failed to check category usage")
+		return false, err
+	}
+	return used, nil
+}
+
+// HasChildren reports whether the Category has subcategories.
+func (r *categoryRepository) HasChildren(id int, userID int) (bool, error) {
+	ctx := context.Background()
+	found, err := r.db.NewSelect().
+		Model((*model.Category)(nil)).
+		Where("parent_id = ? AND user_id = ?", id, userID).
+		Exists(ctx)
+	if err != nil {
+		log.Error().Err(err).Int("id", id).Msg("Failed to check subcategories")
+	}
+	return found, err
+}
+
+// Seed creates model.DefaultCategories for userID in a single transaction.
+func (r *categoryRepository) Seed(userID int) error {
+	log.Info().Int("user_id", userID).Msg("Seeding default categories")
+	ctx := context.Background()
+	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		for _, def := range model.DefaultCategories {
+			parent := &model.Category{Name: def.Name, UserID: userID}
+			if err := tx.NewInsert().Model(parent).Returning("*").Scan(ctx, parent); err != nil {
+				return err
+			}
+			if len(def.Children) == 0 {
+				continue
+			}
+			children := make([]model.Category, 0, len(def.Children))
+			for _, name := range def.Children {
+				children = append(children, model.Category{Name: name, UserID: userID, ParentID: &parent.ID})
+			}
+			if _, err := tx.NewInsert().Model(&children).Exec(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error().Err(err).Int("user_id", userID).Msg("Failed to seed default categories")
+	}
+	return err
+}
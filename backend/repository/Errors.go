@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/uptrace/bun/driver/pgdriver"
+)
+
+// ErrStaleVersion is returned by writes conditioned on the version of a row
+// when the row was changed since that version was read.
+var ErrStaleVersion = errors.New("the row changed since it was read")
+
+// atVersion conditions a write on the version of its row, unless version is 0.
+func atVersion[Q interface {
+	Where(query string, args ...interface{}) Q
+}](q Q, version int) Q {
+	if version == 0 {
+		return q
+	}
+	return q.Where("version = ?", version)
+}
+
+// checkVersion reports a write that matched no row as ErrStaleVersion when
+// it was conditioned on a version, and as sql.ErrNoRows otherwise.
+func checkVersion(res sql.Result, version int) error {
+	if n, _ := res.RowsAffected(); n == 0 {
+		if version != 0 {
+			return ErrStaleVersion
+		}
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// SQLState returns the SQLSTATE code of an error reported by PostgreSQL,
+// such as "23505" for a unique violation, or "" for any other error.
+func SQLState(err error) string {
+	var pgErr pgdriver.Error
+	if errors.As(err, &pgErr) {
+		return pgErr.Field('C')
+	}
+	return ""
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"backend/model"
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/uptrace/bun"
+)
+
+// ExchangeRateRepository stores the exchange rates entered by hand or imported from files.
+type ExchangeRateRepository interface {
+	Save(rates ...*model.ExchangeRate) error
+	FindLatest(base string, quote string, on time.Time) (*model.ExchangeRate, error)
+	List(base string, quote string) ([]model.ExchangeRate, error)
+}
+
+type exchangeRateRepository struct {
+	db *bun.DB
+}
+
+func NewExchangeRateRepository(db *bun.DB) ExchangeRateRepository {
+	log.Info().Msg("Initializing ExchangeRateRepository")
+	return &exchangeRateRepository{db: db}
+}
+
+// Save upserts rates, replacing any rate already stored for the same pair and date.
+func (r *exchangeRateRepository) Save(rates ...*model.ExchangeRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+	log.Info().Int("count", len(rates)).Msg("Saving exchange rates")
+	ctx := context.Background()
+	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		for _, rate := range rates {
+			if err := tx.NewInsert().
+				Model(rate).
+				On("CONFLICT (base, quote, date) DO UPDATE").
+				Set("rate = EXCLUDED.rate").
+				Set("source = EXCLUDED.source").
+				Returning("*").
+				Scan(ctx, rate); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to save exchange rates")
+	} else {
+		log.Info().Int("count", len(rates)).Msg("Exchange rates saved successfully")
+	}
+	return err
+}
+
+// FindLatest returns the most recent rate from base to quote published on or before on.
+func (r *exchangeRateRepository) FindLatest(base string, quote string, on time.Time) (*model.ExchangeRate, error) {
+	log.Debug().Str("base", base).Str("quote", quote).Time("on", on).Msg("Fetching exchange rate")
+	ctx := context.Background()
+	rate := new(model.ExchangeRate)
+	err := r.db.NewSelect().
+		Model(rate).
+		Where("base = ? AND quote = ?", base, quote).
+		Where("date <= ?", on).
+		Order("date DESC").
+		Limit(1).
+		Scan(ctx)
+	if err != nil {
+		log.Debug().Err(err).Str("base", base).Str("quote", quote).Msg("Exchange rate not found")
+		return nil, err
+	}
+	return rate, nil
+}
+
+// List returns the stored rates, optionally filtered by base and quote currency.
+func (r *exchangeRateRepository) List(base string, quote string) ([]model.ExchangeRate, error) {
+	log.Info().Str("base", base).Str("quote", quote).Msg("Listing exchange rates")
+	ctx := context.Background()
+	var rates []model.ExchangeRate
+	q := r.db.NewSelect().Model(&rates).Order("date DESC", "base", "quote")
+	if base != "" {
+		q.Where("base = ?", base)
+	}
+	if quote != "" {
+		q.Where("quote = ?", quote)
+	}
+	err := q.Scan(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list exchange rates")
+	}
+	return rates, err
+}
@@ -3,16 +3,28 @@ package repository
 import (
 	"backend/model"
 	"context"
+	"database/sql"
+	"errors"
 	"github.com/rs/zerolog/log"
 	"github.com/uptrace/bun"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type ExpensesRepository interface {
 	Create(expense *model.Expense) error
-	Update(expense *model.Expense) error
-	Delete(id int) error
-	GetByPlan(id int) ([]model.Expense, error)
-	GetByCategory(id int) ([]model.Expense, error)
+	CreateOccurrence(expense *model.Expense) (bool, error)
+	CreateBatch(expenses []*model.Expense) error
+	Update(expense *model.Expense, splits []model.ExpenseSplit) error
+	SetCategory(id int, categoryID int, categoryName string) error
+	Delete(id int, version int) error
+	GetByID(id int, userID int) (*model.Expense, error)
+	GetByPlan(id int, userID int, filter ExpenseFilter, list model.ListQuery) (*model.Page[model.Expense], error)
+	GetByCategory(id int, userID int, list model.ListQuery) (*model.Page[model.Expense], error)
+	Search(search model.ExpenseSearch, userID int) ([]model.Expense, error)
+	GetByPlanBetween(id int, userID int, from time.Time, to time.Time) ([]model.Expense, error)
+	EachByPlan(id int, userID int, from time.Time, to time.Time, fn func(expense *model.Expense) error) error
 }
 
 type expensesRepository struct {
@@ -24,34 +36,122 @@ func NewExpensesRepository(db *bun.DB) ExpensesRepository {
 	return &expensesRepository{db: db}
 }
 
-// Create inserts a new Expense into the database and links it to a BudgetPlan.
+// Create inserts a new Expense into the database, links it to a BudgetPlan
+// and tags it with its Tags, in a single transaction.
 func (r *expensesRepository) Create(expense *model.Expense) error {
 	log.Info().Str("description", expense.Description).Int("budget_id", expense.BudgetID).Msg("Creating expense and linking to budget plan")
 	ctx := context.Background()
 
-	if err := r.db.NewInsert().Model(expense).Returning("*").Scan(ctx, expense); err != nil {
+	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		return insertExpense(ctx, tx, expense)
+	})
+	if err != nil {
 		log.Error().Err(err).Msg("Failed to create expense")
+	} else {
+		log.Info().Int("expense_id", expense.ID).Int("budget_plan_id", expense.BudgetID).Msg("Expense linked to budget plan successfully")
+	}
+	return err
+}
+
+// CreateOccurrence inserts an Expense materialized from a RecurringExpense and links it to
+// its BudgetPlan. It reports false when that occurrence already exists.
+func (r *expensesRepository) CreateOccurrence(expense *model.Expense) (bool, error) {
+	log.Info().Int("budget_id", expense.BudgetID).Msg("Creating recurring expense occurrence")
+	ctx := context.Background()
+	created := false
+	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		err := tx.NewInsert().
+			Model(expense).
+			On("CONFLICT (recurring_expense_id, occurrence_date) DO NOTHING").
+			Returning("*").
+			Scan(ctx, expense)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		created = true
+		link := &model.BudgetPlanExpense{BudgetPlanID: expense.BudgetID, ExpenseID: expense.ID}
+		_, err = tx.NewInsert().Model(link).Exec(ctx)
 		return err
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to create recurring expense occurrence")
+	} else if created {
+		log.Info().Int("expense_id", expense.ID).Msg("Recurring expense occurrence created")
 	}
+	return created, err
+}
 
-	link := &model.BudgetPlanExpense{
-		BudgetPlanID: expense.BudgetID,
-		ExpenseID:    expense.ID,
+// CreateBatch inserts several Expenses with their BudgetPlan links and tags
+// in a single transaction, so either every expense is stored or none is.
+func (r *expensesRepository) CreateBatch(expenses []*model.Expense) error {
+	log.Info().Int("count", len(expenses)).Msg("Creating expenses in batch")
+	if len(expenses) == 0 {
+		return nil
 	}
-	_, err := r.db.NewInsert().Model(link).Exec(ctx)
+	ctx := context.Background()
+	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		for _, expense := range expenses {
+			if err := insertExpense(ctx, tx, expense); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to link expense to budget plan")
+		log.Error().Err(err).Msg("Failed to create expenses in batch")
 	} else {
-		log.Info().Int("expense_id", expense.ID).Int("budget_plan_id", expense.BudgetID).Msg("Expense linked to budget plan successfully")
+		log.Info().Int("count", len(expenses)).Msg("Expenses created in batch successfully")
 	}
 	return err
 }
 
-// Update modifies an existing Expense based on its ID.
-func (r *expensesRepository) Update(expense *model.Expense) error {
+// insertExpense inserts an expense, its BudgetPlan link and its tags within tx.
+func insertExpense(ctx context.Context, tx bun.Tx, expense *model.Expense) error {
+	tags := expense.Tags
+	if err := tx.NewInsert().Model(expense).Returning("*").Scan(ctx, expense); err != nil {
+		return err
+	}
+	link := &model.BudgetPlanExpense{
+		BudgetPlanID: expense.BudgetID,
+		ExpenseID:    expense.ID,
+	}
+	if _, err := tx.NewInsert().Model(link).Exec(ctx); err != nil {
+		return err
+	}
+	expense.Tags = tags
+	if len(tags) == 0 {
+		return nil
+	}
+	return setExpenseTags(ctx, tx, expense.ID, expense.BudgetID, tags)
+}
+
+// Update modifies an existing Expense based on its ID. Non-nil Tags replace
+// its tags and non-nil splits replace its shares, split by its SplitMethod,
+// in the same transaction.
+func (r *expensesRepository) Update(expense *model.Expense, splits []model.ExpenseSplit) error {
 	log.Info().Int("id", expense.ID).Msg("Updating expense")
 	ctx := context.Background()
-	_, err := r.db.NewUpdate().Model(expense).Column("amount", "description", "category_name", "date", "is_recurring").Where("id = ?", expense.ID).Exec(ctx)
+	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		res, err := atVersion(tx.NewUpdate().Model(expense).Column("amount_minor", "amount_currency", "description", "category_id", "category_name", "date", "is_recurring", "paid_by", "exchange_rate", "base_amount_minor", "base_amount_currency").Where("id = ?", expense.ID), expense.Version).Exec(ctx)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(res, expense.Version); err != nil {
+			return err
+		}
+		if expense.Tags != nil {
+			if err := setExpenseTags(ctx, tx, expense.ID, expense.BudgetID, expense.Tags); err != nil {
+				return err
+			}
+		}
+		if splits != nil && expense.SplitMethod != nil {
+			return setSplits(ctx, tx, expense.ID, *expense.SplitMethod, splits)
+		}
+		return nil
+	})
 	if err != nil {
 		log.Error().Err(err).Int("id", expense.ID).Msg("Failed to update expense")
 	} else {
@@ -60,24 +160,42 @@ func (r *expensesRepository) Update(expense *model.Expense) error {
 	return err
 }
 
-// Delete removes an Expense and its association from the BudgetPlanExpense table.
-func (r *expensesRepository) Delete(id int) error {
-	log.Info().Int("id", id).Msg("Deleting expense and removing budget plan association")
+// SetCategory moves an Expense to another category.
+func (r *expensesRepository) SetCategory(id int, categoryID int, categoryName string) error {
+	log.Info().Int("id", id).Int("category_id", categoryID).Msg("Recategorizing expense")
 	ctx := context.Background()
-
-	_, err := r.db.NewDelete().
-		Model((*model.BudgetPlanExpense)(nil)).
-		Where("expense_id = ?", id).
-		Exec(ctx)
-	if err != nil {
-		log.Error().Err(err).Int("id", id).Msg("Failed to delete expense association from BudgetPlanExpense")
-		return err
-	}
-
-	_, err = r.db.NewDelete().
-		Model(&model.Expense{}).
+	res, err := r.db.NewUpdate().
+		Model((*model.Expense)(nil)).
+		Set("category_id = ?", categoryID).
+		Set("category_name = ?", categoryName).
 		Where("id = ?", id).
 		Exec(ctx)
+	return checkAffected(res, err, "Failed to recategorize expense", id)
+}
+
+// Delete removes an Expense and its association from the BudgetPlanExpense
+// table. A version other than 0 fails with ErrStaleVersion unless the
+// expense is still at that version.
+func (r *expensesRepository) Delete(id int, version int) error {
+	log.Info().Int("id", id).Msg("Deleting expense and removing budget plan association")
+	ctx := context.Background()
+
+	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.NewDelete().
+			Model((*model.BudgetPlanExpense)(nil)).
+			Where("expense_id = ?", id).
+			Exec(ctx); err != nil {
+			return err
+		}
+		res, err := atVersion(tx.NewDelete().
+			Model(&model.Expense{}).
+			Where("id = ?", id), version).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		return checkVersion(res, version)
+	})
 	if err != nil {
 		log.Error().Err(err).Int("id", id).Msg("Failed to delete expense")
 	} else {
@@ -86,30 +204,224 @@ func (r *expensesRepository) Delete(id int) error {
 	return err
 }
 
-// GetByPlan retrieves all Expenses associated with a specific BudgetPlan ID.
-func (r *expensesRepository) GetByPlan(id int) ([]model.Expense, error) {
-	log.Info().Int("budget_id", id).Msg("Fetching expenses by budget plan")
+// visibleTo restricts an Expense query to rows of the BudgetPlans userID is a member of.
+func visibleTo(q *bun.SelectQuery, userID int) *bun.SelectQuery {
+	return q.Join("JOIN budget_plan AS bp ON bp.id = expense.budget_id").
+		Join("JOIN plan_members AS pm ON pm.plan_id = bp.id AND pm.user_id = ?", userID)
+}
+
+// GetByID retrieves an Expense by its ID if its BudgetPlan is visible to userID.
+func (r *expensesRepository) GetByID(id int, userID int) (*model.Expense, error) {
+	log.Info().Int("id", id).Int("user_id", userID).Msg("Fetching expense by ID")
+	ctx := context.Background()
+	expense := new(model.Expense)
+	err := visibleTo(withTags(r.db.NewSelect().Model(expense)), userID).
+		Where("expense.id = ?", id).
+		Scan(ctx)
+	if err != nil {
+		log.Error().Err(err).Int("id", id).Int("user_id", userID).Msg("Failed to fetch expense by ID")
+		return nil, err
+	}
+	log.Info().Int("id", id).Msg("Expense fetched successfully")
+	return expense, nil
+}
+
+// ExpenseFilter selects the expenses of a plan. Zero dates leave the
+// corresponding bound open and To is exclusive. Amounts are minor units of
+// the plan's base currency, compared to the base amount of each expense.
+type ExpenseFilter struct {
+	From       time.Time
+	To         time.Time
+	CategoryID int
+	MinAmount  *int64
+	MaxAmount  *int64
+	PaidBy     int
+	Recurring  *bool
+	Tags       model.TagFilter
+}
+
+// expenseSorting orders the lists of Expenses.
+var expenseSorting = sorting[model.Expense]{
+	id:    "expense.id",
+	rowID: func(e *model.Expense) int { return e.ID },
+	keys: map[string]sortKey[model.Expense]{
+		"date":        {"expense.date", func(e *model.Expense) string { return e.Date.Format(time.RFC3339Nano) }, timeKey},
+		"amount":      {"expense.base_amount_minor", func(e *model.Expense) string { return strconv.FormatInt(e.BaseAmount.Minor, 10) }, intKey},
+		"description": {"lower(expense.description)", func(e *model.Expense) string { return strings.ToLower(e.Description) }, textKey},
+		"id":          {"expense.id", func(e *model.Expense) string { return strconv.Itoa(e.ID) }, intKey},
+	},
+}
+
+// GetByPlan retrieves a page of the Expenses associated with a specific
+// BudgetPlan ID visible to userID and matched by filter.
+func (r *expensesRepository) GetByPlan(id int, userID int, filter ExpenseFilter, list model.ListQuery) (*model.Page[model.Expense], error) {
+	log.Info().Int("budget_id", id).Int("user_id", userID).Msg("Fetching expenses by budget plan")
 	ctx := context.Background()
 	var expenses []model.Expense
-	err := r.db.NewSelect().Model(&expenses).Where("budget_id = ?", id).Scan(ctx)
+	q := visibleTo(withTags(r.db.NewSelect().Model(&expenses)), userID).
+		Where("expense.budget_id = ?", id)
+	if !filter.From.IsZero() {
+		q = q.Where("expense.date >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		q = q.Where("expense.date < ?", filter.To)
+	}
+	if filter.CategoryID != 0 {
+		subtree := r.db.NewSelect().
+			Model((*model.Category)(nil)).
+			Column("id").
+			Where("id = ? OR parent_id = ?", filter.CategoryID, filter.CategoryID)
+		q = q.Where("expense.category_id IN (?)", subtree)
+	}
+	if filter.MinAmount != nil {
+		q = q.Where("expense.base_amount_minor >= ?", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		q = q.Where("expense.base_amount_minor <= ?", *filter.MaxAmount)
+	}
+	if filter.PaidBy != 0 {
+		q = q.Where("expense.paid_by = ?", filter.PaidBy)
+	}
+	if filter.Recurring != nil {
+		q = q.Where("expense.is_recurring = ?", *filter.Recurring)
+	}
+	q, err := expenseSorting.apply(withTagFilter(q, filter.Tags), list)
 	if err != nil {
+		return nil, err
+	}
+	if err := q.Scan(ctx); err != nil {
 		log.Error().Err(err).Int("budget_id", id).Msg("Failed to fetch expenses by budget plan")
-	} else {
-		log.Info().Int("budget_id", id).Int("count", len(expenses)).Msg("Expenses fetched by budget plan")
+		return nil, err
 	}
-	return expenses, err
+	log.Info().Int("budget_id", id).Int("count", len(expenses)).Msg("Expenses fetched by budget plan")
+	return expenseSorting.page(expenses, list), nil
 }
 
-// GetByCategory retrieves all Expenses that belong to a specific Category ID.
-func (r *expensesRepository) GetByCategory(id int) ([]model.Expense, error) {
-	log.Info().Int("category_id", id).Msg("Fetching expenses by category")
+// GetByCategory retrieves a page of the Expenses of a specific Category ID,
+// or of one of its subcategories, across the plans visible to userID.
+func (r *expensesRepository) GetByCategory(id int, userID int, list model.ListQuery) (*model.Page[model.Expense], error) {
+	log.Info().Int("category_id", id).Int("user_id", userID).Msg("Fetching expenses by category")
 	ctx := context.Background()
 	var expenses []model.Expense
-	err := r.db.NewSelect().Model(&expenses).Where("categoryID = ?", id).Scan(ctx)
+	subtree := r.db.NewSelect().
+		Model((*model.Category)(nil)).
+		Column("id").
+		Where("id = ? OR parent_id = ?", id, id)
+	q, err := expenseSorting.apply(visibleTo(withTags(r.db.NewSelect().Model(&expenses)), userID).
+		Where("expense.category_id IN (?)", subtree), list)
 	if err != nil {
+		return nil, err
+	}
+	if err := q.Scan(ctx); err != nil {
 		log.Error().Err(err).Int("category_id", id).Msg("Failed to fetch expenses by category")
 		return nil, err
 	}
 	log.Info().Int("category_id", id).Int("count", len(expenses)).Msg("Expenses fetched by category")
+	return expenseSorting.page(expenses, list), nil
+}
+
+// Search runs a full-text search over the Expenses visible to userID and
+// returns up to search.Limit of them after search.After, best match first.
+func (r *expensesRepository) Search(search model.ExpenseSearch, userID int) ([]model.Expense, error) {
+	log.Info().Int("user_id", userID).Int("budget_id", search.PlanID).Msg("Searching expenses")
+	ctx := context.Background()
+	var expenses []model.Expense
+	q := visibleTo(withTags(r.db.NewSelect().Model(&expenses)), userID)
+	rank := bun.Safe("0::real")
+	if search.Query != "" {
+		q = q.Join("JOIN expense_search AS es ON es.expense_id = expense.id").
+			Join("CROSS JOIN websearch_to_tsquery('simple', ?) AS query", search.Query).
+			Where("es.document @@ query")
+		rank = bun.Safe("ts_rank_cd(es.document, query)")
+	}
+	q = q.ColumnExpr("? AS rank", rank)
+	if search.PlanID != 0 {
+		q = q.Where("expense.budget_id = ?", search.PlanID)
+	}
+	if search.CategoryID != 0 {
+		subtree := r.db.NewSelect().
+			Model((*model.Category)(nil)).
+			Column("id").
+			Where("id = ? OR parent_id = ?", search.CategoryID, search.CategoryID)
+		q = q.Where("expense.category_id IN (?)", subtree)
+	}
+	if search.From != nil {
+		q = q.Where("expense.date >= ?", *search.From)
+	}
+	if search.To != nil {
+		q = q.Where("expense.date < ?", search.To.AddDate(0, 0, 1))
+	}
+	// compare the original amount when it is in the currency, the base amount otherwise
+	const amount = "(CASE WHEN expense.amount_currency = ? THEN expense.amount_minor " +
+		"WHEN expense.base_amount_currency = ? THEN expense.base_amount_minor END)"
+	if search.MinAmount != nil {
+		q = q.Where(amount+" >= ?", search.Currency, search.Currency, search.MinAmount.Minor)
+	}
+	if search.MaxAmount != nil {
+		q = q.Where(amount+" <= ?", search.Currency, search.Currency, search.MaxAmount.Minor)
+	}
+	if c := search.After; c != nil {
+		q = q.Where("(?, expense.date, expense.id) < (?::real, ?, ?)", rank, c.Rank, c.Date, c.ID)
+	}
+	err := q.OrderExpr("rank DESC, expense.date DESC, expense.id DESC").
+		Limit(search.Limit).
+		Scan(ctx)
+	if err != nil {
+		log.Error().Err(err).Int("user_id", userID).Msg("Failed to search expenses")
+		return nil, err
+	}
+	log.Info().Int("user_id", userID).Int("count", len(expenses)).Msg("Expenses searched")
+	return expenses, nil
+}
+
+// GetByPlanBetween retrieves the Expenses of a BudgetPlan visible to userID dated
+// between from and to, both inclusive.
+func (r *expensesRepository) GetByPlanBetween(id int, userID int, from time.Time, to time.Time) ([]model.Expense, error) {
+	log.Info().Int("budget_id", id).Time("from", from).Time("to", to).Msg("Fetching expenses by budget plan and date range")
+	ctx := context.Background()
+	var expenses []model.Expense
+	err := visibleTo(withTags(r.db.NewSelect().Model(&expenses)), userID).
+		Where("expense.budget_id = ?", id).
+		Where("expense.date >= ?", from).
+		Where("expense.date <= ?", to).
+		Scan(ctx)
+	if err != nil {
+		log.Error().Err(err).Int("budget_id", id).Msg("Failed to fetch expenses by budget plan and date range")
+		return nil, err
+	}
 	return expenses, nil
 }
+
+// EachByPlan streams the Expenses of a BudgetPlan visible to userID to fn, ordered
+// by date, without loading them all in memory. Zero bounds are ignored and to
+// is exclusive. Iteration stops at the first error returned by fn.
+func (r *expensesRepository) EachByPlan(id int, userID int, from time.Time, to time.Time, fn func(expense *model.Expense) error) error {
+	log.Info().Int("budget_id", id).Int("user_id", userID).Msg("Streaming expenses by budget plan")
+	ctx := context.Background()
+	q := visibleTo(r.db.NewSelect().Model((*model.Expense)(nil)), userID).
+		Where("expense.budget_id = ?", id).
+		Order("expense.date", "expense.id")
+	if !from.IsZero() {
+		q = q.Where("expense.date >= ?", from)
+	}
+	if !to.IsZero() {
+		q = q.Where("expense.date < ?", to)
+	}
+
+	rows, err := q.Rows(ctx)
+	if err != nil {
+		log.Error().Err(err).Int("budget_id", id).Msg("Failed to stream expenses by budget plan")
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		expense := new(model.Expense)
+		if err := r.db.ScanRow(ctx, rows, expense); err != nil {
+			return err
+		}
+		if err := fn(expense); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
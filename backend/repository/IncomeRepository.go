@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"backend/model"
+	"context"
+
+	"github.com/rs/zerolog/log"
+	"github.com/uptrace/bun"
+)
+
+type IncomeRepository interface {
+	Create(income *model.Income) error
+	Update(income *model.Income) error
+	Delete(id int) error
+	GetByID(id int, userID int) (*model.Income, error)
+	GetByPlan(filter ReportFilter) ([]model.Income, error)
+	Totals(filter ReportFilter) (income int64, expenses int64, err error)
+}
+
+type incomeRepository struct {
+	db *bun.DB
+}
+
+func NewIncomeRepository(db *bun.DB) IncomeRepository {
+	log.Info().Msg("Initializing IncomeRepository")
+	return &incomeRepository{db: db}
+}
+
+func (r *incomeRepository) Create(income *model.Income) error {
+	log.Info().Int("budget_id", income.BudgetID).Msg("Creating income")
+	ctx := context.Background()
+	err := r.db.NewInsert().Model(income).Returning("*").Scan(ctx, income)
+	if err != nil {
+		log.Error().Err(err).Int("budget_id", income.BudgetID).Msg("Failed to create income")
+	}
+	return err
+}
+
+func (r *incomeRepository) Update(income *model.Income) error {
+	log.Info().Int("id", income.ID).Msg("Updating income")
+	ctx := context.Background()
+	res, err := r.db.NewUpdate().
+		Model(income).
+		Column("description", "source", "amount_minor", "amount_currency", "date", "exchange_rate", "base_amount_minor", "base_amount_currency").
+		Where("id = ?", income.ID).
+		Exec(ctx)
+	return checkAffected(res, err, "Failed to update income", income.ID)
+}
+
+func (r *incomeRepository) Delete(id int) error {
+	log.Info().Int("id", id).Msg("Deleting income")
+	ctx := context.Background()
+	res, err := r.db.NewDelete().Model((*model.Income)(nil)).Where("id = ?", id).Exec(ctx)
+	return checkAffected(res, err, "Failed to delete income", id)
+}
+
+// GetByID retrieves an Income of a BudgetPlan userID is a member of.
+func (r *incomeRepository) GetByID(id int, userID int) (*model.Income, error) {
+	ctx := context.Background()
+	income := new(model.Income)
+	err := r.db.NewSelect().
+		Model(income).
+		Join("JOIN budget_plan AS bp ON bp.id = income.budget_id").
+		Where("income.id = ? AND bp.id IN (?)", id, memberPlans(r.db, userID)).
+		Scan(ctx)
+	if err != nil {
+		log.Error().Err(err).Int("id", id).Msg("Failed to fetch income")
+		return nil, err
+	}
+	return income, nil
+}
+
+// GetByPlan lists the incomes of the plan matched by filter, ordered by date.
+func (r *incomeRepository) GetByPlan(filter ReportFilter) ([]model.Income, error) {
+	ctx := context.Background()
+	var incomes []model.Income
+	q := r.db.NewSelect().
+		Model(&incomes).
+		Join("JOIN budget_plan AS bp ON bp.id = income.budget_id").
+		Where("bp.id IN (?)", memberPlans(r.db, filter.UserID)).
+		Where("income.budget_id = ?", filter.PlanID).
+		Order("income.date", "income.id")
+	if !filter.From.IsZero() {
+		q.Where("income.date >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		q.Where("income.date < ?", filter.To)
+	}
+	if err := q.Scan(ctx); err != nil {
+		log.Error().Err(err).Int("budget_id", filter.PlanID).Msg("Failed to fetch incomes by plan")
+		return nil, err
+	}
+	return incomes, nil
+}
+
+// Totals sums the incomes and the expenses of the plan matched by filter, in
+// minor units of the plan's base currency.
+func (r *incomeRepository) Totals(filter ReportFilter) (int64, int64, error) {
+	ctx := context.Background()
+	sum := func(model interface{}, alias string) *bun.SelectQuery {
+		q := r.db.NewSelect().
+			Model(model).
+			ColumnExpr("COALESCE(SUM(?.base_amount_minor), 0)", bun.Ident(alias)).
+			Where("?.budget_id = ?", bun.Ident(alias), filter.PlanID)
+		if !filter.From.IsZero() {
+			q.Where("?.date >= ?", bun.Ident(alias), filter.From)
+		}
+		if !filter.To.IsZero() {
+			q.Where("?.date < ?", bun.Ident(alias), filter.To)
+		}
+		return q
+	}
+	var totals struct {
+		Income   int64 `bun:"income"`
+		Expenses int64 `bun:"expenses"`
+	}
+	err := r.db.NewSelect().
+		ColumnExpr("(?) AS income", sum((*model.Income)(nil), "income")).
+		ColumnExpr("(?) AS expenses", sum((*model.Expense)(nil), "expense")).
+		TableExpr("budget_plan AS bp").
+		Where("bp.id = ? AND bp.id IN (?)", filter.PlanID, memberPlans(r.db, filter.UserID)).
+		Scan(ctx, &totals)
+	if err != nil {
+		log.Error().Err(err).Int("budget_id", filter.PlanID).Msg("Failed to sum plan balance")
+	}
+	return totals.Income, totals.Expenses, err
+}
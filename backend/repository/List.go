@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"backend/model"
+	"fmt"
+	"github.com/uptrace/bun"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sortKey is a field a list can be sorted by: the SQL expression it orders
+// by, the value of that expression for a row, stored in cursors, and the
+// check a value read back from a cursor must pass.
+type sortKey[T any] struct {
+	expr  string
+	key   func(row *T) string
+	valid func(key string) bool
+}
+
+// intKey, timeKey and textKey check the values of sort keys of each type,
+// so that a forged cursor is rejected before it reaches the database.
+func intKey(key string) bool {
+	_, err := strconv.ParseInt(key, 10, 64)
+	return err == nil
+}
+
+func timeKey(key string) bool {
+	_, err := time.Parse(time.RFC3339Nano, key)
+	return err == nil
+}
+
+func textKey(string) bool {
+	return true
+}
+
+// sorting describes how the rows of a list are ordered and paginated. Rows
+// with the same sort key are ordered by their ID column, so that every row
+// has a stable position a cursor can point to.
+type sorting[T any] struct {
+	id    string
+	rowID func(row *T) int
+	keys  map[string]sortKey[T]
+}
+
+// apply orders q by list.Sort, skips the rows up to list.Cursor and fetches
+// one row more than the page so that page can tell whether another page
+// follows. list must have been resolved, so that it has a Limit.
+func (s sorting[T]) apply(q *bun.SelectQuery, list model.ListQuery) (*bun.SelectQuery, error) {
+	if list.Limit <= 0 {
+		return nil, fmt.Errorf("list query with limit %d was not resolved", list.Limit)
+	}
+	field, desc := list.Field()
+	k, ok := s.keys[field]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", model.ErrInvalidSort, list.Sort)
+	}
+	dir, cmp := "ASC", ">"
+	if desc {
+		dir, cmp = "DESC", "<"
+	}
+	if c := list.Cursor; c != nil {
+		if !k.valid(c.Key) {
+			return nil, fmt.Errorf("%w: its key does not fit sort %q", model.ErrInvalidCursor, list.Sort)
+		}
+		q = q.Where("(?, ?) "+cmp+" (?, ?)", bun.Safe(k.expr), bun.Safe(s.id), c.Key, c.ID)
+	}
+	q = q.OrderExpr("? "+dir+", ? "+dir, bun.Safe(k.expr), bun.Safe(s.id))
+	q = q.Limit(list.Limit + 1)
+	return q, nil
+}
+
+// page cuts the rows fetched by a query built with apply to the page size
+// and points the cursor of the page to its last row.
+func (s sorting[T]) page(rows []T, list model.ListQuery) *model.Page[T] {
+	page := &model.Page[T]{Items: rows}
+	if len(rows) <= list.Limit {
+		return page
+	}
+	page.Items = rows[:list.Limit]
+	last := &page.Items[list.Limit-1]
+	field, _ := list.Field()
+	page.NextCursor = model.Cursor{Sort: list.Sort, Key: s.keys[field].key(last), ID: s.rowID(last)}.Encode()
+	return page
+}
+
+// likeEscaper escapes the wildcards of a LIKE pattern.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike makes s match literally inside a LIKE pattern.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
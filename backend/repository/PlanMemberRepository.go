@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"backend/model"
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/uptrace/bun"
+)
+
+type PlanMemberRepository interface {
+	GetByPlan(planID int) ([]model.PlanMember, error)
+	Get(planID int, userID int) (*model.PlanMember, error)
+	UpdateRole(planID int, userID int, role model.PlanRole) error
+	Remove(planID int, userID int) error
+	CreateInvitation(invitation *model.PlanInvitation) error
+	GetInvitation(id int) (*model.PlanInvitation, error)
+	GetInvitationsByPlan(planID int) ([]model.PlanInvitation, error)
+	PendingInvitations(email string) ([]model.PlanInvitation, error)
+	Respond(invitation *model.PlanInvitation, status model.InvitationStatus, userID int) error
+}
+
+type planMemberRepository struct {
+	db *bun.DB
+}
+
+func NewPlanMemberRepository(db *bun.DB) PlanMemberRepository {
+	log.Info().Msg("Initializing PlanMemberRepository")
+	return &planMemberRepository{db: db}
+}
+
+// memberPlans selects the IDs of the plans userID is a member of.
+func memberPlans(db bun.IDB, userID int) *bun.SelectQuery {
+	return db.NewSelect().
+		TableExpr("plan_members AS pm").
+		Column("pm.plan_id").
+		Where("pm.user_id = ?", userID)
+}
+
+// GetByPlan lists the members of a plan with their names and emails, owner first.
+func (r *planMemberRepository) GetByPlan(planID int) ([]model.PlanMember, error) {
+	ctx := context.Background()
+	var members []model.PlanMember
+	err := r.db.NewSelect().
+		Model(&members).
+		ColumnExpr("plan_member.*").
+		ColumnExpr("u.name, u.email").
+		Join("JOIN users AS u ON u.id = plan_member.user_id").
+		Where("plan_member.plan_id = ?", planID).
+		OrderExpr("plan_member.role = ? DESC, plan_member.joined_at", model.RoleOwner).
+		Scan(ctx)
+	if err != nil {
+		log.Error().Err(err).Int("plan_id", planID).Msg("Failed to fetch plan members")
+		return nil, err
+	}
+	return members, nil
+}
+
+func (r *planMemberRepository) Get(planID int, userID int) (*model.PlanMember, error) {
+	ctx := context.Background()
+	member := new(model.PlanMember)
+	err := r.db.NewSelect().
+		Model(member).
+		Where("plan_id = ? AND user_id = ?", planID, userID).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+func (r *planMemberRepository) UpdateRole(planID int, userID int, role model.PlanRole) error {
+	log.Info().Int("plan_id", planID).Int("user_id", userID).Str("role", string(role)).Msg("Updating plan member role")
+	ctx := context.Background()
+	res, err := r.db.NewUpdate().
+		Model((*model.PlanMember)(nil)).
+		Set("role = ?", role).
+		Where("plan_id = ? AND user_id = ?", planID, userID).
+		Exec(ctx)
+	return checkAffected(res, err, "Failed to update plan member role", planID)
+}
+
+func (r *planMemberRepository) Remove(planID int, userID int) error {
+	log.Info().Int("plan_id", planID).Int("user_id", userID).Msg("Removing plan member")
+	ctx := context.Background()
+	res, err := r.db.NewDelete().
+		Model((*model.PlanMember)(nil)).
+		Where("plan_id = ? AND user_id = ?", planID, userID).
+		Exec(ctx)
+	return checkAffected(res, err, "Failed to remove plan member", planID)
+}
+
+func (r *planMemberRepository) CreateInvitation(invitation *model.PlanInvitation) error {
+	log.Info().Int("plan_id", invitation.PlanID).Msg("Creating plan invitation")
+	ctx := context.Background()
+	err := r.db.NewInsert().Model(invitation).Returning("*").Scan(ctx, invitation)
+	if err != nil {
+		log.Error().Err(err).Int("plan_id", invitation.PlanID).Msg("Failed to create plan invitation")
+	}
+	return err
+}
+
+// GetInvitation fetches an invitation along with the name of its plan.
+func (r *planMemberRepository) GetInvitation(id int) (*model.PlanInvitation, error) {
+	ctx := context.Background()
+	invitation := new(model.PlanInvitation)
+	err := r.invitations(r.db.NewSelect().Model(invitation)).
+		Where("plan_invitation.id = ?", id).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+// GetInvitationsByPlan lists the pending invitations of a plan.
+func (r *planMemberRepository) GetInvitationsByPlan(planID int) ([]model.PlanInvitation, error) {
+	ctx := context.Background()
+	var invitations []model.PlanInvitation
+	err := r.invitations(r.db.NewSelect().Model(&invitations)).
+		Where("plan_invitation.plan_id = ?", planID).
+		Where("plan_invitation.status = ?", model.InvitationPending).
+		Order("plan_invitation.id").
+		Scan(ctx)
+	if err != nil {
+		log.Error().Err(err).Int("plan_id", planID).Msg("Failed to fetch plan invitations")
+		return nil, err
+	}
+	return invitations, nil
+}
+
+// PendingInvitations lists the invitations waiting for an answer from email.
+func (r *planMemberRepository) PendingInvitations(email string) ([]model.PlanInvitation, error) {
+	ctx := context.Background()
+	var invitations []model.PlanInvitation
+	err := r.invitations(r.db.NewSelect().Model(&invitations)).
+		Where("lower(plan_invitation.email) = lower(?)", email).
+		Where("plan_invitation.status = ?", model.InvitationPending).
+		Order("plan_invitation.id").
+		Scan(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to fetch pending plan invitations")
+		return nil, err
+	}
+	return invitations, nil
+}
+
+// Respond closes a pending invitation with status. Accepting it adds userID
+// to the plan with the invited role in the same transaction. It returns
+// sql.ErrNoRows when the invitation is no longer pending.
+func (r *planMemberRepository) Respond(invitation *model.PlanInvitation, status model.InvitationStatus, userID int) error {
+	log.Info().Int("id", invitation.ID).Str("status", string(status)).Msg("Responding to plan invitation")
+	ctx := context.Background()
+	now := time.Now()
+	return r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		res, err := tx.NewUpdate().
+			Model((*model.PlanInvitation)(nil)).
+			Set("status = ?", status).
+			Set("responded_at = ?", now).
+			Where("id = ? AND status = ?", invitation.ID, model.InvitationPending).
+			Exec(ctx)
+		if err := checkAffected(res, err, "Failed to respond to plan invitation", invitation.ID); err != nil {
+			return err
+		}
+		invitation.Status, invitation.RespondedAt = status, &now
+		if status != model.InvitationAccepted {
+			return nil
+		}
+		_, err = tx.NewInsert().
+			Model(&model.PlanMember{
+				PlanID:    invitation.PlanID,
+				UserID:    userID,
+				Role:      invitation.Role,
+				InvitedBy: invitation.InvitedBy,
+			}).
+			On("CONFLICT (plan_id, user_id) DO NOTHING").
+			Exec(ctx)
+		return err
+	})
+}
+
+// invitations adds the plan name to an invitation query.
+func (r *planMemberRepository) invitations(q *bun.SelectQuery) *bun.SelectQuery {
+	return q.ColumnExpr("plan_invitation.*").
+		ColumnExpr("bp.name AS plan_name").
+		Join("JOIN budget_plan AS bp ON bp.id = plan_invitation.plan_id")
+}
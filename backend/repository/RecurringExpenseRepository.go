@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"backend/model"
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/uptrace/bun"
+)
+
+type RecurringExpenseRepository interface {
+	Create(recurring *model.RecurringExpense) error
+	Update(recurring *model.RecurringExpense) error
+	Delete(id int, userID int) error
+	GetByID(id int, userID int) (*model.RecurringExpense, error)
+	GetByPlan(planID int, userID int) ([]model.RecurringExpense, error)
+	FindDue(day time.Time) ([]model.RecurringExpense, error)
+	SetPaused(id int, userID int, paused bool, next *time.Time) error
+	AddSkippedDate(id int, userID int, day time.Time) error
+	SetNextOccurrence(id int, next *time.Time) error
+}
+
+type recurringExpenseRepository struct {
+	db *bun.DB
+}
+
+func NewRecurringExpenseRepository(db *bun.DB) RecurringExpenseRepository {
+	log.Info().Msg("Initializing RecurringExpenseRepository")
+	return &recurringExpenseRepository{db: db}
+}
+
+// Create inserts a new RecurringExpense and returns the created record.
+func (r *recurringExpenseRepository) Create(recurring *model.RecurringExpense) error {
+	log.Info().Int("budget_id", recurring.BudgetID).Str("frequency", string(recurring.Frequency)).Msg("Creating recurring expense")
+	ctx := context.Background()
+	err := r.db.NewInsert().Model(recurring).Returning("*").Scan(ctx, recurring)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to create recurring expense")
+	} else {
+		log.Info().Int("id", recurring.ID).Msg("Recurring expense created successfully")
+	}
+	return err
+}
+
+// Update replaces the template and rule of a RecurringExpense owned by recurring.UserID.
+func (r *recurringExpenseRepository) Update(recurring *model.RecurringExpense) error {
+	log.Info().Int("id", recurring.ID).Msg("Updating recurring expense")
+	ctx := context.Background()
+	res, err := r.db.NewUpdate().
+		Model(recurring).
+		Column("category_id", "category_name", "description", "amount_minor", "amount_currency",
+			"frequency", "interval_count", "by_day", "by_month_day", "count",
+			"start_date", "end_date", "next_occurrence").
+		Where("id = ? AND user_id = ?", recurring.ID, recurring.UserID).
+		Exec(ctx)
+	return checkAffected(res, err, "Failed to update recurring expense", recurring.ID)
+}
+
+// Delete removes a RecurringExpense owned by userID. Materialized expenses are kept.
+func (r *recurringExpenseRepository) Delete(id int, userID int) error {
+	log.Info().Int("id", id).Msg("Deleting recurring expense")
+	ctx := context.Background()
+	res, err := r.db.NewDelete().
+		Model((*model.RecurringExpense)(nil)).
+		Where("id = ? AND user_id = ?", id, userID).
+		Exec(ctx)
+	return checkAffected(res, err, "Failed to delete recurring expense", id)
+}
+
+// GetByID retrieves a RecurringExpense owned by userID.
+func (r *recurringExpenseRepository) GetByID(id int, userID int) (*model.RecurringExpense, error) {
+	log.Info().Int("id", id).Int("user_id", userID).Msg("Fetching recurring expense by ID")
+	ctx := context.Background()
+	recurring := new(model.RecurringExpense)
+	err := r.db.NewSelect().Model(recurring).Where("id = ? AND user_id = ?", id, userID).Scan(ctx)
+	if err != nil {
+		log.Error().Err(err).Int("id", id).Msg("Failed to fetch recurring expense by ID")
+		return nil, err
+	}
+	return recurring, nil
+}
+
+// GetByPlan retrieves the RecurringExpenses of a BudgetPlan userID is a
+// member of, whoever created them.
+func (r *recurringExpenseRepository) GetByPlan(planID int, userID int) ([]model.RecurringExpense, error) {
+	log.Info().Int("budget_id", planID).Int("user_id", userID).Msg("Fetching recurring expenses by budget plan")
+	ctx := context.Background()
+	var recurring []model.RecurringExpense
+	err := r.db.NewSelect().
+		Model(&recurring).
+		Where("budget_id = ? AND budget_id IN (?)", planID, memberPlans(r.db, userID)).
+		Order("id").
+		Scan(ctx)
+	if err != nil {
+		log.Error().Err(err).Int("budget_id", planID).Msg("Failed to fetch recurring expenses by budget plan")
+	}
+	return recurring, err
+}
+
+// FindDue returns the active RecurringExpenses with an occurrence on or before day.
+func (r *recurringExpenseRepository) FindDue(day time.Time) ([]model.RecurringExpense, error) {
+	ctx := context.Background()
+	var recurring []model.RecurringExpense
+	err := r.db.NewSelect().
+		Model(&recurring).
+		Where("NOT paused").
+		Where("next_occurrence IS NOT NULL AND next_occurrence <= ?", day).
+		Order("id").
+		Scan(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to fetch due recurring expenses")
+	}
+	return recurring, err
+}
+
+// SetPaused pauses or resumes a RecurringExpense owned by userID. Resuming
+// also stores next as its next occurrence, in the same statement, so that the
+// scheduler never sees it active with a stale one.
+func (r *recurringExpenseRepository) SetPaused(id int, userID int, paused bool, next *time.Time) error {
+	log.Info().Int("id", id).Bool("paused", paused).Msg("Setting recurring expense paused state")
+	ctx := context.Background()
+	q := r.db.NewUpdate().
+		Model((*model.RecurringExpense)(nil)).
+		Set("paused = ?", paused)
+	if !paused {
+		q = q.Set("next_occurrence = ?", next)
+	}
+	res, err := q.
+		Where("id = ? AND user_id = ?", id, userID).
+		Exec(ctx)
+	return checkAffected(res, err, "Failed to set recurring expense paused state", id)
+}
+
+// AddSkippedDate records an occurrence that must not be materialized.
+func (r *recurringExpenseRepository) AddSkippedDate(id int, userID int, day time.Time) error {
+	log.Info().Int("id", id).Time("day", day).Msg("Skipping recurring expense occurrence")
+	ctx := context.Background()
+	_, err := r.db.NewUpdate().
+		Model((*model.RecurringExpense)(nil)).
+		Set("skipped_dates = array_append(skipped_dates, ?::date)", day).
+		Where("id = ? AND user_id = ?", id, userID).
+		Where("NOT (?::date = ANY(skipped_dates))", day).
+		Exec(ctx)
+	if err != nil {
+		log.Error().Err(err).Int("id", id).Msg("Failed to skip recurring expense occurrence")
+	}
+	return err
+}
+
+// SetNextOccurrence stores the next day the scheduler has to materialize, nil once the rule ended.
+func (r *recurringExpenseRepository) SetNextOccurrence(id int, next *time.Time) error {
+	ctx := context.Background()
+	_, err := r.db.NewUpdate().
+		Model((*model.RecurringExpense)(nil)).
+		Set("next_occurrence = ?", next).
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		log.Error().Err(err).Int("id", id).Msg("Failed to advance recurring expense")
+	}
+	return err
+}
+
+// checkAffected logs err and turns an update or delete that matched no row into sql.ErrNoRows.
+func checkAffected(res sql.Result, err error, msg string, id int) error {
+	if err != nil {
+		log.Error().Err(err).Int("id", id).Msg(msg)
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		log.Warn().Int("id", id).Msg("No row matched")
+		return sql.ErrNoRows
+	}
+	return nil
+}
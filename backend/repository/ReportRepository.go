@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"backend/model"
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/uptrace/bun"
+)
+
+// ReportFilter selects the expenses of a plan UserID is a member of. Zero dates and
+// a nil Recurring leave the corresponding bound open.
+type ReportFilter struct {
+	PlanID    int
+	UserID    int
+	From      time.Time
+	To        time.Time
+	Recurring *bool
+}
+
+// ReportGroup is a single aggregated bucket, with Total in minor units of the
+// plan's base currency. Sub names the subcategory of Key the bucket belongs to,
+// if any, so that callers can roll subcategories up to their parent.
+type ReportGroup struct {
+	Key   string `bun:"key"`
+	Sub   string `bun:"sub"`
+	Total int64  `bun:"total"`
+	Count int    `bun:"count"`
+}
+
+type ReportRepository interface {
+	ByCategory(filter ReportFilter) ([]ReportGroup, error)
+	ByPeriod(filter ReportFilter, period string) ([]ReportGroup, error)
+	ByRecurrence(filter ReportFilter) ([]ReportGroup, error)
+	ByTag(filter ReportFilter) ([]ReportGroup, error)
+}
+
+type reportRepository struct {
+	db *bun.DB
+}
+
+func NewReportRepository(db *bun.DB) ReportRepository {
+	log.Info().Msg("Initializing ReportRepository")
+	return &reportRepository{db: db}
+}
+
+// ByCategory sums expenses per top-level category and subcategory, largest first.
+// Expenses of a subcategory are keyed by their parent's name with Sub set.
+func (r *reportRepository) ByCategory(filter ReportFilter) ([]ReportGroup, error) {
+	return r.aggregate("ByCategory", filter, func(q *bun.SelectQuery) *bun.SelectQuery {
+		return q.Join("LEFT JOIN category AS c ON c.id = expense.category_id").
+			Join("LEFT JOIN category AS p ON p.id = c.parent_id").
+			ColumnExpr("COALESCE(p.name, c.name, expense.category_name, '') AS key").
+			ColumnExpr("CASE WHEN p.id IS NOT NULL THEN c.name ELSE '' END AS sub").
+			Group("key", "sub").
+			OrderExpr("total DESC")
+	})
+}
+
+// ByPeriod sums expenses per day, week or month, in chronological order.
+func (r *reportRepository) ByPeriod(filter ReportFilter, period string) ([]ReportGroup, error) {
+	return r.aggregate("ByPeriod", filter, func(q *bun.SelectQuery) *bun.SelectQuery {
+		return q.ColumnExpr("to_char(date_trunc(?, expense.date), 'YYYY-MM-DD') AS key", period).
+			Group("key").
+			OrderExpr("key ASC")
+	})
+}
+
+// ByRecurrence splits expenses between recurring and one-off ones.
+func (r *reportRepository) ByRecurrence(filter ReportFilter) ([]ReportGroup, error) {
+	return r.aggregate("ByRecurrence", filter, func(q *bun.SelectQuery) *bun.SelectQuery {
+		return q.ColumnExpr("CASE WHEN expense.is_recurring THEN 'recurring' ELSE 'one-off' END AS key").
+			Group("key").
+			OrderExpr("key ASC")
+	})
+}
+
+// ByTag sums expenses per tag, largest first. An expense counts towards each of
+// its tags and untagged expenses are keyed by an empty name.
+func (r *reportRepository) ByTag(filter ReportFilter) ([]ReportGroup, error) {
+	return r.aggregate("ByTag", filter, func(q *bun.SelectQuery) *bun.SelectQuery {
+		return q.Join("LEFT JOIN expense_tags AS et ON et.expense_id = expense.id").
+			Join("LEFT JOIN tags AS t ON t.id = et.tag_id").
+			ColumnExpr("COALESCE(t.name, '') AS key").
+			Group("key").
+			OrderExpr("total DESC")
+	})
+}
+
+// aggregate runs the grouping built by group over the expenses matched by filter.
+func (r *reportRepository) aggregate(method string, filter ReportFilter, group func(q *bun.SelectQuery) *bun.SelectQuery) ([]ReportGroup, error) {
+	ctx := context.Background()
+	logger := log.With().Str("component", "ReportRepository").Str("method", method).
+		Int("budget_plan_id", filter.PlanID).Int("user_id", filter.UserID).Logger()
+	logger.Info().Msg("Aggregating expenses")
+
+	q := r.db.NewSelect().
+		Model((*model.Expense)(nil)).
+		ColumnExpr("SUM(expense.base_amount_minor) AS total").
+		ColumnExpr("COUNT(*) AS count").
+		Join("JOIN budget_plan AS bp ON bp.id = expense.budget_id").
+		Where("bp.id IN (?)", memberPlans(r.db, filter.UserID)).
+		Where("expense.budget_id = ?", filter.PlanID)
+	if !filter.From.IsZero() {
+		q.Where("expense.date >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		q.Where("expense.date < ?", filter.To)
+	}
+	if filter.Recurring != nil {
+		q.Where("expense.is_recurring = ?", *filter.Recurring)
+	}
+
+	var groups []ReportGroup
+	if err := group(q).Scan(ctx, &groups); err != nil {
+		logger.Error().Err(err).Msg("Failed to aggregate expenses")
+		return nil, err
+	}
+	logger.Info().Int("groups", len(groups)).Msg("Expenses aggregated successfully")
+	return groups, nil
+}
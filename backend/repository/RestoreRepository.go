@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"backend/model"
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/uptrace/bun"
+)
+
+type RestoreRepository interface {
+	Restore(userID int, doc *model.ExportDocument) (*model.RestoreResult, error)
+}
+
+type restoreRepository struct {
+	db *bun.DB
+}
+
+func NewRestoreRepository(db *bun.DB) RestoreRepository {
+	log.Info().Msg("Initializing RestoreRepository")
+	return &restoreRepository{db: db}
+}
+
+// Restore writes the categories, plans and expenses of doc for userID in a
+// single transaction, so that a failure leaves nothing behind. The IDs of doc
+// are those of the export: its rows get new ones, and expenses are moved to
+// the rows created for the plans and categories they refer to. Parent
+// categories must come before their subcategories. Categories are matched by
+// name and only created when missing.
+func (r *restoreRepository) Restore(userID int, doc *model.ExportDocument) (*model.RestoreResult, error) {
+	log.Info().Int("user_id", userID).Int("plans", len(doc.Plans)).Int("expenses", len(doc.Expenses)).Msg("Restoring export")
+	ctx := context.Background()
+	result := &model.RestoreResult{}
+	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		categories := make(map[int]*model.Category, len(doc.Categories))
+		for _, c := range doc.Categories {
+			var parentID *int
+			if c.ParentID != nil {
+				parent, ok := categories[*c.ParentID]
+				if !ok {
+					return fmt.Errorf("category %d refers to unknown parent %d", c.ID, *c.ParentID)
+				}
+				parentID = &parent.ID
+			}
+			category, err := categoryByName(ctx, tx, c.Name, parentID, userID)
+			if err != nil {
+				return err
+			}
+			// a subcategory of the same name does not stand for a top-level one
+			if category != nil && parentID == nil && category.ParentID != nil {
+				category = nil
+			}
+			if category == nil {
+				category = &model.Category{Name: c.Name, UserID: userID, ParentID: parentID}
+				if err := tx.NewInsert().Model(category).Returning("*").Scan(ctx, category); err != nil {
+					return err
+				}
+				result.Categories++
+			}
+			categories[c.ID] = category
+		}
+
+		plans := make(map[int]*model.BudgetPlan, len(doc.Plans))
+		for i := range doc.Plans {
+			plan := doc.Plans[i]
+			exportedID := plan.ID
+			plan.ID = 0
+			plan.UserID = userID
+			if err := insertPlan(ctx, tx, &plan); err != nil {
+				return err
+			}
+			plans[exportedID] = &plan
+			result.Plans++
+		}
+
+		for i := range doc.Expenses {
+			expense := doc.Expenses[i]
+			plan, ok := plans[expense.BudgetID]
+			if !ok {
+				return fmt.Errorf("expense %d refers to unknown plan %d", expense.ID, expense.BudgetID)
+			}
+			category, ok := categories[expense.CategoryID]
+			if !ok {
+				return fmt.Errorf("expense %d refers to unknown category %d", expense.ID, expense.CategoryID)
+			}
+			expense.ID = 0
+			expense.BudgetID = plan.ID
+			expense.CategoryID = category.ID
+			expense.CategoryName = category.Name
+			if err := insertExpense(ctx, tx, &expense); err != nil {
+				return err
+			}
+			result.Expenses++
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error().Err(err).Int("user_id", userID).Msg("Failed to restore export")
+		return nil, err
+	}
+	log.Info().Int("user_id", userID).Int("plans", result.Plans).Int("expenses", result.Expenses).Msg("Export restored successfully")
+	return result, nil
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"backend/model"
+	"context"
+
+	"github.com/rs/zerolog/log"
+	"github.com/uptrace/bun"
+)
+
+type RuleRepository interface {
+	Create(rule *model.ExpenseRule) error
+	Update(rule *model.ExpenseRule) error
+	Delete(id int, userID int) error
+	GetByID(id int, userID int) (*model.ExpenseRule, error)
+	GetByUser(userID int) ([]model.ExpenseRule, error)
+	ForPlan(userID int, planID int) ([]model.ExpenseRule, error)
+}
+
+type ruleRepository struct {
+	db *bun.DB
+}
+
+func NewRuleRepository(db *bun.DB) RuleRepository {
+	log.Info().Msg("Initializing RuleRepository")
+	return &ruleRepository{db: db}
+}
+
+func (r *ruleRepository) Create(rule *model.ExpenseRule) error {
+	log.Info().Int("user_id", rule.UserID).Str("name", rule.Name).Msg("Creating expense rule")
+	ctx := context.Background()
+	err := r.db.NewInsert().Model(rule).Returning("*").Scan(ctx, rule)
+	if err != nil {
+		log.Error().Err(err).Int("user_id", rule.UserID).Msg("Failed to create expense rule")
+	}
+	return err
+}
+
+func (r *ruleRepository) Update(rule *model.ExpenseRule) error {
+	log.Info().Int("id", rule.ID).Msg("Updating expense rule")
+	ctx := context.Background()
+	res, err := r.db.NewUpdate().
+		Model(rule).
+		Column("plan_id", "name", "priority", "enabled", "description_pattern", "min_amount", "max_amount",
+			"currency", "date_from", "date_to", "category_id", "tags").
+		Where("id = ? AND user_id = ?", rule.ID, rule.UserID).
+		Exec(ctx)
+	return checkAffected(res, err, "Failed to update expense rule", rule.ID)
+}
+
+func (r *ruleRepository) Delete(id int, userID int) error {
+	log.Info().Int("id", id).Msg("Deleting expense rule")
+	ctx := context.Background()
+	res, err := r.db.NewDelete().
+		Model((*model.ExpenseRule)(nil)).
+		Where("id = ? AND user_id = ?", id, userID).
+		Exec(ctx)
+	return checkAffected(res, err, "Failed to delete expense rule", id)
+}
+
+func (r *ruleRepository) GetByID(id int, userID int) (*model.ExpenseRule, error) {
+	ctx := context.Background()
+	rule := new(model.ExpenseRule)
+	err := r.rules(r.db.NewSelect().Model(rule)).
+		Where("expense_rule.id = ? AND expense_rule.user_id = ?", id, userID).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// GetByUser lists the rules of a user in the order they run.
+func (r *ruleRepository) GetByUser(userID int) ([]model.ExpenseRule, error) {
+	ctx := context.Background()
+	var rules []model.ExpenseRule
+	err := r.rules(r.db.NewSelect().Model(&rules)).
+		Where("expense_rule.user_id = ?", userID).
+		OrderExpr("expense_rule.priority, expense_rule.id").
+		Scan(ctx)
+	if err != nil {
+		log.Error().Err(err).Int("user_id", userID).Msg("Failed to fetch expense rules")
+		return nil, err
+	}
+	return rules, nil
+}
+
+// ForPlan lists the enabled rules of a user that apply to a plan.
+func (r *ruleRepository) ForPlan(userID int, planID int) ([]model.ExpenseRule, error) {
+	ctx := context.Background()
+	var rules []model.ExpenseRule
+	err := r.rules(r.db.NewSelect().Model(&rules)).
+		Where("expense_rule.user_id = ? AND expense_rule.enabled", userID).
+		Where("expense_rule.plan_id IS NULL OR expense_rule.plan_id = ?", planID).
+		OrderExpr("expense_rule.priority, expense_rule.id").
+		Scan(ctx)
+	if err != nil {
+		log.Error().Err(err).Int("user_id", userID).Int("plan_id", planID).Msg("Failed to fetch expense rules for plan")
+		return nil, err
+	}
+	return rules, nil
+}
+
+// rules selects the rules along with the name of their category.
+func (r *ruleRepository) rules(q *bun.SelectQuery) *bun.SelectQuery {
+	return q.ColumnExpr("expense_rule.*").
+		ColumnExpr("c.name AS category_name").
+		Join("LEFT JOIN category AS c ON c.id = expense_rule.category_id")
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"backend/model"
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/uptrace/bun"
+)
+
+type SessionRepository interface {
+	Create(session *model.Session) error
+	FindByTokenHash(hash string) (*model.Session, error)
+	Rotate(old *model.Session, next *model.Session) error
+	Revoke(id int, userID int) error
+	RevokeAll(userID int) error
+	IsActive(id int, email string, version int) (bool, error)
+}
+
+type sessionRepository struct {
+	db *bun.DB
+}
+
+func NewSessionRepository(db *bun.DB) SessionRepository {
+	log.Info().Msg("Initializing SessionRepository")
+	return &sessionRepository{db: db}
+}
+
+// Create inserts a new Session and returns the created record.
+func (r *sessionRepository) Create(session *model.Session) error {
+	log.Info().Int("user_id", session.UserID).Msg("Creating session")
+	ctx := context.Background()
+	err := r.db.NewInsert().Model(session).Returning("*").Scan(ctx, session)
+	if err != nil {
+		log.Error().Err(err).Int("user_id", session.UserID).Msg("Failed to create session")
+	}
+	return err
+}
+
+// FindByTokenHash retrieves a Session, revoked or not, by the hash of its refresh token.
+func (r *sessionRepository) FindByTokenHash(hash string) (*model.Session, error) {
+	ctx := context.Background()
+	session := new(model.Session)
+	err := r.db.NewSelect().Model(session).Where("token_hash = ?", hash).Scan(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to find session by token")
+		return nil, err
+	}
+	return session, nil
+}
+
+// Rotate revokes old and inserts next as its replacement in a single transaction.
+// It returns sql.ErrNoRows when old was revoked concurrently, so that a refresh
+// token can only ever be exchanged once.
+func (r *sessionRepository) Rotate(old *model.Session, next *model.Session) error {
+	log.Info().Int("id", old.ID).Int("user_id", old.UserID).Msg("Rotating session")
+	ctx := context.Background()
+	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if err := tx.NewInsert().Model(next).Returning("*").Scan(ctx, next); err != nil {
+			return err
+		}
+		now := time.Now()
+		res, err := tx.NewUpdate().
+			Model((*model.Session)(nil)).
+			Set("revoked_at = ?", now).
+			Set("last_used_at = ?", now).
+			Set("replaced_by = ?", next.ID).
+			Where("id = ? AND revoked_at IS NULL", old.ID).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return sql.ErrNoRows
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error().Err(err).Int("id", old.ID).Msg("Failed to rotate session")
+	}
+	return err
+}
+
+// Revoke ends a Session owned by userID.
+func (r *sessionRepository) Revoke(id int, userID int) error {
+	log.Info().Int("id", id).Int("user_id", userID).Msg("Revoking session")
+	ctx := context.Background()
+	res, err := r.db.NewUpdate().
+		Model((*model.Session)(nil)).
+		Set("revoked_at = ?", time.Now()).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Exec(ctx)
+	return checkAffected(res, err, "Failed to revoke session", id)
+}
+
+// RevokeAll ends every active Session of userID.
+func (r *sessionRepository) RevokeAll(userID int) error {
+	log.Info().Int("user_id", userID).Msg("Revoking all sessions")
+	ctx := context.Background()
+	_, err := r.db.NewUpdate().
+		Model((*model.Session)(nil)).
+		Set("revoked_at = ?", time.Now()).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Exec(ctx)
+	if err != nil {
+		log.Error().Err(err).Int("user_id", userID).Msg("Failed to revoke all sessions")
+	}
+	return err
+}
+
+// IsActive reports whether an access token issued for the session id, the user
+// email and the token version is still valid: the session is neither revoked
+// nor expired and the user's token version has not been bumped since.
+func (r *sessionRepository) IsActive(id int, email string, version int) (bool, error) {
+	ctx := context.Background()
+	active, err := r.db.NewSelect().
+		Model((*model.Session)(nil)).
+		Join("JOIN users AS u ON u.id = session.user_id").
+		Where("session.id = ?", id).
+		Where("u.email = ?", email).
+		Where("u.token_version = ?", version).
+		Where("session.token_version = u.token_version").
+		Where("session.revoked_at IS NULL").
+		Where("session.expires_at > ?", time.Now()).
+		Exists(ctx)
+	if err != nil {
+		log.Error().Err(err).Int("id", id).Msg("Failed to check session")
+	}
+	return active, err
+}
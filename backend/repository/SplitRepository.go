@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"backend/model"
+	"context"
+
+	"github.com/rs/zerolog/log"
+	"github.com/uptrace/bun"
+)
+
+type SplitRepository interface {
+	SetSplits(expenseID int, method model.SplitMethod, splits []model.ExpenseSplit) error
+	ClearSplits(expenseID int) error
+	GetByExpense(expenseID int) ([]model.ExpenseSplit, error)
+	CreatePayment(payment *model.SettlementPayment) error
+	DeletePayment(id int) error
+	GetPayment(id int) (*model.SettlementPayment, error)
+	GetPayments(planID int) ([]model.SettlementPayment, error)
+	Paid(planID int) ([]UserAmount, error)
+	Owed(planID int) ([]UserAmount, error)
+	Sent(planID int) ([]UserAmount, error)
+	Received(planID int) ([]UserAmount, error)
+	Involves(userID int) (bool, error)
+}
+
+// UserAmount is a total in minor units of a plan's base currency, per user.
+type UserAmount struct {
+	UserID int   `bun:"user_id"`
+	Amount int64 `bun:"amount"`
+}
+
+type splitRepository struct {
+	db *bun.DB
+}
+
+func NewSplitRepository(db *bun.DB) SplitRepository {
+	log.Info().Msg("Initializing SplitRepository")
+	return &splitRepository{db: db}
+}
+
+// SetSplits replaces the shares of an expense and records how it was split.
+func (r *splitRepository) SetSplits(expenseID int, method model.SplitMethod, splits []model.ExpenseSplit) error {
+	log.Info().Int("expense_id", expenseID).Str("method", string(method)).Msg("Splitting expense")
+	ctx := context.Background()
+	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		return setSplits(ctx, tx, expenseID, method, splits)
+	})
+	if err != nil {
+		log.Error().Err(err).Int("expense_id", expenseID).Msg("Failed to split expense")
+	}
+	return err
+}
+
+// setSplits replaces the shares of an expense within tx, so that other
+// repositories can split an expense in the transaction that writes it.
+func setSplits(ctx context.Context, tx bun.Tx, expenseID int, method model.SplitMethod, splits []model.ExpenseSplit) error {
+	if _, err := tx.NewDelete().Model((*model.ExpenseSplit)(nil)).Where("expense_id = ?", expenseID).Exec(ctx); err != nil {
+		return err
+	}
+	for i := range splits {
+		splits[i].ExpenseID = expenseID
+	}
+	if _, err := tx.NewInsert().Model(&splits).Exec(ctx); err != nil {
+		return err
+	}
+	res, err := tx.NewUpdate().
+		Model((*model.Expense)(nil)).
+		Set("split_method = ?", method).
+		Where("id = ?", expenseID).
+		Exec(ctx)
+	return checkAffected(res, err, "Failed to set expense split method", expenseID)
+}
+
+// ClearSplits removes the shares of an expense, which stops being shared.
+func (r *splitRepository) ClearSplits(expenseID int) error {
+	log.Info().Int("expense_id", expenseID).Msg("Clearing expense splits")
+	ctx := context.Background()
+	return r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.NewDelete().Model((*model.ExpenseSplit)(nil)).Where("expense_id = ?", expenseID).Exec(ctx); err != nil {
+			log.Error().Err(err).Int("expense_id", expenseID).Msg("Failed to clear expense splits")
+			return err
+		}
+		res, err := tx.NewUpdate().
+			Model((*model.Expense)(nil)).
+			Set("split_method = NULL").
+			Where("id = ?", expenseID).
+			Exec(ctx)
+		return checkAffected(res, err, "Failed to clear expense split method", expenseID)
+	})
+}
+
+// GetByExpense lists the shares of an expense with the names of their users.
+func (r *splitRepository) GetByExpense(expenseID int) ([]model.ExpenseSplit, error) {
+	ctx := context.Background()
+	var splits []model.ExpenseSplit
+	err := r.db.NewSelect().
+		Model(&splits).
+		ColumnExpr("expense_split.*").
+		ColumnExpr("u.name").
+		Join("JOIN users AS u ON u.id = expense_split.user_id").
+		Where("expense_split.expense_id = ?", expenseID).
+		OrderExpr("expense_split.user_id").
+		Scan(ctx)
+	if err != nil {
+		log.Error().Err(err).Int("expense_id", expenseID).Msg("Failed to fetch expense splits")
+		return nil, err
+	}
+	return splits, nil
+}
+
+func (r *splitRepository) CreatePayment(payment *model.SettlementPayment) error {
+	log.Info().Int("plan_id", payment.PlanID).Msg("Recording settlement payment")
+	ctx := context.Background()
+	err := r.db.NewInsert().Model(payment).Returning("*").Scan(ctx, payment)
+	if err != nil {
+		log.Error().Err(err).Int("plan_id", payment.PlanID).Msg("Failed to record settlement payment")
+	}
+	return err
+}
+
+func (r *splitRepository) DeletePayment(id int) error {
+	log.Info().Int("id", id).Msg("Deleting settlement payment")
+	ctx := context.Background()
+	res, err := r.db.NewDelete().Model((*model.SettlementPayment)(nil)).Where("id = ?", id).Exec(ctx)
+	return checkAffected(res, err, "Failed to delete settlement payment", id)
+}
+
+func (r *splitRepository) GetPayment(id int) (*model.SettlementPayment, error) {
+	ctx := context.Background()
+	payment := new(model.SettlementPayment)
+	if err := r.db.NewSelect().Model(payment).Where("id = ?", id).Scan(ctx); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// GetPayments lists the settlement payments of a plan, newest first.
+func (r *splitRepository) GetPayments(planID int) ([]model.SettlementPayment, error) {
+	ctx := context.Background()
+	var payments []model.SettlementPayment
+	err := r.db.NewSelect().
+		Model(&payments).
+		Where("plan_id = ?", planID).
+		OrderExpr("date DESC, id DESC").
+		Scan(ctx)
+	if err != nil {
+		log.Error().Err(err).Int("plan_id", planID).Msg("Failed to fetch settlement payments")
+		return nil, err
+	}
+	return payments, nil
+}
+
+// Involves reports whether userID paid a split expense or holds a share of one.
+func (r *splitRepository) Involves(userID int) (bool, error) {
+	ctx := context.Background()
+	shares := r.db.NewSelect().
+		Model((*model.ExpenseSplit)(nil)).
+		Where("expense_split.user_id = ?", userID)
+	paid := r.db.NewSelect().
+		Model((*model.Expense)(nil)).
+		Where("expense.paid_by = ? AND expense.split_method IS NOT NULL", userID)
+	involved, err := shares.Exists(ctx)
+	if err == nil && !involved {
+		involved, err = paid.Exists(ctx)
+	}
+	if err != nil {
+		log.Error().Err(err).Int("user_id", userID).Msg("Failed to check the splits of user")
+	}
+	return involved, err
+}
+
+// Paid sums the split expenses of a plan by the member who paid them.
+func (r *splitRepository) Paid(planID int) ([]UserAmount, error) {
+	shared := r.db.NewSelect().
+		TableExpr("expense_splits AS s").
+		ColumnExpr("1").
+		Where("s.expense_id = expense.id")
+	q := r.db.NewSelect().
+		Model((*model.Expense)(nil)).
+		ColumnExpr("expense.paid_by AS user_id").
+		ColumnExpr("SUM(expense.base_amount_minor) AS amount").
+		Where("expense.budget_id = ?", planID).
+		Where("expense.paid_by IS NOT NULL").
+		Where("EXISTS (?)", shared).
+		GroupExpr("expense.paid_by")
+	return r.sum(q, planID, "paid")
+}
+
+// Owed sums the shares of the split expenses of a plan by member.
+func (r *splitRepository) Owed(planID int) ([]UserAmount, error) {
+	q := r.db.NewSelect().
+		Model((*model.ExpenseSplit)(nil)).
+		ColumnExpr("expense_split.user_id").
+		ColumnExpr("SUM(expense_split.amount_minor) AS amount").
+		Join("JOIN expenses AS e ON e.id = expense_split.expense_id").
+		Where("e.budget_id = ?", planID).
+		GroupExpr("expense_split.user_id")
+	return r.sum(q, planID, "owed")
+}
+
+// Sent sums the settlement payments of a plan by the member who made them.
+func (r *splitRepository) Sent(planID int) ([]UserAmount, error) {
+	q := r.db.NewSelect().
+		Model((*model.SettlementPayment)(nil)).
+		ColumnExpr("from_user_id AS user_id").
+		ColumnExpr("SUM(amount_minor) AS amount").
+		Where("plan_id = ?", planID).
+		GroupExpr("from_user_id")
+	return r.sum(q, planID, "sent")
+}
+
+// Received sums the settlement payments of a plan by the member who got them.
+func (r *splitRepository) Received(planID int) ([]UserAmount, error) {
+	q := r.db.NewSelect().
+		Model((*model.SettlementPayment)(nil)).
+		ColumnExpr("to_user_id AS user_id").
+		ColumnExpr("SUM(amount_minor) AS amount").
+		Where("plan_id = ?", planID).
+		GroupExpr("to_user_id")
+	return r.sum(q, planID, "received")
+}
+
+func (r *splitRepository) sum(q *bun.SelectQuery, planID int, what string) ([]UserAmount, error) {
+	var totals []UserAmount
+	if err := q.Scan(context.Background(), &totals); err != nil {
+		log.Error().Err(err).Int("plan_id", planID).Str("total", what).Msg("Failed to sum settlement totals")
+		return nil, err
+	}
+	return totals, nil
+}
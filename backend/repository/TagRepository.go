@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"backend/model"
+	"context"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/uptrace/bun"
+)
+
+type TagRepository interface {
+	GetByPlan(planID int) ([]model.Tag, error)
+	GetByID(id int, planID int) (*model.Tag, error)
+	GetByName(name string, planID int) (*model.Tag, error)
+	Rename(tag *model.Tag) error
+	Merge(planID int, sourceIDs []int, targetID int) error
+	Delete(id int, planID int) error
+	SetExpenseTags(expenseID int, planID int, names []string) error
+}
+
+type tagRepository struct {
+	db *bun.DB
+}
+
+func NewTagRepository(db *bun.DB) TagRepository {
+	log.Info().Msg("Initializing TagRepository")
+	return &tagRepository{db: db}
+}
+
+// GetByPlan lists the tags of a plan by name with the number of expenses using each.
+func (r *tagRepository) GetByPlan(planID int) ([]model.Tag, error) {
+	ctx := context.Background()
+	var tags []model.Tag
+	usage := r.db.NewSelect().
+		Model((*model.ExpenseTag)(nil)).
+		ColumnExpr("COUNT(*)").
+		Where("expense_tag.tag_id = tag.id")
+	err := r.db.NewSelect().
+		Model(&tags).
+		ColumnExpr("tag.*").
+		ColumnExpr("(?) AS usage", usage).
+		Where("tag.plan_id = ?", planID).
+		OrderExpr("lower(tag.name)").
+		Scan(ctx)
+	if err != nil {
+		log.Error().Err(err).Int("plan_id", planID).Msg("Failed to fetch tags")
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (r *tagRepository) GetByID(id int, planID int) (*model.Tag, error) {
+	ctx := context.Background()
+	tag := new(model.Tag)
+	err := r.db.NewSelect().Model(tag).Where("id = ? AND plan_id = ?", id, planID).Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+// GetByName finds a tag of a plan by name, ignoring case.
+func (r *tagRepository) GetByName(name string, planID int) (*model.Tag, error) {
+	ctx := context.Background()
+	tag := new(model.Tag)
+	err := r.db.NewSelect().Model(tag).Where("lower(name) = lower(?) AND plan_id = ?", name, planID).Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+func (r *tagRepository) Rename(tag *model.Tag) error {
+	log.Info().Int("id", tag.ID).Str("name", tag.Name).Msg("Renaming tag")
+	ctx := context.Background()
+	res, err := r.db.NewUpdate().
+		Model(tag).
+		Column("name").
+		Where("id = ? AND plan_id = ?", tag.ID, tag.PlanID).
+		Exec(ctx)
+	return checkAffected(res, err, "Failed to rename tag", tag.ID)
+}
+
+// Merge moves the expenses of the source tags to the target tag and deletes
+// the sources. Expenses that already carry the target are not duplicated.
+func (r *tagRepository) Merge(planID int, sourceIDs []int, targetID int) error {
+	log.Info().Int("plan_id", planID).Ints("source_ids", sourceIDs).Int("target_id", targetID).Msg("Merging tags")
+	ctx := context.Background()
+	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		sources := tx.NewSelect().
+			Model((*model.Tag)(nil)).
+			Column("id").
+			Where("plan_id = ? AND id IN (?)", planID, bun.In(sourceIDs))
+		moved := tx.NewSelect().
+			Model((*model.ExpenseTag)(nil)).
+			ColumnExpr("DISTINCT expense_tag.expense_id, ?", targetID).
+			Where("expense_tag.tag_id IN (?)", sources)
+		_, err := tx.NewInsert().
+			Model((*model.ExpenseTag)(nil)).
+			On("CONFLICT DO NOTHING").
+			With("moved", moved).
+			TableExpr("moved").
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		res, err := tx.NewDelete().
+			Model((*model.Tag)(nil)).
+			Where("plan_id = ? AND id IN (?)", planID, bun.In(sourceIDs)).
+			Exec(ctx)
+		return checkAffected(res, err, "Failed to delete merged tags", targetID)
+	})
+	if err != nil {
+		log.Error().Err(err).Int("plan_id", planID).Msg("Failed to merge tags")
+	}
+	return err
+}
+
+func (r *tagRepository) Delete(id int, planID int) error {
+	log.Info().Int("id", id).Msg("Deleting tag")
+	ctx := context.Background()
+	res, err := r.db.NewDelete().Model((*model.Tag)(nil)).Where("id = ? AND plan_id = ?", id, planID).Exec(ctx)
+	return checkAffected(res, err, "Failed to delete tag", id)
+}
+
+// SetExpenseTags replaces the tags of an expense, creating the tags of its plan
+// that do not exist yet. Names are matched ignoring case.
+func (r *tagRepository) SetExpenseTags(expenseID int, planID int, names []string) error {
+	log.Info().Int("expense_id", expenseID).Strs("tags", names).Msg("Tagging expense")
+	ctx := context.Background()
+	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		return setExpenseTags(ctx, tx, expenseID, planID, names)
+	})
+	if err != nil {
+		log.Error().Err(err).Int("expense_id", expenseID).Msg("Failed to tag expense")
+	}
+	return err
+}
+
+// setExpenseTags replaces the tags of an expense within tx, so that other
+// repositories can tag an expense in the transaction that writes it.
+func setExpenseTags(ctx context.Context, tx bun.Tx, expenseID int, planID int, names []string) error {
+	if _, err := tx.NewDelete().Model((*model.ExpenseTag)(nil)).Where("expense_id = ?", expenseID).Exec(ctx); err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	tags := make([]model.Tag, len(names))
+	lower := make([]string, len(names))
+	for i, name := range names {
+		tags[i] = model.Tag{PlanID: planID, Name: name}
+		lower[i] = strings.ToLower(name)
+	}
+	_, err := tx.NewInsert().
+		Model(&tags).
+		ExcludeColumn("created_at").
+		On("CONFLICT (plan_id, lower(name)) DO NOTHING").
+		Returning("NULL").
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	matched := tx.NewSelect().
+		Model((*model.Tag)(nil)).
+		ColumnExpr("?, tag.id", expenseID).
+		Where("tag.plan_id = ? AND lower(tag.name) IN (?)", planID, bun.In(lower))
+	_, err = tx.NewInsert().
+		Model((*model.ExpenseTag)(nil)).
+		With("matched", matched).
+		TableExpr("matched").
+		Exec(ctx)
+	return err
+}
+
+// withTags selects every column of an Expense query along with its tag names.
+func withTags(q *bun.SelectQuery) *bun.SelectQuery {
+	return q.ColumnExpr("expense.*").
+		ColumnExpr("ARRAY(SELECT t.name FROM expense_tags AS et JOIN tags AS t ON t.id = et.tag_id " +
+			"WHERE et.expense_id = expense.id ORDER BY lower(t.name)) AS tags")
+}
+
+// withTagFilter restricts an Expense query to the expenses matched by filter.
+func withTagFilter(q *bun.SelectQuery, filter model.TagFilter) *bun.SelectQuery {
+	if filter.IsZero() {
+		return q
+	}
+	seen := make(map[string]bool, len(filter.Tags))
+	lower := make([]string, 0, len(filter.Tags))
+	for _, name := range filter.Tags {
+		if key := strings.ToLower(name); !seen[key] {
+			seen[key] = true
+			lower = append(lower, key)
+		}
+	}
+	matched := "SELECT COUNT(DISTINCT lower(t.name)) FROM expense_tags AS et JOIN tags AS t ON t.id = et.tag_id " +
+		"WHERE et.expense_id = expense.id AND lower(t.name) IN (?)"
+	if filter.All {
+		return q.Where("("+matched+") = ?", bun.In(lower), len(lower))
+	}
+	return q.Where("("+matched+") > 0", bun.In(lower))
+}
@@ -15,6 +15,7 @@ type UserRepository interface {
 	FindByEmail(email string) (*model.User, error)
 	Update(user *model.User) error
 	UpdatePassword(user *model.User) error
+	IncrementTokenVersion(id int) (int, error)
 	Delete(id int) error
 }
 
@@ -52,12 +53,12 @@ func (r *userRepository) FindByID(id int) (*model.User, error) {
 	return user, err
 }
 
-// FindByEmail retrieves a User by their email address.
+// FindByEmail retrieves a User by their email address, ignoring case.
 func (r *userRepository) FindByEmail(email string) (*model.User, error) {
 	ctx := context.Background()
 	user := new(model.User)
 	log.Debug().Msg("Searching for user by email")
-	err := r.db.NewSelect().Model(user).Where("email = ?", email).Scan(ctx, user)
+	err := r.db.NewSelect().Model(user).Where("lower(email) = lower(?)", email).Scan(ctx, user)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to find user by email")
 	}
@@ -94,6 +95,24 @@ func (r *userRepository) UpdatePassword(user *model.User) error {
 	return err
 }
 
+// IncrementTokenVersion bumps the token version of the User, invalidating every
+// access token issued before, and returns the new version.
+func (r *userRepository) IncrementTokenVersion(id int) (int, error) {
+	ctx := context.Background()
+	log.Debug().Int("id", id).Msg("Incrementing user token version")
+	var version int
+	err := r.db.NewUpdate().
+		Model((*model.User)(nil)).
+		Set("token_version = token_version + 1").
+		Where("id = ?", id).
+		Returning("token_version").
+		Scan(ctx, &version)
+	if err != nil {
+		log.Error().Err(err).Int("id", id).Msg("Failed to increment user token version")
+	}
+	return version, err
+}
+
 // Delete removes a User from the database by ID.
 func (r *userRepository) Delete(id int) error {
 	ctx := context.Background()
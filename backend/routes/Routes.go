@@ -5,10 +5,11 @@ import (
 	"backend/middleware"
 	"backend/service"
 	"github.com/gorilla/mux"
-	"net/http"
 )
 
-func SetupRoutes(serviceFactory *service.ServiceBase) http.Handler {
+// SetupRoutes registers every route of the API. Routes must also be listed
+// in the OpenAPI document; openapi.Check reports the ones that are not.
+func SetupRoutes(serviceFactory *service.ServiceBase) *mux.Router {
 	r := mux.NewRouter()
 
 	userController := controller.NewUserController(serviceFactory)
@@ -16,6 +17,9 @@ func SetupRoutes(serviceFactory *service.ServiceBase) http.Handler {
 	r.HandleFunc("/users", userController.CreateUser).Methods("POST")
 	r.HandleFunc("/users", userController.FindByEmail).Methods("GET")
 	r.HandleFunc("/users/login", userController.Login).Methods("POST")
+	r.HandleFunc("/users/refresh", userController.Refresh).Methods("POST")
+	r.HandleFunc("/users/logout", middleware.JWTAuth(userController.Logout)).Methods("POST")
+	r.HandleFunc("/users/logout/all", middleware.JWTAuth(userController.LogoutAll)).Methods("POST")
 	r.HandleFunc("/users/password", middleware.JWTAuth(userController.UpdatePassword)).Methods("PUT")
 	r.HandleFunc("/users", middleware.JWTAuth(userController.Delete)).Methods("DELETE")
 	r.HandleFunc("/users", middleware.JWTAuth(userController.Update)).Methods("PUT")
@@ -32,15 +36,136 @@ func SetupRoutes(serviceFactory *service.ServiceBase) http.Handler {
 	r.HandleFunc("/expense", middleware.JWTAuth(expenseController.NewExpense)).Methods("POST")
 	r.HandleFunc("/expense/plan", middleware.JWTAuth(expenseController.GetByPlan)).Methods("GET")
 	r.HandleFunc("/expense/category", middleware.JWTAuth(expenseController.GetByCategory)).Methods("GET")
+	r.HandleFunc("/expense/search", middleware.JWTAuth(expenseController.Search)).Methods("GET")
 	r.HandleFunc("/expense", middleware.JWTAuth(expenseController.Update)).Methods("PUT")
 	r.HandleFunc("/expense", middleware.JWTAuth(expenseController.Delete)).Methods("DELETE")
 
+	incomeController := controller.NewIncomeController(serviceFactory)
+	r.HandleFunc("/income", middleware.JWTAuth(incomeController.Create)).Methods("POST")
+	r.HandleFunc("/income/plan", middleware.JWTAuth(incomeController.GetByPlan)).Methods("GET")
+	r.HandleFunc("/income", middleware.JWTAuth(incomeController.Update)).Methods("PUT")
+	r.HandleFunc("/income", middleware.JWTAuth(incomeController.Delete)).Methods("DELETE")
+	r.HandleFunc("/plan/balance", middleware.JWTAuth(incomeController.Balance)).Methods("GET")
+
+	importController := controller.NewImportController(serviceFactory)
+	r.HandleFunc("/import/preview", middleware.JWTAuth(importController.Preview)).Methods("POST")
+	r.HandleFunc("/import/commit", middleware.JWTAuth(importController.Commit)).Methods("POST")
+	r.HandleFunc("/import/restore", middleware.JWTAuth(importController.Restore)).Methods("POST")
+
+	exportController := controller.NewExportController(serviceFactory)
+	r.HandleFunc("/export", middleware.JWTAuth(exportController.Export)).Methods("GET")
+
 	budgetController := controller.NewBudgetPlanController(serviceFactory)
 	r.HandleFunc("/plan", middleware.JWTAuth(budgetController.CreatePlan)).Methods("POST")
 	r.HandleFunc("/plan/user", middleware.JWTAuth(budgetController.GetByUser)).Methods("GET")
 	r.HandleFunc("/plan", middleware.JWTAuth(budgetController.Delete)).Methods("DELETE")
 	r.HandleFunc("/plan/amount", middleware.JWTAuth(budgetController.UpdateAmount)).Methods("PUT")
+	r.HandleFunc("/plan/amount/history", middleware.JWTAuth(budgetController.History)).Methods("GET")
 	r.HandleFunc("/plan", middleware.JWTAuth(budgetController.Update)).Methods("PUT")
 
+	memberController := controller.NewPlanMemberController(serviceFactory)
+	r.HandleFunc("/plan/members", middleware.JWTAuth(memberController.GetByPlan)).Methods("GET")
+	r.HandleFunc("/plan/members", middleware.JWTAuth(memberController.UpdateRole)).Methods("PUT")
+	r.HandleFunc("/plan/members", middleware.JWTAuth(memberController.Remove)).Methods("DELETE")
+	r.HandleFunc("/plan/invitations", middleware.JWTAuth(memberController.Invite)).Methods("POST")
+	r.HandleFunc("/plan/invitations", middleware.JWTAuth(memberController.GetInvitations)).Methods("GET")
+	r.HandleFunc("/plan/invitations", middleware.JWTAuth(memberController.Revoke)).Methods("DELETE")
+	r.HandleFunc("/plan/invitations/pending", middleware.JWTAuth(memberController.Pending)).Methods("GET")
+	r.HandleFunc("/plan/invitations/accept", middleware.JWTAuth(memberController.Accept)).Methods("POST")
+	r.HandleFunc("/plan/invitations/decline", middleware.JWTAuth(memberController.Decline)).Methods("POST")
+
+	attachmentController := controller.NewAttachmentController(serviceFactory)
+	r.HandleFunc("/expense/attachments", middleware.JWTAuth(attachmentController.Upload)).Methods("POST")
+	r.HandleFunc("/expense/attachments", middleware.JWTAuth(attachmentController.GetByExpense)).Methods("GET")
+	r.HandleFunc("/expense/attachments", middleware.JWTAuth(attachmentController.Delete)).Methods("DELETE")
+	r.HandleFunc("/expense/attachments/download", middleware.JWTAuth(attachmentController.Download)).Methods("GET")
+
+	tagController := controller.NewTagController(serviceFactory)
+	r.HandleFunc("/tags", middleware.JWTAuth(tagController.GetByPlan)).Methods("GET")
+	r.HandleFunc("/tags", middleware.JWTAuth(tagController.Rename)).Methods("PUT")
+	r.HandleFunc("/tags", middleware.JWTAuth(tagController.Delete)).Methods("DELETE")
+	r.HandleFunc("/tags/merge", middleware.JWTAuth(tagController.Merge)).Methods("POST")
+
+	ruleController := controller.NewRuleController(serviceFactory)
+	r.HandleFunc("/rules", middleware.JWTAuth(ruleController.Create)).Methods("POST")
+	r.HandleFunc("/rules", middleware.JWTAuth(ruleController.GetByUser)).Methods("GET")
+	r.HandleFunc("/rules", middleware.JWTAuth(ruleController.Update)).Methods("PUT")
+	r.HandleFunc("/rules", middleware.JWTAuth(ruleController.Delete)).Methods("DELETE")
+	r.HandleFunc("/rules/apply", middleware.JWTAuth(ruleController.Apply)).Methods("POST")
+	r.HandleFunc("/rules/dry-run", middleware.JWTAuth(ruleController.DryRun)).Methods("POST")
+
+	splitController := controller.NewSplitController(serviceFactory)
+	r.HandleFunc("/expense/splits", middleware.JWTAuth(splitController.Split)).Methods("PUT")
+	r.HandleFunc("/expense/splits", middleware.JWTAuth(splitController.GetByExpense)).Methods("GET")
+	r.HandleFunc("/expense/splits", middleware.JWTAuth(splitController.Unsplit)).Methods("DELETE")
+	r.HandleFunc("/plan/settlement", middleware.JWTAuth(splitController.Settlement)).Methods("GET")
+	r.HandleFunc("/plan/settlement/payments", middleware.JWTAuth(splitController.RecordPayment)).Methods("POST")
+	r.HandleFunc("/plan/settlement/payments", middleware.JWTAuth(splitController.GetPayments)).Methods("GET")
+	r.HandleFunc("/plan/settlement/payments", middleware.JWTAuth(splitController.DeletePayment)).Methods("DELETE")
+
+	periodController := controller.NewBudgetPeriodController(serviceFactory)
+	r.HandleFunc("/plan/periods", middleware.JWTAuth(periodController.GetByPlan)).Methods("GET")
+	r.HandleFunc("/plan/periods/current", middleware.JWTAuth(periodController.Current)).Methods("GET")
+
+	categoryBudgetController := controller.NewCategoryBudgetController(serviceFactory)
+	r.HandleFunc("/plan/budgets", middleware.JWTAuth(categoryBudgetController.GetByPlan)).Methods("GET")
+	r.HandleFunc("/plan/budgets", middleware.JWTAuth(categoryBudgetController.Save)).Methods("PUT")
+	r.HandleFunc("/plan/budgets", middleware.JWTAuth(categoryBudgetController.Delete)).Methods("DELETE")
+	r.HandleFunc("/plan/budgets/status", middleware.JWTAuth(categoryBudgetController.Status)).Methods("GET")
+
+	recurringController := controller.NewRecurringExpenseController(serviceFactory)
+	r.HandleFunc("/recurring", middleware.JWTAuth(recurringController.Create)).Methods("POST")
+	r.HandleFunc("/recurring/plan", middleware.JWTAuth(recurringController.GetByPlan)).Methods("GET")
+	r.HandleFunc("/recurring", middleware.JWTAuth(recurringController.Update)).Methods("PUT")
+	r.HandleFunc("/recurring", middleware.JWTAuth(recurringController.Delete)).Methods("DELETE")
+	r.HandleFunc("/recurring/pause", middleware.JWTAuth(recurringController.Pause)).Methods("PUT")
+	r.HandleFunc("/recurring/resume", middleware.JWTAuth(recurringController.Resume)).Methods("PUT")
+	r.HandleFunc("/recurring/skip", middleware.JWTAuth(recurringController.Skip)).Methods("PUT")
+
+	reportController := controller.NewReportController(serviceFactory)
+	r.HandleFunc("/reports/category", middleware.JWTAuth(reportController.ByCategory)).Methods("GET")
+	r.HandleFunc("/reports/period", middleware.JWTAuth(reportController.ByPeriod)).Methods("GET")
+	r.HandleFunc("/reports/recurrence", middleware.JWTAuth(reportController.ByRecurrence)).Methods("GET")
+	r.HandleFunc("/reports/tag", middleware.JWTAuth(reportController.ByTag)).Methods("GET")
+
+	rateController := controller.NewExchangeRateController(serviceFactory)
+	r.HandleFunc("/rates", middleware.JWTAuth(rateController.Create)).Methods("POST")
+	r.HandleFunc("/rates/import", middleware.JWTAuth(rateController.Import)).Methods("POST")
+	r.HandleFunc("/rates", middleware.JWTAuth(rateController.List)).Methods("GET")
+
+	docsController := controller.NewDocsController()
+	r.HandleFunc("/openapi.json", docsController.Spec).Methods("GET")
+	r.HandleFunc("/docs", docsController.Page).Methods("GET")
+
+	setupV2Routes(r.PathPrefix("/api/v2").Subrouter(), serviceFactory)
+
 	return r
 }
+
+// setupV2Routes registers the resources of the v2 API, addressed by path
+// parameters, on r. The routes above stay mounted for existing clients.
+func setupV2Routes(r *mux.Router, serviceFactory *service.ServiceBase) {
+	planController := controller.NewBudgetPlanV2Controller(serviceFactory)
+	r.HandleFunc("/plans", middleware.JWTAuth(planController.List)).Methods("GET")
+	r.HandleFunc("/plans", middleware.JWTAuth(planController.Create)).Methods("POST")
+	r.HandleFunc("/plans/{id:[0-9]+}", middleware.JWTAuth(planController.Get)).Methods("GET")
+	r.HandleFunc("/plans/{id:[0-9]+}", middleware.JWTAuth(planController.Patch)).Methods("PATCH")
+	r.HandleFunc("/plans/{id:[0-9]+}", middleware.JWTAuth(planController.Delete)).Methods("DELETE")
+	r.HandleFunc("/plans/{id:[0-9]+}/adjustments", middleware.JWTAuth(planController.Adjustments)).Methods("GET")
+	r.HandleFunc("/plans/{id:[0-9]+}/adjustments", middleware.JWTAuth(planController.Adjust)).Methods("POST")
+
+	expenseController := controller.NewExpenseV2Controller(serviceFactory)
+	r.HandleFunc("/plans/{id:[0-9]+}/expenses", middleware.JWTAuth(expenseController.List)).Methods("GET")
+	r.HandleFunc("/plans/{id:[0-9]+}/expenses", middleware.JWTAuth(expenseController.Create)).Methods("POST")
+	r.HandleFunc("/plans/{id:[0-9]+}/expenses/{expenseId:[0-9]+}", middleware.JWTAuth(expenseController.Get)).Methods("GET")
+	r.HandleFunc("/plans/{id:[0-9]+}/expenses/{expenseId:[0-9]+}", middleware.JWTAuth(expenseController.Patch)).Methods("PATCH")
+	r.HandleFunc("/plans/{id:[0-9]+}/expenses/{expenseId:[0-9]+}", middleware.JWTAuth(expenseController.Delete)).Methods("DELETE")
+
+	categoryListController := controller.NewCategoryController(serviceFactory)
+	categoryController := controller.NewCategoryV2Controller(serviceFactory)
+	r.HandleFunc("/categories", middleware.JWTAuth(categoryListController.GetAll)).Methods("GET")
+	r.HandleFunc("/categories", middleware.JWTAuth(categoryController.Create)).Methods("POST")
+	r.HandleFunc("/categories/{id:[0-9]+}", middleware.JWTAuth(categoryController.Get)).Methods("GET")
+	r.HandleFunc("/categories/{id:[0-9]+}", middleware.JWTAuth(categoryController.Patch)).Methods("PATCH")
+	r.HandleFunc("/categories/{id:[0-9]+}", middleware.JWTAuth(categoryController.Delete)).Methods("DELETE")
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"backend/model"
+	"backend/repository"
+	"backend/storage"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// sweepBatch is how many queued blobs a sweep removes per round trip.
+const sweepBatch = 100
+
+type AttachmentService interface {
+	Upload(expenseID int, fileName string, r io.Reader, email string) (*model.Attachment, error)
+	GetByExpense(expenseID int, email string) ([]model.Attachment, error)
+	Download(id int, email string) (*model.Attachment, io.ReadCloser, error)
+	Delete(id int, email string) error
+	Sweep() (int, error)
+}
+
+type attachmentService struct {
+	repository repository.AttachmentRepository
+	expenses   repository.ExpensesRepository
+	budget     repository.BudgetPlanRepository
+	user       repository.UserRepository
+}
+
+func NewAttachmentService(factory *repository.RepositoryBase) AttachmentService {
+	return &attachmentService{
+		repository: repository.GetByType[repository.AttachmentRepository](factory),
+		expenses:   repository.GetByType[repository.ExpensesRepository](factory),
+		budget:     repository.GetByType[repository.BudgetPlanRepository](factory),
+		user:       repository.GetByType[repository.UserRepository](factory),
+	}
+}
+
+// Upload attaches a receipt to an expense. The content type is detected from
+// the data itself and must be one of model.AttachmentContentTypes.
+func (s *attachmentService) Upload(expenseID int, fileName string, r io.Reader, email string) (*model.Attachment, error) {
+	user, expense, err := s.expense(expenseID, email, model.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, model.MaxAttachmentSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: the file is empty", ErrInvalid)
+	}
+	if len(data) > model.MaxAttachmentSize {
+		return nil, fmt.Errorf("%w: the file is larger than %d MiB", ErrInvalid, model.MaxAttachmentSize>>20)
+	}
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	if !model.AttachmentContentTypes[contentType] {
+		return nil, fmt.Errorf("%w: unsupported file type %s", ErrInvalid, contentType)
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("failed to generate attachment key: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	attachment := &model.Attachment{
+		ExpenseID:   expense.ID,
+		StorageKey:  fmt.Sprintf("plans/%d/expenses/%d/%s", expense.BudgetID, expense.ID, hex.EncodeToString(id)),
+		FileName:    cleanFileName(fileName),
+		ContentType: contentType,
+		SizeBytes:   int64(len(data)),
+		SHA256:      hex.EncodeToString(sum[:]),
+		UploadedBy:  &user.ID,
+	}
+	if err := s.repository.Create(attachment, data); err != nil {
+		return nil, err
+	}
+	attachment.PlanID = expense.BudgetID
+	return attachment, nil
+}
+
+func (s *attachmentService) GetByExpense(expenseID int, email string) ([]model.Attachment, error) {
+	_, expense, err := s.expense(expenseID, email, model.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	return s.repository.GetByExpense(expense.ID)
+}
+
+// Download opens the file of an attachment. The caller must close it.
+func (s *attachmentService) Download(id int, email string) (*model.Attachment, io.ReadCloser, error) {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return nil, nil, err
+	}
+	attachment, err := s.repository.GetByID(id, user.ID)
+	if err != nil {
+		return nil, nil, notFound(err)
+	}
+	blob, err := s.repository.Open(attachment)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return attachment, blob, nil
+}
+
+func (s *attachmentService) Delete(id int, email string) error {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return err
+	}
+	attachment, err := s.repository.GetByID(id, user.ID)
+	if err != nil {
+		return notFound(err)
+	}
+	plan, err := s.budget.GetAccessible(attachment.PlanID, user.ID)
+	if err != nil {
+		return notFound(err)
+	}
+	if err := requireRole(plan, model.RoleEditor); err != nil {
+		return err
+	}
+	if err := s.repository.Delete(id); err != nil {
+		return notFound(err)
+	}
+	purgeBlobs(s.repository)
+	return nil
+}
+
+// Sweep removes every blob queued for deletion and returns how many were removed.
+func (s *attachmentService) Sweep() (int, error) {
+	removed := 0
+	for {
+		n, err := s.repository.Sweep(sweepBatch)
+		removed += n
+		if err != nil || n < sweepBatch {
+			return removed, err
+		}
+	}
+}
+
+// expense loads an expense of a plan the caller holds role in.
+func (s *attachmentService) expense(id int, email string, role model.PlanRole) (*model.User, *model.Expense, error) {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return nil, nil, err
+	}
+	expense, err := s.expenses.GetByID(id, user.ID)
+	if err != nil {
+		return nil, nil, notFound(err)
+	}
+	plan, err := s.budget.GetAccessible(expense.BudgetID, user.ID)
+	if err != nil {
+		return nil, nil, notFound(err)
+	}
+	if err := requireRole(plan, role); err != nil {
+		return nil, nil, err
+	}
+	return user, expense, nil
+}
+
+// cleanFileName keeps the base name of an uploaded file, without characters
+// that would break a Content-Disposition header.
+func cleanFileName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == '"' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, name)
+	if name = strings.TrimSpace(name); name == "" || name == "." || name == "/" {
+		return "receipt"
+	}
+	return name
+}
+
+// purgeBlobs removes the blobs queued by deleted attachments right away.
+// Failures are only logged since RunAttachmentSweeper retries them.
+func purgeBlobs(attachments repository.AttachmentRepository) {
+	if _, err := attachments.Sweep(sweepBatch); err != nil {
+		log.Error().Err(err).Msg("Failed to purge attachment blobs")
+	}
+}
+
+// RunAttachmentSweeper removes the blobs of deleted attachments immediately
+// and then every interval until ctx is cancelled.
+func RunAttachmentSweeper(ctx context.Context, svc AttachmentService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		removed, err := svc.Sweep()
+		if err != nil {
+			log.Error().Err(err).Msg("Attachment sweeper run failed")
+		} else if removed > 0 {
+			log.Info().Int("removed", removed).Msg("Attachment blobs removed")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
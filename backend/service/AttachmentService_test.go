@@ -0,0 +1,138 @@
+package service
+
+import (
+	"backend/model"
+	"backend/repository"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// The fakes embed the repository interfaces and implement only what Upload
+// calls; anything else panics.
+type fakeUsers struct {
+	repository.UserRepository
+	user *model.User
+}
+
+func (f fakeUsers) FindByEmail(string) (*model.User, error) { return f.user, nil }
+
+type fakeExpenses struct {
+	repository.ExpensesRepository
+	expense *model.Expense
+}
+
+func (f fakeExpenses) GetByID(int, int) (*model.Expense, error) { return f.expense, nil }
+
+type fakePlans struct {
+	repository.BudgetPlanRepository
+	plan *model.BudgetPlan
+}
+
+func (f fakePlans) GetAccessible(int, int) (*model.BudgetPlan, error) { return f.plan, nil }
+
+type fakeAttachments struct {
+	repository.AttachmentRepository
+	stored []*model.Attachment
+}
+
+func (f *fakeAttachments) Create(attachment *model.Attachment, _ []byte) error {
+	f.stored = append(f.stored, attachment)
+	return nil
+}
+
+func newUploadService(role model.PlanRole) (*attachmentService, *fakeAttachments) {
+	attachments := &fakeAttachments{}
+	return &attachmentService{
+		repository: attachments,
+		expenses:   fakeExpenses{expense: &model.Expense{ID: 7, BudgetID: 3}},
+		budget:     fakePlans{plan: &model.BudgetPlan{ID: 3, Role: role}},
+		user:       fakeUsers{user: &model.User{ID: 1, Email: "ana@example.com"}},
+	}, attachments
+}
+
+var (
+	pdf  = []byte("%PDF-1.7\n1 0 obj\n<<>>\nendobj\n")
+	png  = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	html = []byte("<!DOCTYPE html><html><body>receipt</body></html>")
+)
+
+func TestUploadDetectsContentType(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		data     []byte
+		want     string
+		wantErr  error
+	}{
+		{"pdf", "receipt.pdf", pdf, "application/pdf", nil},
+		{"png named as pdf", "receipt.pdf", png, "image/png", nil},
+		{"html named as pdf", "receipt.pdf", html, "", ErrInvalid},
+		{"plain text", "notes.txt", []byte("paid in cash"), "", ErrInvalid},
+		{"empty", "empty.pdf", nil, "", ErrInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, attachments := newUploadService(model.RoleEditor)
+			attachment, err := svc.Upload(7, tt.fileName, bytes.NewReader(tt.data), "ana@example.com")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				if len(attachments.stored) != 0 {
+					t.Errorf("a rejected file was stored")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if attachment.ContentType != tt.want {
+				t.Errorf("content type = %q, want %q", attachment.ContentType, tt.want)
+			}
+			if attachment.SizeBytes != int64(len(tt.data)) {
+				t.Errorf("size = %d, want %d", attachment.SizeBytes, len(tt.data))
+			}
+		})
+	}
+}
+
+func TestUploadSizeLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		wantErr bool
+	}{
+		{"at the limit", model.MaxAttachmentSize, false},
+		{"one byte over", model.MaxAttachmentSize + 1, true},
+		{"far over", 3 * model.MaxAttachmentSize, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, attachments := newUploadService(model.RoleEditor)
+			data := append(append([]byte{}, pdf...), bytes.Repeat([]byte{' '}, tt.size-len(pdf))...)
+			_, err := svc.Upload(7, "big.pdf", bytes.NewReader(data), "ana@example.com")
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalid) || !strings.Contains(err.Error(), "larger than") {
+					t.Fatalf("err = %v, want a size error", err)
+				}
+				if len(attachments.stored) != 0 {
+					t.Errorf("an oversized file was stored")
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestUploadRequiresEditor(t *testing.T) {
+	svc, attachments := newUploadService(model.RoleViewer)
+	if _, err := svc.Upload(7, "receipt.pdf", bytes.NewReader(pdf), "ana@example.com"); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("err = %v, want ErrForbidden", err)
+	}
+	if len(attachments.stored) != 0 {
+		t.Errorf("a viewer's file was stored")
+	}
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"backend/model"
+	"backend/repository"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+type BudgetPeriodService interface {
+	GetByPlan(planID int, email string) ([]model.BudgetPeriod, error)
+	Current(planID int, email string) (*model.BudgetPeriod, error)
+	AdvanceDue(now time.Time) (int, error)
+}
+
+type budgetPeriodService struct {
+	repository repository.BudgetPeriodRepository
+	budget     repository.BudgetPlanRepository
+	user       repository.UserRepository
+}
+
+func NewBudgetPeriodService(factory *repository.RepositoryBase) BudgetPeriodService {
+	return &budgetPeriodService{
+		repository: repository.GetByType[repository.BudgetPeriodRepository](factory),
+		budget:     repository.GetByType[repository.BudgetPlanRepository](factory),
+		user:       repository.GetByType[repository.UserRepository](factory),
+	}
+}
+
+// GetByPlan lists the periods of a plan, most recent first.
+func (s *budgetPeriodService) GetByPlan(planID int, email string) ([]model.BudgetPeriod, error) {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := s.budget.GetAccessible(planID, user.ID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	periods, err := s.repository.GetByPlan(plan.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range periods {
+		fillPeriod(&periods[i])
+	}
+	return periods, nil
+}
+
+// Current returns the latest period of a plan, starting any period the
+// scheduler has not created yet.
+func (s *budgetPeriodService) Current(planID int, email string) (*model.BudgetPeriod, error) {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := s.budget.GetAccessible(planID, user.ID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	latest, err := s.repository.Latest(plan.ID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if _, err := advancePeriods(s.repository, plan, latest, time.Now()); err != nil {
+		return nil, err
+	}
+	if latest, err = s.repository.Latest(plan.ID); err != nil {
+		return nil, notFound(err)
+	}
+	fillPeriod(latest)
+	return latest, nil
+}
+
+// AdvanceDue starts the next period of every periodic plan whose current
+// period has ended and returns how many periods were created. A plan that
+// fails is logged and left for the next run, without holding up the others.
+func (s *budgetPeriodService) AdvanceDue(now time.Time) (int, error) {
+	due, err := s.repository.Due(now)
+	if err != nil {
+		return 0, err
+	}
+	created := 0
+	for i := range due {
+		n, err := advancePeriods(s.repository, due[i].Plan, &due[i], now)
+		created += n
+		if err != nil {
+			log.Error().Err(err).Int("plan_id", due[i].PlanID).Msg("Failed to start budget period")
+		}
+	}
+	return created, nil
+}
+
+// advancePeriods creates the periods following last up to the one
+// containing now. When the plan rolls over, the amount left in a period
+// (negative when overspent) is added to the next one.
+func advancePeriods(periods repository.BudgetPeriodRepository, plan *model.BudgetPlan, last *model.BudgetPeriod, now time.Time) (int, error) {
+	created := 0
+	for plan.IsPeriodic() && last.EndDate != nil && !last.EndDate.After(now) {
+		if plan.EndDate != nil && !last.EndDate.Before(*plan.EndDate) {
+			break
+		}
+		currency := plan.BaseCurrency()
+		rollover := model.NewMoney(0, currency)
+		if plan.Rollover {
+			rollover.Minor = last.Amount.Minor - last.SpentMinor
+		}
+		next := &model.BudgetPeriod{
+			PlanID:    plan.ID,
+			StartDate: *last.EndDate,
+			EndDate:   plan.PeriodEnd(*last.EndDate),
+			Amount:    model.NewMoney(plan.TotalAmount.Minor+rollover.Minor, currency),
+			Rollover:  rollover,
+		}
+		if err := periods.Create(next); err != nil {
+			if repository.SQLState(err) == "23505" { // unique_violation on (plan_id, start_date)
+				// another run started the period first and carries on from it
+				break
+			}
+			return created, err
+		}
+		created++
+		// reload to pick up expenses already dated within the new period
+		reloaded, err := periods.GetByID(next.ID, plan.ID)
+		if err != nil {
+			return created, err
+		}
+		last = reloaded
+	}
+	return created, nil
+}
+
+// fillPeriod expresses what was spent and what remains in the period's currency.
+func fillPeriod(period *model.BudgetPeriod) {
+	currency := period.Amount.CurrencyCode()
+	period.Spent = model.NewMoney(period.SpentMinor, currency)
+	period.Remaining = model.NewMoney(period.Amount.Minor-period.SpentMinor, currency)
+}
+
+// applyPeriod narrows q to the dates of the plan period given by q.PeriodID.
+func applyPeriod(periods repository.BudgetPeriodRepository, q *ReportQuery, planID int) error {
+	if q.PeriodID == 0 {
+		return nil
+	}
+	if q.From != nil || q.To != nil {
+		return fmt.Errorf("%w: period_id cannot be combined with from or to", ErrInvalid)
+	}
+	period, err := periods.GetByID(q.PeriodID, planID)
+	if err != nil {
+		return notFound(err)
+	}
+	from := period.StartDate
+	q.From = &from
+	if period.EndDate != nil {
+		to := period.EndDate.AddDate(0, 0, -1)
+		q.To = &to
+	}
+	return nil
+}
+
+// RunPeriodScheduler starts due budget periods immediately and then every
+// interval until ctx is cancelled.
+func RunPeriodScheduler(ctx context.Context, svc BudgetPeriodService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		created, err := svc.AdvanceDue(time.Now())
+		if err != nil {
+			log.Error().Err(err).Msg("Budget period scheduler run failed")
+		} else if created > 0 {
+			log.Info().Int("created", created).Msg("Budget periods started")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
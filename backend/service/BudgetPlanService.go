@@ -2,61 +2,248 @@ package service
 
 import (
 	"backend/model"
+	"backend/model/request"
 	"backend/repository"
-	"errors"
+	"fmt"
+	"strings"
+	"time"
 )
 
 type BudgetPlanService interface {
 	Create(b *model.BudgetPlan, email string) error
-	FindByUser(id int) ([]model.BudgetPlan, error)
-	Delete(id int) error
-	Update(b *model.BudgetPlan) error
-	UpdateAmount(id int, amount float64, Add bool) error
+	FindByUser(id int, q PlanQuery, list model.ListQuery) (*model.Page[model.BudgetPlan], error)
+	Get(id int, email string) (*model.BudgetPlan, error)
+	Delete(id int, version int, email string) error
+	Update(b *model.BudgetPlan, email string) error
+	UpdateAmount(req *request.PlanAmountRequest, email string) (*model.PlanAdjustment, error)
+	History(id int, email string) ([]model.PlanAdjustment, error)
 }
 type budgetPlanService struct {
-	repository repository.BudgetPlanRepository
-	user       repository.UserRepository
+	repository  repository.BudgetPlanRepository
+	periods     repository.BudgetPeriodRepository
+	attachments repository.AttachmentRepository
+	user        repository.UserRepository
+	rates       ExchangeRateProvider
 }
 
 func NewBudgetPlanService(factory *repository.RepositoryBase) BudgetPlanService {
 	return &budgetPlanService{
-		repository: repository.GetByType[repository.BudgetPlanRepository](factory),
-		user:       repository.GetByType[repository.UserRepository](factory),
+		repository:  repository.GetByType[repository.BudgetPlanRepository](factory),
+		periods:     repository.GetByType[repository.BudgetPeriodRepository](factory),
+		attachments: repository.GetByType[repository.AttachmentRepository](factory),
+		user:        repository.GetByType[repository.UserRepository](factory),
+		rates:       NewDBExchangeRateProvider(repository.GetByType[repository.ExchangeRateRepository](factory)),
 	}
 }
 
 func (s *budgetPlanService) Create(b *model.BudgetPlan, email string) error {
-	user, _ := s.user.FindByEmail(email)
-	if user == nil {
-		return errors.New("user does not exists")
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return err
 	}
 	b.UserID = user.ID
+	b.TotalAmount = model.NewMoney(b.TotalAmount.Minor, b.TotalAmount.Currency)
+	if err := b.ValidatePeriod(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
 	return s.repository.Create(b)
 }
 
-func (s *budgetPlanService) FindByUser(id int) ([]model.BudgetPlan, error) {
-	return s.repository.GetByUser(id)
+// How plan listings show the expenses of each plan.
+const (
+	PlanExpensesFull    = "full"
+	PlanExpensesSummary = "summary"
+	PlanExpensesNone    = "none"
+)
+
+// PlanQuery filters the plans of a user. Name matches part of the plan name.
+// Expenses is one of the PlanExpenses modes, summary when empty: full loads the
+// expenses of each plan, summary only counts and totals them and none leaves
+// them out.
+type PlanQuery struct {
+	Name     string
+	Role     model.PlanRole
+	Period   model.PeriodType
+	Expenses string
 }
 
-func (s *budgetPlanService) Delete(id int) error {
-	return s.repository.Delete(id)
+// FindByUser lists a page of the plans user id is a member of matched by q.
+func (s *budgetPlanService) FindByUser(id int, q PlanQuery, list model.ListQuery) (*model.Page[model.BudgetPlan], error) {
+	list, err := list.Resolve(model.PlanSortFields, "id")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	switch q.Expenses {
+	case "":
+		q.Expenses = PlanExpensesSummary
+	case PlanExpensesFull, PlanExpensesSummary, PlanExpensesNone:
+	default:
+		return nil, fmt.Errorf("%w: expenses must be %s, %s or %s", ErrInvalid, PlanExpensesFull, PlanExpensesSummary, PlanExpensesNone)
+	}
+	filter := repository.PlanFilter{
+		Name:         strings.TrimSpace(q.Name),
+		Role:         q.Role,
+		Period:       q.Period,
+		WithExpenses: q.Expenses == PlanExpensesFull,
+	}
+	page, err := s.repository.GetByUser(id, filter, list)
+	if err != nil || q.Expenses != PlanExpensesSummary {
+		return page, err
+	}
+
+	ids := make([]int, len(page.Items))
+	for i, plan := range page.Items {
+		ids[i] = plan.ID
+	}
+	totals, err := s.repository.Totals(ids)
+	if err != nil {
+		return nil, err
+	}
+	byPlan := make(map[int]repository.PlanTotal, len(totals))
+	for _, t := range totals {
+		byPlan[t.PlanID] = t
+	}
+	for i := range page.Items {
+		plan := &page.Items[i]
+		t := byPlan[plan.ID]
+		plan.Summary = &model.PlanSummary{ExpenseCount: t.Count, ExpenseTotal: model.NewMoney(t.Total, plan.BaseCurrency())}
+	}
+	return page, nil
 }
 
-func (s *budgetPlanService) Update(b *model.BudgetPlan) error {
-	return s.repository.Update(b)
+// Get returns a plan the caller is a member of, with their role but not its expenses.
+func (s *budgetPlanService) Get(id int, email string) (*model.BudgetPlan, error) {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := s.repository.GetAccessible(id, user.ID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return plan, nil
 }
-func (s *budgetPlanService) UpdateAmount(id int, amount float64, add bool) error {
-	plan, err := s.repository.GetByID(id)
+
+// Delete removes a plan along with the files attached to its expenses. Only
+// its owner may delete it. A version other than 0 must be the current one.
+func (s *budgetPlanService) Delete(id int, version int, email string) error {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return err
+	}
+	plan, err := s.repository.GetAccessible(id, user.ID)
 	if err != nil {
+		return notFound(err)
+	}
+	if err := requireRole(plan, model.RoleOwner); err != nil {
 		return err
 	}
+	if err := s.repository.Delete(id, user.ID, version); err != nil {
+		return notFound(err)
+	}
+	// attachments of the plan's expenses were deleted by cascade
+	purgeBlobs(s.attachments)
+	return nil
+}
 
-	var newAmount float64
-	if add {
-		newAmount = plan.TotalAmount + amount
-	} else {
-		newAmount = plan.TotalAmount - amount
+// Update edits a plan. When the period settings change, the current period
+// is cut or extended to match and the following ones use the new settings.
+// An empty Period keeps the current settings. Only the owner may edit a plan.
+func (s *budgetPlanService) Update(b *model.BudgetPlan, email string) error {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return err
+	}
+	existing, err := s.repository.GetAccessible(b.ID, user.ID)
+	if err != nil {
+		return notFound(err)
 	}
+	if err := requireRole(existing, model.RoleOwner); err != nil {
+		return err
+	}
+	b.UserID = user.ID
+	b.CreatedDate = existing.CreatedDate
+	b.TotalAmount = existing.TotalAmount
+	if b.Period == "" {
+		b.Period, b.PeriodDays, b.EndDate, b.Rollover = existing.Period, existing.PeriodDays, existing.EndDate, existing.Rollover
+	}
+	if err := b.ValidatePeriod(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if err := s.repository.Update(b); err != nil {
+		return notFound(err)
+	}
+
+	latest, err := s.periods.Latest(b.ID)
+	if err != nil {
+		return notFound(err)
+	}
+	end := b.PeriodEnd(latest.StartDate)
+	if end != nil && !end.After(latest.StartDate) {
+		return fmt.Errorf("%w: endDate must be after the start of the current period", ErrInvalid)
+	}
+	if err := s.periods.SetEnd(latest.ID, end); err != nil {
+		return err
+	}
+	latest.EndDate = end
+	_, err = advancePeriods(s.periods, b, latest, time.Now())
+	return err
+}
 
-	return s.repository.UpdateAmount(id, newAmount)
+// UpdateAmount adds to or subtracts from the total of a plan, converted to
+// its base currency, and records the change in the plan history.
+func (s *budgetPlanService) UpdateAmount(req *request.PlanAmountRequest, email string) (*model.PlanAdjustment, error) {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := s.repository.GetAccessible(req.ID, user.ID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if err := requireRole(plan, model.RoleEditor); err != nil {
+		return nil, err
+	}
+
+	currency := req.Currency
+	if currency == "" {
+		currency = plan.BaseCurrency()
+	}
+	on := req.Date
+	if on.IsZero() {
+		on = time.Now()
+	}
+	original := req.Amount.In(currency)
+	amount, rate, err := convert(s.rates, original, plan.BaseCurrency(), on)
+	if err != nil {
+		return nil, err
+	}
+	if !req.Add {
+		amount, original = amount.Neg(), original.Neg()
+	}
+
+	adjustment := &model.PlanAdjustment{
+		PlanID:         plan.ID,
+		Amount:         amount,
+		OriginalAmount: original,
+		ExchangeRate:   rate,
+		Reason:         req.Reason,
+		AuthorID:       &user.ID,
+	}
+	if err := s.repository.AdjustAmount(adjustment, user.ID); err != nil {
+		return nil, notFound(err)
+	}
+	return adjustment, nil
+}
+
+// History lists the changes to the total of a plan, newest first.
+func (s *budgetPlanService) History(id int, email string) ([]model.PlanAdjustment, error) {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.repository.GetAccessible(id, user.ID); err != nil {
+		return nil, notFound(err)
+	}
+	return s.repository.GetAdjustments(id, user.ID)
 }
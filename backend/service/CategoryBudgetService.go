@@ -0,0 +1,164 @@
+package service
+
+import (
+	"backend/model"
+	"backend/model/request"
+	"backend/repository"
+	"fmt"
+	"strings"
+)
+
+type CategoryBudgetService interface {
+	Save(req *request.CategoryBudgetRequest, email string) (*model.CategoryBudget, error)
+	Delete(planID int, categoryID int, email string) error
+	GetByPlan(planID int, email string) ([]model.CategoryBudget, error)
+	Status(q ReportQuery, email string) (*model.BudgetStatus, error)
+}
+
+type categoryBudgetService struct {
+	repository repository.CategoryBudgetRepository
+	budget     repository.BudgetPlanRepository
+	periods    repository.BudgetPeriodRepository
+	category   repository.CategoryRepository
+	user       repository.UserRepository
+}
+
+func NewCategoryBudgetService(factory *repository.RepositoryBase) CategoryBudgetService {
+	return &categoryBudgetService{
+		repository: repository.GetByType[repository.CategoryBudgetRepository](factory),
+		budget:     repository.GetByType[repository.BudgetPlanRepository](factory),
+		periods:    repository.GetByType[repository.BudgetPeriodRepository](factory),
+		category:   repository.GetByType[repository.CategoryRepository](factory),
+		user:       repository.GetByType[repository.UserRepository](factory),
+	}
+}
+
+// Save allocates the amount to the category, replacing any previous allocation.
+func (s *categoryBudgetService) Save(req *request.CategoryBudgetRequest, email string) (*model.CategoryBudget, error) {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := s.budget.GetAccessible(req.PlanID, user.ID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if err := requireRole(plan, model.RoleEditor); err != nil {
+		return nil, err
+	}
+	category, err := s.category.FindById(req.CategoryID, user.ID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	currency := plan.BaseCurrency()
+	if req.Currency != "" && !strings.EqualFold(req.Currency, currency) {
+		return nil, fmt.Errorf("%w: amount must be in the plan currency %s", ErrInvalid, currency)
+	}
+	if req.Amount.Minor < 0 {
+		return nil, fmt.Errorf("%w: amount must not be negative", ErrInvalid)
+	}
+
+	budget := &model.CategoryBudget{
+		PlanID:     plan.ID,
+		CategoryID: category.ID,
+		Amount:     req.Amount.In(currency),
+	}
+	if err := s.repository.Save(budget); err != nil {
+		return nil, err
+	}
+	budget.CategoryName = category.Name
+	return budget, nil
+}
+
+func (s *categoryBudgetService) Delete(planID int, categoryID int, email string) error {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return err
+	}
+	plan, err := s.budget.GetAccessible(planID, user.ID)
+	if err != nil {
+		return notFound(err)
+	}
+	if err := requireRole(plan, model.RoleEditor); err != nil {
+		return err
+	}
+	return notFound(s.repository.Delete(planID, categoryID))
+}
+
+func (s *categoryBudgetService) GetByPlan(planID int, email string) ([]model.CategoryBudget, error) {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.budget.GetAccessible(planID, user.ID); err != nil {
+		return nil, notFound(err)
+	}
+	return s.repository.GetByPlan(planID)
+}
+
+// Status compares every allocation of the plan with the spending in its
+// category and subcategories between q.From and q.To (inclusive).
+func (s *categoryBudgetService) Status(q ReportQuery, email string) (*model.BudgetStatus, error) {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := s.budget.GetAccessible(q.PlanID, user.ID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if err := applyPeriod(s.periods, &q, plan.ID); err != nil {
+		return nil, err
+	}
+	if q.From != nil && q.To != nil && q.From.After(*q.To) {
+		return nil, fmt.Errorf("%w: from must not be after to", ErrInvalid)
+	}
+
+	filter := repository.ReportFilter{PlanID: plan.ID, UserID: user.ID}
+	if q.From != nil {
+		filter.From = *q.From
+	}
+	if q.To != nil {
+		filter.To = q.To.AddDate(0, 0, 1)
+	}
+	budgets, err := s.repository.GetByPlan(plan.ID)
+	if err != nil {
+		return nil, err
+	}
+	spending, err := s.repository.Spending(filter)
+	if err != nil {
+		return nil, err
+	}
+	unallocated, err := s.repository.Unallocated(filter)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.repository.Total(filter)
+	if err != nil {
+		return nil, err
+	}
+	spent := make(map[int]int64, len(spending))
+	for _, row := range spending {
+		spent[row.CategoryID] = row.Spent
+	}
+
+	currency := plan.BaseCurrency()
+	status := &model.BudgetStatus{
+		PlanID:      plan.ID,
+		Currency:    currency,
+		From:        q.From,
+		To:          q.To,
+		Allocated:   model.NewMoney(0, currency),
+		Spent:       model.NewMoney(total, currency),
+		Unallocated: model.NewMoney(unallocated, currency),
+		Categories:  make([]model.CategoryBudgetStatus, 0, len(budgets)),
+	}
+	for _, b := range budgets {
+		row := model.NewCategoryBudgetStatus(b.CategoryID, b.CategoryName, b.Amount.In(currency), model.NewMoney(spent[b.CategoryID], currency))
+		status.Categories = append(status.Categories, row)
+		status.Allocated.Minor += row.Allocated.Minor
+		status.OverBudget = status.OverBudget || row.OverBudget
+	}
+	return status, nil
+}
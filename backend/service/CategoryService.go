@@ -3,57 +3,216 @@ package service
 import (
 	"backend/model"
 	"backend/repository"
-	"errors"
+	"fmt"
+	"strings"
 )
 
 type CategoryService interface {
-	NewCategory(category *model.Category) error
-	FindById(id int) (*model.Category, error)
-	FindByName(name string) (*model.Category, error)
-	FindAll() ([]model.Category, error)
-	Update(model *model.Category) error
-	Delete(id int) error
+	NewCategory(category *model.Category, email string) error
+	FindById(id int, email string) (*model.Category, error)
+	FindByName(name string, email string) (*model.Category, error)
+	FindAll(q CategoryQuery, list model.ListQuery, email string) (*model.Page[model.Category], error)
+	Tree(email string) ([]*model.Category, error)
+	Update(model *model.Category, email string) error
+	Delete(id int, version int, email string) error
 }
 
 type categoryRepository struct {
 	repository repository.CategoryRepository
+	user       repository.UserRepository
 }
 
 func NewCategoryService(factory *repository.RepositoryBase) CategoryService {
 	return &categoryRepository{
 		repository: repository.GetByType[repository.CategoryRepository](factory),
+		user:       repository.GetByType[repository.UserRepository](factory),
 	}
 }
 
-func (s *categoryRepository) NewCategory(category *model.Category) error {
-	existing, _ := s.repository.GetByName(category.Name)
-	if existing != nil {
-		return errors.New("category already exists")
+func (s *categoryRepository) NewCategory(category *model.Category, email string) error {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return err
+	}
+	category.UserID = user.ID
+	if err := s.validate(category); err != nil {
+		return err
 	}
 	return s.repository.Create(category)
 }
 
-func (s *categoryRepository) FindById(id int) (*model.Category, error) {
-	return s.repository.FindById(id)
+func (s *categoryRepository) FindById(id int, email string) (*model.Category, error) {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return nil, err
+	}
+	c, err := s.repository.FindById(id, user.ID)
+	return c, notFound(err)
+}
+
+// FindByName accepts a plain name or a path such as "Food > Restaurants".
+func (s *categoryRepository) FindByName(name string, email string) (*model.Category, error) {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return nil, err
+	}
+	category, err := findCategoryByPath(s.repository, user.ID, name)
+	if err != nil {
+		return nil, err
+	}
+	if category == nil {
+		return nil, ErrNotFound
+	}
+	return category, nil
 }
 
-func (s *categoryRepository) FindByName(name string) (*model.Category, error) {
-	return s.repository.GetByName(name)
+// CategoryQuery filters the categories of a user. A ParentID of zero selects
+// the top-level categories, any other the subcategories of that category.
+// Name matches part of the category name.
+type CategoryQuery struct {
+	ParentID *int
+	Name     string
 }
 
-func (s *categoryRepository) FindAll() ([]model.Category, error) {
-	return s.repository.FindAll()
+// FindAll lists a page of the caller's categories matched by q, with their
+// paths. Users that have no category at all, such as accounts created before
+// categories were per-user, get the default set first.
+func (s *categoryRepository) FindAll(q CategoryQuery, list model.ListQuery, email string) (*model.Page[model.Category], error) {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return nil, err
+	}
+	if list, err = list.Resolve(model.CategorySortFields, "path"); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	filter := repository.CategoryFilter{ParentID: q.ParentID, Name: strings.TrimSpace(q.Name)}
+	page, err := s.repository.List(user.ID, filter, list)
+	if err != nil {
+		return nil, err
+	}
+	if len(page.Items) == 0 && list.Cursor == nil {
+		if all, err := s.repository.FindAll(user.ID); err != nil || len(all) > 0 {
+			return page, err
+		}
+		if err := s.repository.Seed(user.ID); err != nil {
+			return nil, err
+		}
+		return s.repository.List(user.ID, filter, list)
+	}
+	return page, nil
 }
-func (s *categoryRepository) Update(model *model.Category) error {
-	return s.repository.Update(model)
+
+// Tree returns the caller's top-level categories with their subcategories as Children.
+func (s *categoryRepository) Tree(email string) ([]*model.Category, error) {
+	categories, err := allPages("path", func(list model.ListQuery) (*model.Page[model.Category], error) {
+		return s.FindAll(CategoryQuery{}, list, email)
+	})
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]*model.Category, len(categories))
+	for i := range categories {
+		byID[categories[i].ID] = &categories[i]
+	}
+	roots := []*model.Category{}
+	for i := range categories {
+		category := &categories[i]
+		if category.ParentID == nil {
+			roots = append(roots, category)
+		} else if parent, ok := byID[*category.ParentID]; ok {
+			parent.Children = append(parent.Children, category)
+		}
+	}
+	return roots, nil
 }
-func (s *categoryRepository) Delete(id int) error {
-	c, err := s.repository.FindById(id)
+
+func (s *categoryRepository) Update(model *model.Category, email string) error {
+	user, err := resolveUser(s.user, email)
 	if err != nil {
 		return err
 	}
-	if c == nil {
-		return errors.New("user not found")
+	if _, err := s.repository.FindById(model.ID, user.ID); err != nil {
+		return notFound(err)
+	}
+	model.UserID = user.ID
+	if err := s.validate(model); err != nil {
+		return err
+	}
+	return notFound(s.repository.Update(model))
+}
+
+// Delete removes a category and its subcategories, as long as no expense uses
+// them. A version other than 0 must be the current one.
+func (s *categoryRepository) Delete(id int, version int, email string) error {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return err
+	}
+	used, err := s.repository.IsUsed(id, user.ID)
+	if err != nil {
+		return err
+	}
+	if used {
+		return Conflict("category_in_use", "category is used by expenses", map[string]interface{}{"category_id": id})
+	}
+	return notFound(s.repository.Delete(id, user.ID, version))
+}
+
+// validate checks the name and the parent of a category owned by category.UserID.
+func (s *categoryRepository) validate(category *model.Category) error {
+	category.Name = strings.TrimSpace(category.Name)
+	if category.Name == "" {
+		return fmt.Errorf("%w: category name is required", ErrInvalid)
+	}
+	if strings.Contains(category.Name, strings.TrimSpace(model.CategoryPathSeparator)) {
+		return fmt.Errorf("%w: category name must not contain %q", ErrInvalid, strings.TrimSpace(model.CategoryPathSeparator))
+	}
+
+	if category.ParentID != nil {
+		if *category.ParentID == category.ID {
+			return fmt.Errorf("%w: a category cannot be its own parent", ErrInvalid)
+		}
+		parent, err := s.repository.FindById(*category.ParentID, category.UserID)
+		if err != nil {
+			return notFound(err)
+		}
+		if parent.ParentID != nil {
+			return fmt.Errorf("%w: subcategories cannot have subcategories", ErrInvalid)
+		}
+		if category.ID != 0 {
+			hasChildren, err := s.repository.HasChildren(category.ID, category.UserID)
+			if err != nil {
+				return err
+			}
+			if hasChildren {
+				return fmt.Errorf("%w: a category with subcategories cannot become a subcategory", ErrInvalid)
+			}
+		}
+	}
+
+	existing, err := s.repository.GetByName(category.Name, category.ParentID, category.UserID)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != category.ID && (existing.ParentID == nil) == (category.ParentID == nil) {
+		return Conflict("already_exists", "category already exists", map[string]interface{}{"name": category.Name})
+	}
+	return nil
+}
+
+// findCategoryByPath resolves "Parent > Child" or a plain name among the
+// categories of userID. It returns nil when nothing matches.
+func findCategoryByPath(categories repository.CategoryRepository, userID int, path string) (*model.Category, error) {
+	names := model.SplitCategoryPath(path)
+	switch len(names) {
+	case 0:
+		return nil, nil
+	case 1:
+		return categories.GetByName(names[0], nil, userID)
+	}
+	parent, err := categories.GetByName(names[0], nil, userID)
+	if err != nil || parent == nil || parent.ParentID != nil {
+		return nil, err
 	}
-	return s.repository.Delete(id)
+	return categories.GetByName(names[1], &parent.ID, userID)
 }
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"backend/model"
+	"backend/repository"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotFound is returned when a resource does not exist or is not visible to the caller.
+	ErrNotFound = errors.New("resource not found")
+	// ErrForbidden is returned when the caller can see a resource but is not allowed to change it.
+	ErrForbidden = errors.New("forbidden")
+	// ErrInvalid is returned when the input of an operation is malformed.
+	ErrInvalid = errors.New("invalid request")
+	// ErrUnauthorized is returned when the caller cannot be resolved from the request.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrConflict is returned when an operation clashes with the current state of a resource.
+	ErrConflict = errors.New("conflict")
+	// ErrPreconditionFailed is returned when a write conditioned on the
+	// version of a resource finds that it changed since it was read.
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+// Error is a domain error with a stable Code that clients can rely on and
+// that keys the translations of its message. Kind is the sentinel error it
+// belongs to, which decides how it is reported. Message is in English and
+// Details holds the values it mentions.
+type Error struct {
+	Kind    error
+	Code    string
+	Message string
+	Details map[string]interface{}
+}
+
+func (e *Error) Error() string {
+	return e.Kind.Error() + ": " + e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// NotFound returns an ErrNotFound domain error.
+func NotFound(code string, message string, details map[string]interface{}) *Error {
+	return &Error{Kind: ErrNotFound, Code: code, Message: message, Details: details}
+}
+
+// Conflict returns an ErrConflict domain error.
+func Conflict(code string, message string, details map[string]interface{}) *Error {
+	return &Error{Kind: ErrConflict, Code: code, Message: message, Details: details}
+}
+
+// Validation returns an ErrInvalid domain error.
+func Validation(code string, message string, details map[string]interface{}) *Error {
+	return &Error{Kind: ErrInvalid, Code: code, Message: message, Details: details}
+}
+
+// Forbidden returns an ErrForbidden domain error.
+func Forbidden(code string, message string, details map[string]interface{}) *Error {
+	return &Error{Kind: ErrForbidden, Code: code, Message: message, Details: details}
+}
+
+// Unauthorized returns an ErrUnauthorized domain error.
+func Unauthorized(code string, message string, details map[string]interface{}) *Error {
+	return &Error{Kind: ErrUnauthorized, Code: code, Message: message, Details: details}
+}
+
+// Classify turns the storage errors that escape the services into domain
+// errors: a missing row is ErrNotFound, a violated constraint or a value
+// the database cannot read is ErrConflict or ErrInvalid, and so is a cursor
+// rejected by a repository. Other errors are returned untouched and are
+// internal errors, whose text must not reach clients.
+func Classify(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if errors.Is(err, model.ErrInvalidCursor) {
+		return Validation("invalid_cursor", err.Error(), nil)
+	}
+	if errors.Is(err, repository.ErrStaleVersion) {
+		return &Error{Kind: ErrPreconditionFailed, Code: "precondition_failed", Message: "the resource changed since it was read"}
+	}
+	switch repository.SQLState(err) {
+	case "23505": // unique_violation
+		return Conflict("already_exists", "the resource already exists", nil)
+	case "23503": // foreign_key_violation
+		return Conflict("conflict", "the resource is referenced by or refers to another resource", nil)
+	case "23502", "23514", "22001", "22003", "22P02", "22007", "22008": // not_null, check, string too long, numeric out of range, malformed text, number or date
+		return Validation("validation_failed", "a value is missing or out of range", nil)
+	}
+	return err
+}
+
+// notFound translates a missing row into ErrNotFound and leaves other errors untouched.
+func notFound(err error) error {
+	return notFoundAs(err, ErrNotFound)
+}
+
+// notFoundAs translates a missing row into target and leaves other errors untouched.
+func notFoundAs(err error, target error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return target
+	}
+	return err
+}
+
+// resolveUser looks up the caller by the email stored in the JWT.
+func resolveUser(users repository.UserRepository, email string) (*model.User, error) {
+	if email == "" {
+		return nil, ErrUnauthorized
+	}
+	user, err := users.FindByEmail(email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUnauthorized
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// requireRole returns ErrForbidden unless the caller's role in the plan, as
+// loaded by BudgetPlanRepository.GetAccessible, grants role.
+func requireRole(plan *model.BudgetPlan, role model.PlanRole) error {
+	if !plan.Role.AtLeast(role) {
+		return Forbidden("role_required", fmt.Sprintf("requires the %s role in plan %d", role, plan.ID),
+			map[string]interface{}{"role": role, "plan_id": plan.ID})
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"backend/model"
+	"backend/repository"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExchangeRateProvider resolves how many units of `to` one unit of `from` was worth on a given day.
+type ExchangeRateProvider interface {
+	Rate(from string, to string, on time.Time) (float64, error)
+}
+
+// dbExchangeRateProvider reads the rates stored through ExchangeRateService.
+type dbExchangeRateProvider struct {
+	repository repository.ExchangeRateRepository
+}
+
+// NewDBExchangeRateProvider builds a provider backed by the exchange_rates table.
+func NewDBExchangeRateProvider(repo repository.ExchangeRateRepository) ExchangeRateProvider {
+	return &dbExchangeRateProvider{repository: repo}
+}
+
+// Rate looks up the latest rate on or before on, falling back to the inverse pair.
+func (p *dbExchangeRateProvider) Rate(from string, to string, on time.Time) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+	rate, err := p.repository.FindLatest(from, to, on)
+	if err == nil {
+		return rate.Rate, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+	inverse, err := p.repository.FindLatest(to, from, on)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("%w: no exchange rate from %s to %s on %s", ErrNotFound, from, to, on.Format("2006-01-02"))
+		}
+		return 0, err
+	}
+	return 1 / inverse.Rate, nil
+}
+
+// convert expresses amount in currency using the rate of the given day.
+func convert(rates ExchangeRateProvider, amount model.Money, currency string, on time.Time) (model.Money, float64, error) {
+	rate, err := rates.Rate(amount.CurrencyCode(), currency, on)
+	if err != nil {
+		return model.Money{}, 0, err
+	}
+	return amount.Convert(rate, currency), rate, nil
+}
+
+type ExchangeRateService interface {
+	ExchangeRateProvider
+	Create(rate *model.ExchangeRate) error
+	Import(r io.Reader) (int, error)
+	List(base string, quote string) ([]model.ExchangeRate, error)
+}
+
+type exchangeRateService struct {
+	ExchangeRateProvider
+	repository repository.ExchangeRateRepository
+}
+
+func NewExchangeRateService(factory *repository.RepositoryBase) ExchangeRateService {
+	repo := repository.GetByType[repository.ExchangeRateRepository](factory)
+	return &exchangeRateService{
+		ExchangeRateProvider: NewDBExchangeRateProvider(repo),
+		repository:           repo,
+	}
+}
+
+func (s *exchangeRateService) Create(rate *model.ExchangeRate) error {
+	if err := normalizeRate(rate); err != nil {
+		return err
+	}
+	if rate.Source == "" {
+		rate.Source = "manual"
+	}
+	return s.repository.Save(rate)
+}
+
+// Import reads a CSV with the columns date,base,quote,rate (header optional)
+// and stores every row, returning how many rates were saved.
+func (s *exchangeRateService) Import(r io.Reader) (int, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 4
+	reader.TrimLeadingSpace = true
+
+	var rates []*model.ExchangeRate
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+		if line == 1 && strings.EqualFold(record[0], "date") {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", record[0])
+		if err != nil {
+			return 0, fmt.Errorf("%w: line %d: invalid date %q", ErrInvalid, line, record[0])
+		}
+		value, err := strconv.ParseFloat(record[3], 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: line %d: invalid rate %q", ErrInvalid, line, record[3])
+		}
+		rate := &model.ExchangeRate{Base: record[1], Quote: record[2], Rate: value, Date: date, Source: "import"}
+		if err := normalizeRate(rate); err != nil {
+			return 0, fmt.Errorf("line %d: %w", line, err)
+		}
+		rates = append(rates, rate)
+	}
+
+	if err := s.repository.Save(rates...); err != nil {
+		return 0, err
+	}
+	return len(rates), nil
+}
+
+func (s *exchangeRateService) List(base string, quote string) ([]model.ExchangeRate, error) {
+	return s.repository.List(strings.ToUpper(base), strings.ToUpper(quote))
+}
+
+func normalizeRate(rate *model.ExchangeRate) error {
+	rate.Base = strings.ToUpper(strings.TrimSpace(rate.Base))
+	rate.Quote = strings.ToUpper(strings.TrimSpace(rate.Quote))
+	if len(rate.Base) != 3 || len(rate.Quote) != 3 {
+		return fmt.Errorf("%w: currencies must be ISO-4217 codes", ErrInvalid)
+	}
+	if rate.Base == rate.Quote {
+		return fmt.Errorf("%w: base and quote currencies must differ", ErrInvalid)
+	}
+	if rate.Rate <= 0 {
+		return fmt.Errorf("%w: rate must be positive", ErrInvalid)
+	}
+	rate.Date = rate.Date.UTC().Truncate(24 * time.Hour)
+	return nil
+}
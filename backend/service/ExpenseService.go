@@ -3,61 +3,329 @@ package service
 import (
 	"backend/model"
 	"backend/repository"
-	"errors"
+	"fmt"
+	"strings"
 )
 
 type ExpenseService interface {
-	NewExpense(expense *model.Expense) error
-	DeleteExpense(id int, plan int) error
-	GetByPlan(id int) ([]model.Expense, error)
-	GetByCategory(id int) ([]model.Expense, error)
-	Update(model *model.Expense) error
+	NewExpense(expense *model.Expense, email string) error
+	DeleteExpense(id int, plan int, version int, email string) error
+	Get(id int, plan int, email string) (*model.Expense, error)
+	GetByPlan(q ExpenseQuery, list model.ListQuery, email string) (*model.Page[model.Expense], error)
+	GetByCategory(id int, list model.ListQuery, email string) (*model.Page[model.Expense], error)
+	Search(search model.ExpenseSearch, email string) (*model.SearchPage, error)
+	Update(model *model.Expense, email string) error
 }
 
 type expenseRepository struct {
-	repository repository.ExpensesRepository
-	budget     repository.BudgetPlanRepository
-	category   repository.CategoryRepository
+	repository  repository.ExpensesRepository
+	budget      repository.BudgetPlanRepository
+	members     repository.PlanMemberRepository
+	splits      repository.SplitRepository
+	rules       repository.RuleRepository
+	attachments repository.AttachmentRepository
+	periods     repository.BudgetPeriodRepository
+	category    repository.CategoryRepository
+	user        repository.UserRepository
+	rates       ExchangeRateProvider
 }
 
 func NewExpensesService(factory *repository.RepositoryBase) ExpenseService {
 	return &expenseRepository{
-		repository: repository.GetByType[repository.ExpensesRepository](factory),
-		budget:     repository.GetByType[repository.BudgetPlanRepository](factory),
-		category:   repository.GetByType[repository.CategoryRepository](factory),
+		repository:  repository.GetByType[repository.ExpensesRepository](factory),
+		budget:      repository.GetByType[repository.BudgetPlanRepository](factory),
+		members:     repository.GetByType[repository.PlanMemberRepository](factory),
+		splits:      repository.GetByType[repository.SplitRepository](factory),
+		rules:       repository.GetByType[repository.RuleRepository](factory),
+		attachments: repository.GetByType[repository.AttachmentRepository](factory),
+		periods:     repository.GetByType[repository.BudgetPeriodRepository](factory),
+		category:    repository.GetByType[repository.CategoryRepository](factory),
+		user:        repository.GetByType[repository.UserRepository](factory),
+		rates:       NewDBExchangeRateProvider(repository.GetByType[repository.ExchangeRateRepository](factory)),
 	}
 }
 
-func (s *expenseRepository) NewExpense(expense *model.Expense) error {
-	b, _ := s.budget.GetByID(expense.BudgetID)
-	if &b == nil {
-		return errors.New("plan not found")
+// NewExpense adds an expense to a plan. The caller's rules fill in the
+// category when none is given and add their tags.
+func (s *expenseRepository) NewExpense(expense *model.Expense, email string) error {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return err
 	}
-	c, _ := s.category.FindById(expense.CategoryID)
-
-	if &c == nil {
-		return errors.New("category not found")
+	plan, err := s.budget.GetAccessible(expense.BudgetID, user.ID)
+	if err != nil {
+		return notFound(err)
+	}
+	if err := requireRole(plan, model.RoleEditor); err != nil {
+		return err
+	}
+	if expense.PaidBy == nil {
+		expense.PaidBy = &user.ID
+	} else if err := s.checkPayer(plan.ID, *expense.PaidBy); err != nil {
+		return err
+	}
+	if err := toBaseCurrency(s.rates, expense, plan); err != nil {
+		return err
+	}
+	if expense.Tags, err = model.NormalizeTags(expense.Tags); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if _, err := applyRules(s.rules, s.category, user.ID, plan.ID, []*model.Expense{expense}, false); err != nil {
+		return err
+	}
+	if expense.CategoryID == 0 {
+		return fmt.Errorf("%w: category_id is required when no rule categorizes the expense", ErrInvalid)
+	}
+	category, err := s.category.FindById(expense.CategoryID, user.ID)
+	if err != nil {
+		return notFound(err)
 	}
+	expense.CategoryName = category.Name
 	return s.repository.Create(expense)
 }
 
-func (s *expenseRepository) DeleteExpense(id int, plan int) error {
-	bError := s.budget.DeleteExpense(plan, id)
-	if bError != nil {
-		return bError
+func (s *expenseRepository) DeleteExpense(id int, plan int, version int, email string) error {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return err
+	}
+	existing, err := s.repository.GetByID(id, user.ID)
+	if err != nil {
+		return notFound(err)
+	}
+	if existing.BudgetID != plan {
+		return ErrNotFound
+	}
+	budgetPlan, err := s.budget.GetAccessible(plan, user.ID)
+	if err != nil {
+		return notFound(err)
+	}
+	if err := requireRole(budgetPlan, model.RoleEditor); err != nil {
+		return err
+	}
+	if err := s.repository.Delete(id, version); err != nil {
+		return err
+	}
+	// the attachments went with the expense, their blobs follow
+	purgeBlobs(s.attachments)
+	return nil
+}
+
+// Get returns the expense id of the plan given by plan, with its tags.
+func (s *expenseRepository) Get(id int, plan int, email string) (*model.Expense, error) {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return nil, err
+	}
+	expense, err := s.repository.GetByID(id, user.ID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if expense.BudgetID != plan {
+		return nil, ErrNotFound
+	}
+	return expense, nil
+}
+
+// ExpenseQuery filters the expenses of a plan. It extends ReportQuery with
+// the category, including its subcategories, the member who paid, an amount
+// range in the plan's base currency and tags.
+type ExpenseQuery struct {
+	ReportQuery
+	CategoryID int
+	PaidBy     int
+	MinAmount  *model.Money
+	MaxAmount  *model.Money
+	Tags       model.TagFilter
+}
+
+// GetByPlan lists a page of the expenses of a plan matched by q.
+func (s *expenseRepository) GetByPlan(q ExpenseQuery, list model.ListQuery, email string) (*model.Page[model.Expense], error) {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := s.budget.GetAccessible(q.PlanID, user.ID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if list, err = list.Resolve(model.ExpenseSortFields, "-date"); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if err := applyPeriod(s.periods, &q.ReportQuery, plan.ID); err != nil {
+		return nil, err
+	}
+	if q.From != nil && q.To != nil && q.From.After(*q.To) {
+		return nil, fmt.Errorf("%w: from must not be after to", ErrInvalid)
+	}
+	filter := repository.ExpenseFilter{CategoryID: q.CategoryID, PaidBy: q.PaidBy, Recurring: q.Recurring, Tags: q.Tags}
+	if q.From != nil {
+		filter.From = *q.From
+	}
+	if q.To != nil {
+		filter.To = q.To.AddDate(0, 0, 1)
+	}
+	if q.MinAmount != nil {
+		minor := q.MinAmount.In(plan.BaseCurrency()).Minor
+		filter.MinAmount = &minor
+	}
+	if q.MaxAmount != nil {
+		minor := q.MaxAmount.In(plan.BaseCurrency()).Minor
+		filter.MaxAmount = &minor
+	}
+	if filter.MinAmount != nil && filter.MaxAmount != nil && *filter.MinAmount > *filter.MaxAmount {
+		return nil, fmt.Errorf("%w: min_amount must not be greater than max_amount", ErrInvalid)
+	}
+	return s.repository.GetByPlan(plan.ID, user.ID, filter, list)
+}
+
+// GetByCategory lists a page of the expenses of a category and its
+// subcategories across the plans of the caller.
+func (s *expenseRepository) GetByCategory(id int, list model.ListQuery, email string) (*model.Page[model.Expense], error) {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return nil, err
 	}
-	err := s.repository.Delete(id)
-	return err
+	if list, err = list.Resolve(model.ExpenseSortFields, "-date"); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	return s.repository.GetByCategory(id, user.ID, list)
 }
 
-func (s *expenseRepository) GetByPlan(id int) ([]model.Expense, error) {
-	return s.repository.GetByPlan(id)
+// Search runs a full-text search over the expenses of the plans the caller is
+// a member of. Amounts are compared in the plan's base currency unless a
+// currency is given, which is required to filter by amount across plans.
+func (s *expenseRepository) Search(search model.ExpenseSearch, email string) (*model.SearchPage, error) {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return nil, err
+	}
+	if search.PlanID != 0 {
+		plan, err := s.budget.GetAccessible(search.PlanID, user.ID)
+		if err != nil {
+			return nil, notFound(err)
+		}
+		if search.Currency == "" {
+			search.Currency = plan.BaseCurrency()
+		}
+	}
+	if search.MinAmount != nil || search.MaxAmount != nil {
+		if search.Currency == "" {
+			return nil, fmt.Errorf("%w: currency is required to filter by amount across plans", ErrInvalid)
+		}
+		search.Currency = strings.ToUpper(search.Currency)
+		for _, amount := range []*model.Money{search.MinAmount, search.MaxAmount} {
+			if amount != nil {
+				*amount = amount.In(search.Currency)
+			}
+		}
+		if search.MinAmount != nil && search.MaxAmount != nil && search.MinAmount.Minor > search.MaxAmount.Minor {
+			return nil, fmt.Errorf("%w: min_amount must not be greater than max_amount", ErrInvalid)
+		}
+	}
+	if search.From != nil && search.To != nil && search.From.After(*search.To) {
+		return nil, fmt.Errorf("%w: from must not be after to", ErrInvalid)
+	}
+	switch {
+	case search.Limit <= 0:
+		search.Limit = model.DefaultSearchLimit
+	case search.Limit > model.MaxSearchLimit:
+		search.Limit = model.MaxSearchLimit
+	}
+	search.Query = strings.TrimSpace(search.Query)
+
+	// fetch one more than asked for to know whether there is a next page
+	limit := search.Limit
+	search.Limit++
+	expenses, err := s.repository.Search(search, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	page := &model.SearchPage{Items: expenses}
+	if len(expenses) > limit {
+		page.Items = expenses[:limit]
+		page.NextCursor = model.CursorAt(&page.Items[limit-1]).Encode()
+	}
+	if page.Items == nil {
+		page.Items = []model.Expense{}
+	}
+	return page, nil
+}
+
+// Update edits an expense. When it is split, the shares are rescaled to the
+// new amount. Nil Tags keep the current tags; an empty list removes them.
+func (s *expenseRepository) Update(expense *model.Expense, email string) error {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return err
+	}
+	existing, err := s.repository.GetByID(expense.ID, user.ID)
+	if err != nil {
+		return notFound(err)
+	}
+	plan, err := s.budget.GetAccessible(existing.BudgetID, user.ID)
+	if err != nil {
+		return notFound(err)
+	}
+	if err := requireRole(plan, model.RoleEditor); err != nil {
+		return err
+	}
+	if expense.PaidBy == nil {
+		expense.PaidBy = existing.PaidBy
+	} else if err := s.checkPayer(plan.ID, *expense.PaidBy); err != nil {
+		return err
+	}
+	if expense.CategoryID == 0 {
+		expense.CategoryID, expense.CategoryName = existing.CategoryID, existing.CategoryName
+	} else {
+		category, err := s.category.FindById(expense.CategoryID, user.ID)
+		if err != nil {
+			return notFound(err)
+		}
+		expense.CategoryName = category.Name
+	}
+	expense.Amount = expense.Amount.In(existing.Amount.CurrencyCode())
+	if err := toBaseCurrency(s.rates, expense, plan); err != nil {
+		return err
+	}
+	if expense.Tags != nil {
+		if expense.Tags, err = model.NormalizeTags(expense.Tags); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+	}
+	expense.BudgetID = existing.BudgetID
+	expense.SplitMethod = existing.SplitMethod
+	// keep the shares of a split expense in proportion to its new amount
+	var splits []model.ExpenseSplit
+	if existing.SplitMethod != nil && expense.BaseAmount != existing.BaseAmount {
+		current, err := s.splits.GetByExpense(expense.ID)
+		if err != nil {
+			return err
+		}
+		if len(current) > 0 {
+			splits = model.RescaleSplits(current, expense.BaseAmount)
+		}
+	}
+	return s.repository.Update(expense, splits)
 }
 
-func (s *expenseRepository) GetByCategory(id int) ([]model.Expense, error) {
-	return s.repository.GetByCategory(id)
+// checkPayer verifies that the user who paid an expense is a member of its plan.
+func (s *expenseRepository) checkPayer(planID int, userID int) error {
+	if _, err := s.members.Get(planID, userID); err != nil {
+		return notFoundAs(err, fmt.Errorf("%w: paid_by %d is not a member of plan %d", ErrInvalid, userID, planID))
+	}
+	return nil
 }
 
-func (s *expenseRepository) Update(model *model.Expense) error {
-	return s.repository.Update(model)
+// toBaseCurrency fills BaseAmount and ExchangeRate using the rate on the expense date.
+// Expenses without a currency are assumed to be in the plan's base currency.
+func toBaseCurrency(rates ExchangeRateProvider, expense *model.Expense, plan *model.BudgetPlan) error {
+	expense.Amount = expense.Amount.In(plan.BaseCurrency())
+	base, rate, err := convert(rates, expense.Amount, plan.BaseCurrency(), expense.Date)
+	if err != nil {
+		return err
+	}
+	expense.BaseAmount = base
+	expense.ExchangeRate = rate
+	return nil
 }
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"backend/model"
+	"backend/repository"
+	"backend/statement"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ExportQuery selects what to export. A zero PlanID exports every plan of the
+// caller; To is inclusive.
+type ExportQuery struct {
+	Format string
+	PlanID int
+	From   *time.Time
+	To     *time.Time
+}
+
+// ExportStream writes an export once the caller has set up the response.
+type ExportStream struct {
+	ContentType string
+	Extension   string
+	WriteTo     func(w io.Writer) error
+}
+
+type ExportService interface {
+	Export(q ExportQuery, email string) (*ExportStream, error)
+}
+
+type exportService struct {
+	expenses repository.ExpensesRepository
+	budget   repository.BudgetPlanRepository
+	category repository.CategoryRepository
+	user     repository.UserRepository
+}
+
+func NewExportService(factory *repository.RepositoryBase) ExportService {
+	return &exportService{
+		expenses: repository.GetByType[repository.ExpensesRepository](factory),
+		budget:   repository.GetByType[repository.BudgetPlanRepository](factory),
+		category: repository.GetByType[repository.CategoryRepository](factory),
+		user:     repository.GetByType[repository.UserRepository](factory),
+	}
+}
+
+// Export validates the query and loads the plans and categories up front, so
+// that errors can still be reported with a status code. Expenses are only
+// read, one row at a time, once the returned stream is written.
+func (s *exportService) Export(q ExportQuery, email string) (*ExportStream, error) {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return nil, err
+	}
+	if q.From != nil && q.To != nil && q.From.After(*q.To) {
+		return nil, fmt.Errorf("%w: from must not be after to", ErrInvalid)
+	}
+	switch q.Format {
+	case statement.ExportCSV, statement.ExportJSON, statement.ExportOFX:
+	default:
+		return nil, fmt.Errorf("%w: format must be csv, json or ofx", ErrInvalid)
+	}
+
+	var plans []model.BudgetPlan
+	if q.PlanID != 0 {
+		plan, err := s.budget.GetAccessible(q.PlanID, user.ID)
+		if err != nil {
+			return nil, notFound(err)
+		}
+		plans = []model.BudgetPlan{*plan}
+	} else if plans, err = s.budget.ListByUser(user.ID); err != nil {
+		return nil, err
+	}
+	categories, err := s.category.FindAll(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var from, to time.Time
+	if q.From != nil {
+		from = *q.From
+	}
+	if q.To != nil {
+		to = q.To.AddDate(0, 0, 1)
+	}
+	header := statement.ExportHeader{
+		ExportedAt: time.Now().UTC(),
+		From:       q.From,
+		To:         q.To,
+		Categories: categories,
+		Plans:      plans,
+	}
+
+	return &ExportStream{
+		ContentType: statement.ContentType(q.Format),
+		Extension:   q.Format,
+		WriteTo: func(w io.Writer) error {
+			exporter, err := statement.NewExporter(q.Format, w)
+			if err != nil {
+				return err
+			}
+			if err := exporter.Begin(header); err != nil {
+				return err
+			}
+			for i := range plans {
+				plan := &plans[i]
+				if err := s.expenses.EachByPlan(plan.ID, user.ID, from, to, func(expense *model.Expense) error {
+					return exporter.Expense(plan, expense)
+				}); err != nil {
+					return err
+				}
+			}
+			return exporter.End()
+		},
+	}, nil
+}
@@ -0,0 +1,372 @@
+package service
+
+import (
+	"backend/model"
+	"backend/model/request"
+	"backend/repository"
+	"backend/statement"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Statement formats accepted by ImportService.
+const (
+	FormatCSV = "csv"
+	FormatOFX = "ofx"
+	FormatQFX = "qfx"
+)
+
+type ImportService interface {
+	Preview(planID int, format string, r io.Reader, mapping statement.CSVMapping, email string) (*model.ImportPreview, error)
+	Commit(req *request.ImportCommitRequest, email string) (*model.ImportResult, error)
+	Restore(r io.Reader, email string) (*model.RestoreResult, error)
+}
+
+type importService struct {
+	expenses repository.ExpensesRepository
+	rules    repository.RuleRepository
+	budget   repository.BudgetPlanRepository
+	category repository.CategoryRepository
+	user     repository.UserRepository
+	restore  repository.RestoreRepository
+	rates    ExchangeRateProvider
+}
+
+func NewImportService(factory *repository.RepositoryBase) ImportService {
+	return &importService{
+		expenses: repository.GetByType[repository.ExpensesRepository](factory),
+		rules:    repository.GetByType[repository.RuleRepository](factory),
+		budget:   repository.GetByType[repository.BudgetPlanRepository](factory),
+		category: repository.GetByType[repository.CategoryRepository](factory),
+		user:     repository.GetByType[repository.UserRepository](factory),
+		restore:  repository.GetByType[repository.RestoreRepository](factory),
+		rates:    NewDBExchangeRateProvider(repository.GetByType[repository.ExchangeRateRepository](factory)),
+	}
+}
+
+// Preview parses a statement and returns the rows it would import, without
+// writing anything. Credits are skipped since plans only track expenses.
+// Rows the statement does not categorize are categorized by the caller's
+// rules, which also suggest tags.
+func (s *importService) Preview(planID int, format string, r io.Reader, mapping statement.CSVMapping, email string) (*model.ImportPreview, error) {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := s.budget.GetAccessible(planID, user.ID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	var entries []statement.Entry
+	format = strings.ToLower(format)
+	switch format {
+	case FormatCSV:
+		if mapping.Currency == "" {
+			mapping.Currency = plan.BaseCurrency()
+		}
+		entries, err = statement.ParseCSV(r, mapping)
+	case FormatOFX, FormatQFX:
+		entries, err = statement.ParseOFX(r, plan.BaseCurrency())
+	default:
+		return nil, fmt.Errorf("%w: unsupported format %q", ErrInvalid, format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	preview := &model.ImportPreview{PlanID: plan.ID, Format: format, Rows: []model.ImportRow{}}
+	categories := make(map[string]*model.Category)
+	for _, entry := range entries {
+		row := model.ImportRow{
+			Line:        entry.Line,
+			Date:        entry.Date,
+			Amount:      entry.Amount.Neg(),
+			Description: entry.Description,
+		}
+		switch {
+		case entry.Amount.IsZero():
+			row.SkipReason = "zero amount"
+		case entry.Amount.Minor > 0:
+			row.SkipReason = "credit"
+		}
+		if entry.Category != "" {
+			category, ok := categories[entry.Category]
+			if !ok {
+				category, _ = findCategoryByPath(s.category, user.ID, entry.Category)
+				categories[entry.Category] = category
+			}
+			if category != nil {
+				row.CategoryID = category.ID
+				row.CategoryName = category.Name
+			}
+		}
+		preview.Rows = append(preview.Rows, row)
+	}
+
+	if err := s.categorize(preview.Rows, plan, user.ID); err != nil {
+		return nil, err
+	}
+	if preview.Duplicates, err = s.markDuplicates(preview.Rows, plan.ID, user.ID); err != nil {
+		return nil, err
+	}
+	for _, row := range preview.Rows {
+		if row.SkipReason != "" {
+			preview.Skipped++
+		}
+	}
+	return preview, nil
+}
+
+// Commit inserts the rows of a preview in a single transaction. Duplicates
+// are detected again and left out unless AllowDuplicates is set. Rows without
+// a category are categorized by the caller's rules before falling back to
+// DefaultCategoryID.
+func (s *importService) Commit(req *request.ImportCommitRequest, email string) (*model.ImportResult, error) {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := s.budget.GetAccessible(req.PlanID, user.ID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if err := requireRole(plan, model.RoleEditor); err != nil {
+		return nil, err
+	}
+
+	result := &model.ImportResult{PlanID: plan.ID, Expenses: []model.Expense{}}
+	if result.Duplicates, err = s.markDuplicates(req.Rows, plan.ID, user.ID); err != nil {
+		return nil, err
+	}
+
+	var lines []int
+	var expenses []*model.Expense
+	for _, row := range req.Rows {
+		if row.SkipReason != "" || (row.DuplicateOf != nil && !req.AllowDuplicates) {
+			result.Skipped++
+			continue
+		}
+		if row.Date.IsZero() {
+			return nil, fmt.Errorf("%w: line %d: date is required", ErrInvalid, row.Line)
+		}
+		if row.Amount.Minor <= 0 {
+			return nil, fmt.Errorf("%w: line %d: amount must be positive", ErrInvalid, row.Line)
+		}
+		tags, err := model.NormalizeTags(row.Tags)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalid, row.Line, err)
+		}
+		expense := &model.Expense{
+			Amount:      row.Amount,
+			Description: row.Description,
+			CategoryID:  row.CategoryID,
+			Date:        row.Date,
+			BudgetID:    plan.ID,
+			PaidBy:      &user.ID,
+			Tags:        tags,
+		}
+		if err := toBaseCurrency(s.rates, expense, plan); err != nil {
+			return nil, err
+		}
+		lines = append(lines, row.Line)
+		expenses = append(expenses, expense)
+	}
+	if _, err := applyRules(s.rules, s.category, user.ID, plan.ID, expenses, false); err != nil {
+		return nil, err
+	}
+
+	categories := make(map[int]*model.Category)
+	for i, expense := range expenses {
+		if expense.CategoryID == 0 {
+			expense.CategoryID = req.DefaultCategoryID
+		}
+		if expense.CategoryID == 0 {
+			return nil, fmt.Errorf("%w: line %d: category is required", ErrInvalid, lines[i])
+		}
+		category, ok := categories[expense.CategoryID]
+		if !ok {
+			if category, err = s.category.FindById(expense.CategoryID, user.ID); err != nil {
+				return nil, notFound(err)
+			}
+			categories[expense.CategoryID] = category
+		}
+		expense.CategoryName = category.Name
+	}
+
+	if err := s.expenses.CreateBatch(expenses); err != nil {
+		return nil, err
+	}
+	for _, expense := range expenses {
+		result.Expenses = append(result.Expenses, *expense)
+	}
+	result.Imported = len(expenses)
+	return result, nil
+}
+
+// Restore recreates the plans and expenses of a JSON export for the caller.
+// Categories are matched by name and created when missing; plans always get
+// new IDs so restoring twice yields two copies instead of merging. Every
+// reference of the document is checked before anything is written, and the
+// whole document is written in one transaction.
+func (s *importService) Restore(r io.Reader, email string) (*model.RestoreResult, error) {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return nil, err
+	}
+	var doc model.ExportDocument
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if doc.Version != statement.ExportVersion {
+		return nil, fmt.Errorf("%w: unsupported export version %d", ErrInvalid, doc.Version)
+	}
+
+	restore := &model.ExportDocument{Version: doc.Version, ExportedAt: doc.ExportedAt}
+	// parents first, so that subcategories can be attached to them
+	sort.SliceStable(doc.Categories, func(i, j int) bool {
+		return doc.Categories[i].ParentID == nil && doc.Categories[j].ParentID != nil
+	})
+	categories := make(map[int]bool, len(doc.Categories))
+	for _, c := range doc.Categories {
+		if c.ParentID != nil && !categories[*c.ParentID] {
+			return nil, fmt.Errorf("%w: category %d refers to unknown parent %d", ErrInvalid, c.ID, *c.ParentID)
+		}
+		categories[c.ID] = true
+		restore.Categories = append(restore.Categories, model.Category{ID: c.ID, Name: c.Name, ParentID: c.ParentID})
+	}
+
+	plans := make(map[int]*model.BudgetPlan, len(doc.Plans))
+	for i := range doc.Plans {
+		exported := doc.Plans[i]
+		plan := model.BudgetPlan{
+			ID:          exported.ID,
+			Name:        exported.Name,
+			TotalAmount: model.NewMoney(exported.TotalAmount.Minor, exported.TotalAmount.Currency),
+			Description: exported.Description,
+			CreatedDate: exported.CreatedDate,
+			Period:      exported.Period,
+			PeriodDays:  exported.PeriodDays,
+			EndDate:     exported.EndDate,
+			Rollover:    exported.Rollover,
+			UserID:      user.ID,
+		}
+		if err := plan.ValidatePeriod(); err != nil {
+			return nil, fmt.Errorf("%w: plan %d: %v", ErrInvalid, exported.ID, err)
+		}
+		restore.Plans = append(restore.Plans, plan)
+		plans[exported.ID] = &plan
+	}
+
+	for _, e := range doc.Expenses {
+		if !categories[e.CategoryID] {
+			return nil, fmt.Errorf("%w: expense %d refers to unknown category %d", ErrInvalid, e.ID, e.CategoryID)
+		}
+		plan, ok := plans[e.BudgetID]
+		if !ok {
+			return nil, fmt.Errorf("%w: expense %d refers to unknown plan %d", ErrInvalid, e.ID, e.BudgetID)
+		}
+		restore.Expenses = append(restore.Expenses, model.Expense{
+			ID:           e.ID,
+			Amount:       model.NewMoney(e.Amount.Minor, e.Amount.Currency),
+			Description:  e.Description,
+			CategoryID:   e.CategoryID,
+			Date:         e.Date,
+			IsRecurring:  e.IsRecurring,
+			BudgetID:     e.BudgetID,
+			PaidBy:       &user.ID,
+			ExchangeRate: e.ExchangeRate,
+			BaseAmount:   e.BaseAmount.In(plan.BaseCurrency()),
+		})
+	}
+	return s.restore.Restore(user.ID, restore)
+}
+
+// categorize runs the rules of userID over the rows that will be imported,
+// filling in missing categories and suggesting tags.
+func (s *importService) categorize(rows []model.ImportRow, plan *model.BudgetPlan, userID int) error {
+	var indexes []int
+	var expenses []*model.Expense
+	for i, row := range rows {
+		if row.SkipReason != "" {
+			continue
+		}
+		expense := &model.Expense{
+			Amount:       row.Amount,
+			Description:  row.Description,
+			CategoryID:   row.CategoryID,
+			CategoryName: row.CategoryName,
+			Date:         row.Date,
+			BudgetID:     plan.ID,
+			// previews do not convert amounts, so rules see the statement currency
+			BaseAmount: row.Amount,
+		}
+		indexes = append(indexes, i)
+		expenses = append(expenses, expense)
+	}
+	if _, err := applyRules(s.rules, s.category, userID, plan.ID, expenses, false); err != nil {
+		return err
+	}
+	for j, expense := range expenses {
+		row := &rows[indexes[j]]
+		row.CategoryID, row.CategoryName, row.Tags = expense.CategoryID, expense.CategoryName, expense.Tags
+	}
+	return nil
+}
+
+// markDuplicates sets DuplicateOf on the rows matching an existing expense of
+// the plan by day, amount and description, and returns how many matched. Each
+// existing expense matches at most one row, so repeated purchases on the same
+// day are only flagged as often as they were already recorded.
+func (s *importService) markDuplicates(rows []model.ImportRow, planID int, userID int) (int, error) {
+	var from, to time.Time
+	for _, row := range rows {
+		if row.Date.IsZero() {
+			continue
+		}
+		if from.IsZero() || row.Date.Before(from) {
+			from = row.Date
+		}
+		if row.Date.After(to) {
+			to = row.Date
+		}
+	}
+	if from.IsZero() {
+		return 0, nil
+	}
+
+	existing, err := s.expenses.GetByPlanBetween(planID, userID, from.UTC().Truncate(24*time.Hour), to.UTC().Truncate(24*time.Hour).AddDate(0, 0, 1).Add(-time.Nanosecond))
+	if err != nil {
+		return 0, err
+	}
+	byKey := make(map[string][]int)
+	for _, expense := range existing {
+		key := duplicateKey(expense.Date, expense.Amount, expense.Description)
+		byKey[key] = append(byKey[key], expense.ID)
+	}
+
+	duplicates := 0
+	for i := range rows {
+		rows[i].DuplicateOf = nil
+		if rows[i].SkipReason != "" {
+			continue
+		}
+		key := duplicateKey(rows[i].Date, rows[i].Amount, rows[i].Description)
+		if ids := byKey[key]; len(ids) > 0 {
+			id := ids[0]
+			rows[i].DuplicateOf = &id
+			byKey[key] = ids[1:]
+			duplicates++
+		}
+	}
+	return duplicates, nil
+}
+
+func duplicateKey(date time.Time, amount model.Money, description string) string {
+	return fmt.Sprintf("%s|%d|%s|%s", date.Format("2006-01-02"), amount.Minor, amount.CurrencyCode(),
+		strings.Join(strings.Fields(strings.ToLower(description)), " "))
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"backend/model"
+	"backend/repository"
+	"fmt"
+	"time"
+)
+
+type IncomeService interface {
+	Create(income *model.Income, email string) error
+	Update(income *model.Income, email string) error
+	Delete(id int, email string) error
+	GetByPlan(q ReportQuery, email string) ([]model.Income, error)
+	Balance(q ReportQuery, email string) (*model.Balance, error)
+}
+
+type incomeService struct {
+	repository repository.IncomeRepository
+	budget     repository.BudgetPlanRepository
+	periods    repository.BudgetPeriodRepository
+	user       repository.UserRepository
+	rates      ExchangeRateProvider
+}
+
+func NewIncomeService(factory *repository.RepositoryBase) IncomeService {
+	return &incomeService{
+		repository: repository.GetByType[repository.IncomeRepository](factory),
+		budget:     repository.GetByType[repository.BudgetPlanRepository](factory),
+		periods:    repository.GetByType[repository.BudgetPeriodRepository](factory),
+		user:       repository.GetByType[repository.UserRepository](factory),
+		rates:      NewDBExchangeRateProvider(repository.GetByType[repository.ExchangeRateRepository](factory)),
+	}
+}
+
+func (s *incomeService) Create(income *model.Income, email string) error {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return err
+	}
+	plan, err := s.budget.GetAccessible(income.BudgetID, user.ID)
+	if err != nil {
+		return notFound(err)
+	}
+	if err := requireRole(plan, model.RoleEditor); err != nil {
+		return err
+	}
+	if err := s.toBaseCurrency(income, plan); err != nil {
+		return err
+	}
+	return s.repository.Create(income)
+}
+
+// Update edits an income. Its plan cannot change.
+func (s *incomeService) Update(income *model.Income, email string) error {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return err
+	}
+	existing, err := s.repository.GetByID(income.ID, user.ID)
+	if err != nil {
+		return notFound(err)
+	}
+	plan, err := s.budget.GetAccessible(existing.BudgetID, user.ID)
+	if err != nil {
+		return notFound(err)
+	}
+	if err := requireRole(plan, model.RoleEditor); err != nil {
+		return err
+	}
+	income.BudgetID = existing.BudgetID
+	income.Amount = income.Amount.In(existing.Amount.CurrencyCode())
+	if err := s.toBaseCurrency(income, plan); err != nil {
+		return err
+	}
+	return notFound(s.repository.Update(income))
+}
+
+func (s *incomeService) Delete(id int, email string) error {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return err
+	}
+	existing, err := s.repository.GetByID(id, user.ID)
+	if err != nil {
+		return notFound(err)
+	}
+	plan, err := s.budget.GetAccessible(existing.BudgetID, user.ID)
+	if err != nil {
+		return notFound(err)
+	}
+	if err := requireRole(plan, model.RoleEditor); err != nil {
+		return err
+	}
+	return notFound(s.repository.Delete(id))
+}
+
+// GetByPlan lists the incomes of a plan, optionally limited to a period or
+// to the dates between q.From and q.To (inclusive).
+func (s *incomeService) GetByPlan(q ReportQuery, email string) ([]model.Income, error) {
+	_, filter, err := s.filter(&q, email)
+	if err != nil {
+		return nil, err
+	}
+	return s.repository.GetByPlan(filter)
+}
+
+// Balance sums the incomes and expenses of a plan over a period or range of
+// dates and carries the net of everything before it as the opening balance.
+func (s *incomeService) Balance(q ReportQuery, email string) (*model.Balance, error) {
+	plan, filter, err := s.filter(&q, email)
+	if err != nil {
+		return nil, err
+	}
+	income, expenses, err := s.repository.Totals(filter)
+	if err != nil {
+		return nil, err
+	}
+	var opening int64
+	if !filter.From.IsZero() {
+		before := repository.ReportFilter{PlanID: filter.PlanID, UserID: filter.UserID, To: filter.From}
+		in, out, err := s.repository.Totals(before)
+		if err != nil {
+			return nil, err
+		}
+		opening = in - out
+	}
+
+	currency := plan.BaseCurrency()
+	net := income - expenses
+	return &model.Balance{
+		PlanID:   plan.ID,
+		PeriodID: q.PeriodID,
+		Currency: currency,
+		From:     q.From,
+		To:       q.To,
+		Opening:  model.NewMoney(opening, currency),
+		Income:   model.NewMoney(income, currency),
+		Expenses: model.NewMoney(expenses, currency),
+		Net:      model.NewMoney(net, currency),
+		Closing:  model.NewMoney(opening+net, currency),
+	}, nil
+}
+
+// filter checks that the caller owns the plan of q and turns q into a
+// repository filter with an exclusive upper bound.
+func (s *incomeService) filter(q *ReportQuery, email string) (*model.BudgetPlan, repository.ReportFilter, error) {
+	var filter repository.ReportFilter
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return nil, filter, err
+	}
+	plan, err := s.budget.GetAccessible(q.PlanID, user.ID)
+	if err != nil {
+		return nil, filter, notFound(err)
+	}
+	if err := applyPeriod(s.periods, q, plan.ID); err != nil {
+		return nil, filter, err
+	}
+	if q.From != nil && q.To != nil && q.From.After(*q.To) {
+		return nil, filter, fmt.Errorf("%w: from must not be after to", ErrInvalid)
+	}
+	filter = repository.ReportFilter{PlanID: plan.ID, UserID: user.ID}
+	if q.From != nil {
+		filter.From = *q.From
+	}
+	if q.To != nil {
+		filter.To = q.To.AddDate(0, 0, 1)
+	}
+	return plan, filter, nil
+}
+
+// toBaseCurrency validates the income and fills BaseAmount and ExchangeRate
+// using the rate on its date, which defaults to now.
+func (s *incomeService) toBaseCurrency(income *model.Income, plan *model.BudgetPlan) error {
+	if income.Amount.Minor <= 0 {
+		return fmt.Errorf("%w: amount must be positive", ErrInvalid)
+	}
+	if income.Date.IsZero() {
+		income.Date = time.Now()
+	}
+	income.Amount = income.Amount.In(plan.BaseCurrency())
+	base, rate, err := convert(s.rates, income.Amount, plan.BaseCurrency(), income.Date)
+	if err != nil {
+		return err
+	}
+	income.BaseAmount = base
+	income.ExchangeRate = rate
+	return nil
+}
@@ -0,0 +1,26 @@
+package service
+
+import (
+	"backend/model"
+)
+
+// allPages collects every item of a list by fetching it a page of
+// MaxPageLimit items at a time, sorted by sort. It serves the callers inside
+// the service layer that need a whole list rather than a page of it.
+func allPages[T any](sort string, fetch func(list model.ListQuery) (*model.Page[T], error)) ([]T, error) {
+	list := model.ListQuery{Limit: model.MaxPageLimit, Sort: sort}
+	var items []T
+	for {
+		page, err := fetch(list)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, page.Items...)
+		if page.NextCursor == "" {
+			return items, nil
+		}
+		if list.Cursor, err = model.ParseCursor(page.NextCursor); err != nil {
+			return nil, err
+		}
+	}
+}
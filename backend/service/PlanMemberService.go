@@ -0,0 +1,205 @@
+package service
+
+import (
+	"backend/model"
+	"backend/model/request"
+	"backend/repository"
+	"fmt"
+	"strings"
+)
+
+type PlanMemberService interface {
+	GetByPlan(planID int, email string) ([]model.PlanMember, error)
+	UpdateRole(req *request.MemberRoleRequest, email string) error
+	Remove(planID int, userID int, email string) error
+	Invite(req *request.InvitationRequest, email string) (*model.PlanInvitation, error)
+	GetInvitations(planID int, email string) ([]model.PlanInvitation, error)
+	Revoke(id int, email string) error
+	Pending(email string) ([]model.PlanInvitation, error)
+	Respond(id int, accept bool, email string) (*model.PlanInvitation, error)
+}
+
+type planMemberService struct {
+	repository repository.PlanMemberRepository
+	budget     repository.BudgetPlanRepository
+	user       repository.UserRepository
+	users      UserService
+}
+
+func NewPlanMemberService(factory *repository.RepositoryBase) PlanMemberService {
+	return &planMemberService{
+		repository: repository.GetByType[repository.PlanMemberRepository](factory),
+		budget:     repository.GetByType[repository.BudgetPlanRepository](factory),
+		user:       repository.GetByType[repository.UserRepository](factory),
+		users:      NewUserService(factory),
+	}
+}
+
+// GetByPlan lists the members of a plan. Any member may see them.
+func (s *planMemberService) GetByPlan(planID int, email string) ([]model.PlanMember, error) {
+	if _, err := s.plan(planID, email, model.RoleViewer); err != nil {
+		return nil, err
+	}
+	return s.repository.GetByPlan(planID)
+}
+
+// UpdateRole makes a member an editor or a viewer. Only the owner may change
+// roles and the owner's own role cannot change.
+func (s *planMemberService) UpdateRole(req *request.MemberRoleRequest, email string) error {
+	plan, err := s.plan(req.PlanID, email, model.RoleOwner)
+	if err != nil {
+		return err
+	}
+	role, err := invitableRole(req.Role)
+	if err != nil {
+		return err
+	}
+	if req.UserID == plan.UserID {
+		return fmt.Errorf("%w: the owner's role cannot change", ErrInvalid)
+	}
+	return notFound(s.repository.UpdateRole(plan.ID, req.UserID, role))
+}
+
+// Remove takes a member out of a plan. The owner may remove anyone else and
+// any other member may leave; the owner cannot leave their own plan.
+func (s *planMemberService) Remove(planID int, userID int, email string) error {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return err
+	}
+	plan, err := s.budget.GetAccessible(planID, user.ID)
+	if err != nil {
+		return notFound(err)
+	}
+	if userID == plan.UserID {
+		return fmt.Errorf("%w: the owner cannot leave the plan", ErrInvalid)
+	}
+	if userID != user.ID {
+		if err := requireRole(plan, model.RoleOwner); err != nil {
+			return err
+		}
+	}
+	return notFound(s.repository.Remove(plan.ID, userID))
+}
+
+// Invite offers a registered user a role in a plan. Only the owner may invite.
+func (s *planMemberService) Invite(req *request.InvitationRequest, email string) (*model.PlanInvitation, error) {
+	plan, err := s.plan(req.PlanID, email, model.RoleOwner)
+	if err != nil {
+		return nil, err
+	}
+	role, err := invitableRole(req.Role)
+	if err != nil {
+		return nil, err
+	}
+	invitee, err := s.users.FindByEmail(strings.TrimSpace(req.Email))
+	if err != nil {
+		return nil, notFoundAs(err, fmt.Errorf("%w: no user with email %s", ErrNotFound, req.Email))
+	}
+	if _, err := s.repository.Get(plan.ID, invitee.ID); err == nil {
+		return nil, Conflict("already_member", fmt.Sprintf("%s is already a member of the plan", invitee.Email),
+			map[string]interface{}{"email": invitee.Email})
+	}
+	pending, err := s.repository.GetInvitationsByPlan(plan.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pending {
+		if strings.EqualFold(p.Email, invitee.Email) {
+			return nil, Conflict("already_invited", fmt.Sprintf("%s was already invited", invitee.Email),
+				map[string]interface{}{"email": invitee.Email})
+		}
+	}
+
+	invitation := &model.PlanInvitation{
+		PlanID:    plan.ID,
+		Email:     invitee.Email,
+		Role:      role,
+		Status:    model.InvitationPending,
+		InvitedBy: &plan.UserID,
+	}
+	if err := s.repository.CreateInvitation(invitation); err != nil {
+		return nil, err
+	}
+	invitation.PlanName = plan.Name
+	return invitation, nil
+}
+
+// GetInvitations lists the pending invitations of a plan to its owner.
+func (s *planMemberService) GetInvitations(planID int, email string) ([]model.PlanInvitation, error) {
+	if _, err := s.plan(planID, email, model.RoleOwner); err != nil {
+		return nil, err
+	}
+	return s.repository.GetInvitationsByPlan(planID)
+}
+
+// Revoke withdraws a pending invitation. Only the owner of its plan may revoke it.
+func (s *planMemberService) Revoke(id int, email string) error {
+	invitation, err := s.repository.GetInvitation(id)
+	if err != nil {
+		return notFound(err)
+	}
+	plan, err := s.plan(invitation.PlanID, email, model.RoleOwner)
+	if err != nil {
+		return err
+	}
+	return notFoundAs(s.repository.Respond(invitation, model.InvitationRevoked, plan.UserID),
+		fmt.Errorf("%w: invitation is no longer pending", ErrInvalid))
+}
+
+// Pending lists the invitations waiting for an answer from the caller.
+func (s *planMemberService) Pending(email string) ([]model.PlanInvitation, error) {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return nil, err
+	}
+	return s.repository.PendingInvitations(user.Email)
+}
+
+// Respond accepts or declines an invitation addressed to the caller.
+func (s *planMemberService) Respond(id int, accept bool, email string) (*model.PlanInvitation, error) {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return nil, err
+	}
+	invitation, err := s.repository.GetInvitation(id)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if !strings.EqualFold(invitation.Email, user.Email) {
+		return nil, ErrNotFound
+	}
+	status := model.InvitationDeclined
+	if accept {
+		status = model.InvitationAccepted
+	}
+	if err := s.repository.Respond(invitation, status, user.ID); err != nil {
+		return nil, notFoundAs(err, fmt.Errorf("%w: invitation is no longer pending", ErrInvalid))
+	}
+	return invitation, nil
+}
+
+// plan loads a plan the caller is a member of and checks their role.
+func (s *planMemberService) plan(planID int, email string, role model.PlanRole) (*model.BudgetPlan, error) {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := s.budget.GetAccessible(planID, user.ID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if err := requireRole(plan, role); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// invitableRole parses a role that can be given to a member other than the owner.
+func invitableRole(role string) (model.PlanRole, error) {
+	r := model.PlanRole(strings.ToLower(role))
+	if r != model.RoleEditor && r != model.RoleViewer {
+		return "", fmt.Errorf("%w: role must be editor or viewer", ErrInvalid)
+	}
+	return r, nil
+}
@@ -0,0 +1,292 @@
+package service
+
+import (
+	"backend/model"
+	"backend/repository"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+type RecurringExpenseService interface {
+	Create(recurring *model.RecurringExpense, email string) error
+	Update(recurring *model.RecurringExpense, email string) error
+	Delete(id int, email string) error
+	GetByPlan(planID int, email string) ([]model.RecurringExpense, error)
+	SetPaused(id int, paused bool, email string) error
+	Skip(id int, day time.Time, email string) error
+	MaterializeDue(now time.Time) (int, error)
+}
+
+type recurringExpenseService struct {
+	repository repository.RecurringExpenseRepository
+	expenses   repository.ExpensesRepository
+	budget     repository.BudgetPlanRepository
+	category   repository.CategoryRepository
+	user       repository.UserRepository
+	rates      ExchangeRateProvider
+}
+
+func NewRecurringExpenseService(factory *repository.RepositoryBase) RecurringExpenseService {
+	return &recurringExpenseService{
+		repository: repository.GetByType[repository.RecurringExpenseRepository](factory),
+		expenses:   repository.GetByType[repository.ExpensesRepository](factory),
+		budget:     repository.GetByType[repository.BudgetPlanRepository](factory),
+		category:   repository.GetByType[repository.CategoryRepository](factory),
+		user:       repository.GetByType[repository.UserRepository](factory),
+		rates:      NewDBExchangeRateProvider(repository.GetByType[repository.ExchangeRateRepository](factory)),
+	}
+}
+
+func (s *recurringExpenseService) Create(recurring *model.RecurringExpense, email string) error {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return err
+	}
+	plan, err := s.budget.GetAccessible(recurring.BudgetID, user.ID)
+	if err != nil {
+		return notFound(err)
+	}
+	if err := requireRole(plan, model.RoleEditor); err != nil {
+		return err
+	}
+	category, err := s.category.FindById(recurring.CategoryID, user.ID)
+	if err != nil {
+		return notFound(err)
+	}
+	recurring.CategoryName = category.Name
+	if err := recurring.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	recurring.UserID = user.ID
+	recurring.Amount = recurring.Amount.In(plan.BaseCurrency())
+	recurring.Paused = false
+	recurring.SkippedDates = []time.Time{}
+	recurring.NextOccurrence = nextOccurrence(recurring, time.Time{})
+	if err := s.repository.Create(recurring); err != nil {
+		return err
+	}
+	_, err = s.materialize(recurring, time.Now())
+	return err
+}
+
+// Update edits the template and rule of a recurring expense. Occurrences that
+// were already materialized are left untouched; only future ones change.
+func (s *recurringExpenseService) Update(recurring *model.RecurringExpense, email string) error {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return err
+	}
+	existing, err := s.repository.GetByID(recurring.ID, user.ID)
+	if err != nil {
+		return notFound(err)
+	}
+	plan, err := s.budget.GetAccessible(existing.BudgetID, user.ID)
+	if err != nil {
+		return notFound(err)
+	}
+	if err := requireRole(plan, model.RoleEditor); err != nil {
+		return err
+	}
+	category, err := s.category.FindById(recurring.CategoryID, user.ID)
+	if err != nil {
+		return notFound(err)
+	}
+	recurring.CategoryName = category.Name
+	if err := recurring.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	recurring.UserID = user.ID
+	recurring.BudgetID = existing.BudgetID
+	recurring.Amount = recurring.Amount.In(existing.Amount.CurrencyCode())
+
+	// resume from the first occurrence that has not been materialized yet
+	from := time.Now()
+	if existing.NextOccurrence != nil {
+		from = *existing.NextOccurrence
+	}
+	recurring.NextOccurrence = nextOccurrence(recurring, from.AddDate(0, 0, -1))
+	return notFound(s.repository.Update(recurring))
+}
+
+func (s *recurringExpenseService) Delete(id int, email string) error {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return err
+	}
+	if _, err := s.editable(id, user.ID); err != nil {
+		return err
+	}
+	return notFound(s.repository.Delete(id, user.ID))
+}
+
+// editable loads a recurring expense of userID, who must still be an editor
+// of its plan to change it.
+func (s *recurringExpenseService) editable(id int, userID int) (*model.RecurringExpense, error) {
+	recurring, err := s.repository.GetByID(id, userID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	plan, err := s.budget.GetAccessible(recurring.BudgetID, userID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if err := requireRole(plan, model.RoleEditor); err != nil {
+		return nil, err
+	}
+	return recurring, nil
+}
+
+func (s *recurringExpenseService) GetByPlan(planID int, email string) ([]model.RecurringExpense, error) {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.budget.GetAccessible(planID, user.ID); err != nil {
+		return nil, notFound(err)
+	}
+	return s.repository.GetByPlan(planID, user.ID)
+}
+
+// SetPaused pauses or resumes a recurring expense. Occurrences that fell due
+// while it was paused are not back-filled: resuming moves its next occurrence
+// past now.
+func (s *recurringExpenseService) SetPaused(id int, paused bool, email string) error {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return err
+	}
+	recurring, err := s.editable(id, user.ID)
+	if err != nil {
+		return err
+	}
+	next := recurring.NextOccurrence
+	if now := time.Now(); recurring.Paused && !paused && next != nil && !next.After(now) {
+		next = nextOccurrence(recurring, now)
+	}
+	return notFound(s.repository.SetPaused(id, user.ID, paused, next))
+}
+
+// Skip prevents a single occurrence from being materialized. A zero day skips the next one.
+func (s *recurringExpenseService) Skip(id int, day time.Time, email string) error {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return err
+	}
+	recurring, err := s.editable(id, user.ID)
+	if err != nil {
+		return err
+	}
+	if day.IsZero() {
+		if recurring.NextOccurrence == nil {
+			return fmt.Errorf("%w: recurring expense has no future occurrence", ErrInvalid)
+		}
+		day = *recurring.NextOccurrence
+	}
+	return s.repository.AddSkippedDate(id, user.ID, day)
+}
+
+// MaterializeDue creates the expenses of every active recurring expense up to now
+// and returns how many were created. Failures are logged and retried on the next run.
+func (s *recurringExpenseService) MaterializeDue(now time.Time) (int, error) {
+	due, err := s.repository.FindDue(now)
+	if err != nil {
+		return 0, err
+	}
+	created := 0
+	for i := range due {
+		n, err := s.materialize(&due[i], now)
+		created += n
+		if err != nil {
+			log.Error().Err(err).Int("recurring_expense_id", due[i].ID).Msg("Failed to materialize recurring expense")
+		}
+	}
+	return created, nil
+}
+
+// materialize inserts every occurrence of recurring due on or before now and
+// advances its NextOccurrence. Occurrences that already exist are not duplicated.
+func (s *recurringExpenseService) materialize(recurring *model.RecurringExpense, now time.Time) (int, error) {
+	if recurring.Paused || recurring.NextOccurrence == nil || recurring.NextOccurrence.After(now) {
+		return 0, nil
+	}
+	plan, err := s.budget.GetAccessible(recurring.BudgetID, recurring.UserID)
+	if err != nil {
+		return 0, err
+	}
+	// the author may have left the plan or lost the right to edit it
+	if err := requireRole(plan, model.RoleEditor); err != nil {
+		return 0, err
+	}
+
+	created := 0
+	next := recurring.NextOccurrence
+	for next != nil && !next.After(now) {
+		day := *next
+		if !recurring.IsSkipped(day) {
+			expense := &model.Expense{
+				Amount:             recurring.Amount,
+				Description:        recurring.Description,
+				CategoryID:         recurring.CategoryID,
+				CategoryName:       recurring.CategoryName,
+				Date:               day,
+				IsRecurring:        true,
+				BudgetID:           recurring.BudgetID,
+				PaidBy:             &recurring.UserID,
+				RecurringExpenseID: &recurring.ID,
+				OccurrenceDate:     &day,
+			}
+			base, rate, err := convert(s.rates, expense.Amount, plan.BaseCurrency(), day)
+			if err != nil {
+				return created, err
+			}
+			expense.BaseAmount = base
+			expense.ExchangeRate = rate
+			ok, err := s.expenses.CreateOccurrence(expense)
+			if err != nil {
+				return created, err
+			}
+			if ok {
+				created++
+			}
+		}
+		next = nextOccurrence(recurring, day)
+		if err := s.repository.SetNextOccurrence(recurring.ID, next); err != nil {
+			return created, err
+		}
+		recurring.NextOccurrence = next
+	}
+	return created, nil
+}
+
+func nextOccurrence(recurring *model.RecurringExpense, after time.Time) *time.Time {
+	next, ok := recurring.NextAfter(after)
+	if !ok {
+		return nil
+	}
+	return &next
+}
+
+// RunRecurringScheduler materializes due recurring expenses immediately and then
+// every interval until ctx is cancelled.
+func RunRecurringScheduler(ctx context.Context, svc RecurringExpenseService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		created, err := svc.MaterializeDue(time.Now())
+		if err != nil {
+			log.Error().Err(err).Msg("Recurring expense scheduler run failed")
+		} else if created > 0 {
+			log.Info().Int("created", created).Msg("Recurring expenses materialized")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
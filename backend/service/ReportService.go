@@ -0,0 +1,136 @@
+package service
+
+import (
+	"backend/model"
+	"backend/repository"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ReportQuery selects the expenses of a plan to aggregate. To is inclusive.
+// A PeriodID selects the dates of that period of the plan instead of From and To.
+type ReportQuery struct {
+	PlanID    int
+	PeriodID  int
+	From      *time.Time
+	To        *time.Time
+	Recurring *bool
+}
+
+type ReportService interface {
+	ByCategory(q ReportQuery, email string) (*model.Report, error)
+	ByPeriod(q ReportQuery, period string, email string) (*model.Report, error)
+	ByRecurrence(q ReportQuery, email string) (*model.Report, error)
+	ByTag(q ReportQuery, email string) (*model.Report, error)
+}
+
+type reportService struct {
+	repository repository.ReportRepository
+	budget     repository.BudgetPlanRepository
+	periods    repository.BudgetPeriodRepository
+	user       repository.UserRepository
+}
+
+func NewReportService(factory *repository.RepositoryBase) ReportService {
+	return &reportService{
+		repository: repository.GetByType[repository.ReportRepository](factory),
+		budget:     repository.GetByType[repository.BudgetPlanRepository](factory),
+		periods:    repository.GetByType[repository.BudgetPeriodRepository](factory),
+		user:       repository.GetByType[repository.UserRepository](factory),
+	}
+}
+
+func (s *reportService) ByCategory(q ReportQuery, email string) (*model.Report, error) {
+	return s.build(q, "category", email, s.repository.ByCategory)
+}
+
+func (s *reportService) ByPeriod(q ReportQuery, period string, email string) (*model.Report, error) {
+	switch period {
+	case "day", "week", "month":
+	default:
+		return nil, fmt.Errorf("%w: period must be day, week or month", ErrInvalid)
+	}
+	return s.build(q, period, email, func(f repository.ReportFilter) ([]repository.ReportGroup, error) {
+		return s.repository.ByPeriod(f, period)
+	})
+}
+
+func (s *reportService) ByRecurrence(q ReportQuery, email string) (*model.Report, error) {
+	return s.build(q, "recurrence", email, s.repository.ByRecurrence)
+}
+
+// ByTag groups expenses by tag. An expense with several tags counts towards
+// each of their rows, and towards Total once per tag.
+func (s *reportService) ByTag(q ReportQuery, email string) (*model.Report, error) {
+	return s.build(q, "tag", email, s.repository.ByTag)
+}
+
+// build checks that the caller owns the plan, runs the aggregation and
+// expresses every group in the plan's base currency.
+func (s *reportService) build(q ReportQuery, groupBy string, email string, aggregate func(repository.ReportFilter) ([]repository.ReportGroup, error)) (*model.Report, error) {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := s.budget.GetAccessible(q.PlanID, user.ID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if err := applyPeriod(s.periods, &q, plan.ID); err != nil {
+		return nil, err
+	}
+	if q.From != nil && q.To != nil && q.From.After(*q.To) {
+		return nil, fmt.Errorf("%w: from must not be after to", ErrInvalid)
+	}
+
+	filter := repository.ReportFilter{PlanID: plan.ID, UserID: user.ID, Recurring: q.Recurring}
+	if q.From != nil {
+		filter.From = *q.From
+	}
+	if q.To != nil {
+		filter.To = q.To.AddDate(0, 0, 1)
+	}
+	groups, err := aggregate(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	currency := plan.BaseCurrency()
+	report := &model.Report{
+		PlanID:   plan.ID,
+		GroupBy:  groupBy,
+		Currency: currency,
+		From:     q.From,
+		To:       q.To,
+		Total:    model.NewMoney(0, currency),
+		Rows:     make([]model.ReportRow, 0, len(groups)),
+	}
+	index := make(map[string]int, len(groups))
+	for _, g := range groups {
+		i, ok := index[g.Key]
+		if !ok {
+			i = len(report.Rows)
+			index[g.Key] = i
+			report.Rows = append(report.Rows, model.ReportRow{Name: g.Key, Value: model.NewMoney(0, currency)})
+		}
+		row := &report.Rows[i]
+		row.Value.Minor += g.Total
+		row.Count += g.Count
+		if g.Sub != "" {
+			row.Children = append(row.Children, model.ReportRow{
+				Name:  g.Sub,
+				Value: model.NewMoney(g.Total, currency),
+				Count: g.Count,
+			})
+		}
+		report.Total.Minor += g.Total
+	}
+	if groupBy == "category" {
+		// rolled up parents may now be out of order
+		sort.SliceStable(report.Rows, func(i, j int) bool {
+			return report.Rows[i].Value.Minor > report.Rows[j].Value.Minor
+		})
+	}
+	return report, nil
+}
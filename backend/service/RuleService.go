@@ -0,0 +1,216 @@
+package service
+
+import (
+	"backend/model"
+	"backend/repository"
+	"fmt"
+	"strings"
+)
+
+type RuleService interface {
+	Create(rule *model.ExpenseRule, email string) error
+	Update(rule *model.ExpenseRule, email string) error
+	Delete(id int, email string) error
+	GetByUser(email string) ([]model.ExpenseRule, error)
+	Run(planID int, dryRun bool, email string) (*model.RuleRun, error)
+}
+
+type ruleService struct {
+	repository repository.RuleRepository
+	expenses   repository.ExpensesRepository
+	tags       repository.TagRepository
+	budget     repository.BudgetPlanRepository
+	category   repository.CategoryRepository
+	user       repository.UserRepository
+}
+
+func NewRuleService(factory *repository.RepositoryBase) RuleService {
+	return &ruleService{
+		repository: repository.GetByType[repository.RuleRepository](factory),
+		expenses:   repository.GetByType[repository.ExpensesRepository](factory),
+		tags:       repository.GetByType[repository.TagRepository](factory),
+		budget:     repository.GetByType[repository.BudgetPlanRepository](factory),
+		category:   repository.GetByType[repository.CategoryRepository](factory),
+		user:       repository.GetByType[repository.UserRepository](factory),
+	}
+}
+
+func (s *ruleService) Create(rule *model.ExpenseRule, email string) error {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return err
+	}
+	rule.UserID = user.ID
+	if err := s.validate(rule); err != nil {
+		return err
+	}
+	return s.repository.Create(rule)
+}
+
+func (s *ruleService) Update(rule *model.ExpenseRule, email string) error {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return err
+	}
+	if _, err := s.repository.GetByID(rule.ID, user.ID); err != nil {
+		return notFound(err)
+	}
+	rule.UserID = user.ID
+	if err := s.validate(rule); err != nil {
+		return err
+	}
+	return notFound(s.repository.Update(rule))
+}
+
+func (s *ruleService) Delete(id int, email string) error {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return err
+	}
+	return notFound(s.repository.Delete(id, user.ID))
+}
+
+// GetByUser lists the rules of the caller in the order they run.
+func (s *ruleService) GetByUser(email string) ([]model.ExpenseRule, error) {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return nil, err
+	}
+	return s.repository.GetByUser(user.ID)
+}
+
+// Run applies the rules of the caller to the existing expenses of a plan,
+// overwriting their categories. A dry run only reports what would change.
+func (s *ruleService) Run(planID int, dryRun bool, email string) (*model.RuleRun, error) {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := s.budget.GetAccessible(planID, user.ID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if !dryRun {
+		if err := requireRole(plan, model.RoleEditor); err != nil {
+			return nil, err
+		}
+	}
+
+	existing, err := allPages("id", func(list model.ListQuery) (*model.Page[model.Expense], error) {
+		return s.expenses.GetByPlan(plan.ID, user.ID, repository.ExpenseFilter{}, list)
+	})
+	if err != nil {
+		return nil, err
+	}
+	expenses := make([]*model.Expense, len(existing))
+	for i := range existing {
+		expenses[i] = &existing[i]
+	}
+	changes, err := applyRules(s.repository, s.category, user.ID, plan.ID, expenses, true)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &model.RuleRun{PlanID: plan.ID, DryRun: dryRun, Scanned: len(expenses), Changed: len(changes), Changes: changes}
+	if dryRun {
+		return run, nil
+	}
+	byID := make(map[int]*model.Expense, len(expenses))
+	for _, expense := range expenses {
+		byID[expense.ID] = expense
+	}
+	for _, change := range changes {
+		expense := byID[change.ExpenseID]
+		if change.ToCategory != "" {
+			if err := s.expenses.SetCategory(expense.ID, expense.CategoryID, expense.CategoryName); err != nil {
+				return nil, notFound(err)
+			}
+		}
+		if len(change.AddedTags) > 0 {
+			if err := s.tags.SetExpenseTags(expense.ID, plan.ID, expense.Tags); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return run, nil
+}
+
+// validate checks the conditions of a rule and that its plan and category
+// are visible to its owner.
+func (s *ruleService) validate(rule *model.ExpenseRule) error {
+	if err := rule.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if rule.PlanID != nil {
+		if _, err := s.budget.GetAccessible(*rule.PlanID, rule.UserID); err != nil {
+			return notFound(err)
+		}
+	}
+	if rule.CategoryID != nil {
+		category, err := s.category.FindById(*rule.CategoryID, rule.UserID)
+		if err != nil {
+			return notFound(err)
+		}
+		rule.CategoryName = category.Name
+	}
+	return nil
+}
+
+// applyRules runs the rules userID set up for a plan over expenses, changing
+// them in place, and returns what changed. The tags of the matching rules
+// are added; categories are only filled in where missing unless overwrite is
+// set.
+func applyRules(rules repository.RuleRepository, categories repository.CategoryRepository, userID int, planID int, expenses []*model.Expense, overwrite bool) ([]model.RuleChange, error) {
+	found, err := rules.ForPlan(userID, planID)
+	if err != nil {
+		return nil, err
+	}
+	changes := []model.RuleChange{}
+	if len(found) == 0 {
+		return changes, nil
+	}
+	set, err := model.NewRuleSet(found)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[int]string)
+	for _, expense := range expenses {
+		outcome := set.Evaluate(expense)
+		if len(outcome.RuleIDs) == 0 {
+			continue
+		}
+		change := model.RuleChange{ExpenseID: expense.ID, Description: expense.Description, RuleIDs: outcome.RuleIDs}
+
+		if id := outcome.CategoryID; id != nil && *id != expense.CategoryID && (overwrite || expense.CategoryID == 0) {
+			name, ok := names[*id]
+			if !ok {
+				// the category may have been deleted since the rule was saved
+				if category, err := categories.FindById(*id, userID); err == nil {
+					name = category.Name
+				}
+				names[*id] = name
+			}
+			if name != "" {
+				change.FromCategory, change.ToCategory = expense.CategoryName, name
+				expense.CategoryID, expense.CategoryName = *id, name
+			}
+		}
+
+		present := make(map[string]bool, len(expense.Tags))
+		for _, tag := range expense.Tags {
+			present[strings.ToLower(tag)] = true
+		}
+		for _, tag := range outcome.Tags {
+			if !present[strings.ToLower(tag)] {
+				change.AddedTags = append(change.AddedTags, tag)
+				expense.Tags = append(expense.Tags, tag)
+			}
+		}
+
+		if change.ToCategory != "" || len(change.AddedTags) > 0 {
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"backend/middleware"
+	"backend/model"
+	"backend/model/response"
+	"backend/repository"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// RefreshTokenTTL is how long a refresh token can be exchanged after it was issued.
+const RefreshTokenTTL = 30 * 24 * time.Hour
+
+type SessionService interface {
+	middleware.SessionValidator
+	Create(user *model.User, userAgent string) (*response.TokenResponse, error)
+	Refresh(refreshToken string, userAgent string) (*response.TokenResponse, error)
+	Logout(sessionID int, email string) error
+	LogoutAll(email string) error
+}
+
+type sessionService struct {
+	repository repository.SessionRepository
+	user       repository.UserRepository
+}
+
+func NewSessionService(factory *repository.RepositoryBase) SessionService {
+	return &sessionService{
+		repository: repository.GetByType[repository.SessionRepository](factory),
+		user:       repository.GetByType[repository.UserRepository](factory),
+	}
+}
+
+// Create opens a session for user and returns its first token pair.
+func (s *sessionService) Create(user *model.User, userAgent string) (*response.TokenResponse, error) {
+	token, session, err := newSession(user, userAgent)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repository.Create(session); err != nil {
+		return nil, err
+	}
+	return tokenPair(user, session, token)
+}
+
+// Refresh exchanges a refresh token for a new pair. Presenting a token that was
+// already exchanged means it leaked, so every session of the user is revoked.
+func (s *sessionService) Refresh(refreshToken string, userAgent string) (*response.TokenResponse, error) {
+	if refreshToken == "" {
+		return nil, ErrUnauthorized
+	}
+	current, err := s.repository.FindByTokenHash(hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUnauthorized
+		}
+		return nil, err
+	}
+	user, err := s.user.FindByID(current.UserID)
+	if err != nil {
+		return nil, notFoundAs(err, ErrUnauthorized)
+	}
+
+	if current.ReplacedBy != nil {
+		log.Warn().Int("session_id", current.ID).Int("user_id", user.ID).Msg("Refresh token reused, revoking all sessions")
+		if err := s.revokeAll(user); err != nil {
+			return nil, err
+		}
+		return nil, ErrUnauthorized
+	}
+	if !current.IsActive(time.Now()) || current.TokenVersion != user.TokenVersion {
+		return nil, ErrUnauthorized
+	}
+
+	token, next, err := newSession(user, userAgent)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repository.Rotate(current, next); err != nil {
+		return nil, notFoundAs(err, ErrUnauthorized)
+	}
+	return tokenPair(user, next, token)
+}
+
+// Logout revokes the session the caller's access token belongs to.
+func (s *sessionService) Logout(sessionID int, email string) error {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return err
+	}
+	return notFound(s.repository.Revoke(sessionID, user.ID))
+}
+
+// LogoutAll revokes every session of the caller and invalidates their access tokens.
+func (s *sessionService) LogoutAll(email string) error {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return err
+	}
+	return s.revokeAll(user)
+}
+
+func (s *sessionService) IsActive(sessionID int, email string, version int) (bool, error) {
+	return s.repository.IsActive(sessionID, email, version)
+}
+
+func (s *sessionService) revokeAll(user *model.User) error {
+	if _, err := s.user.IncrementTokenVersion(user.ID); err != nil {
+		return err
+	}
+	return s.repository.RevokeAll(user.ID)
+}
+
+// newSession generates a refresh token and the Session storing its hash.
+func newSession(user *model.User, userAgent string) (string, *model.Session, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	return token, &model.Session{
+		UserID:       user.ID,
+		TokenHash:    hashToken(token),
+		TokenVersion: user.TokenVersion,
+		UserAgent:    userAgent,
+		CreatedAt:    time.Now(),
+		ExpiresAt:    time.Now().Add(RefreshTokenTTL),
+	}, nil
+}
+
+func tokenPair(user *model.User, session *model.Session, refreshToken string) (*response.TokenResponse, error) {
+	access, err := middleware.GenerateJWT(user.Email, session.ID, session.TokenVersion)
+	if err != nil {
+		return nil, errors.New("error generating token")
+	}
+	return &response.TokenResponse{
+		Token:        access,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(middleware.AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,264 @@
+package service
+
+import (
+	"backend/model"
+	"backend/model/request"
+	"backend/repository"
+	"fmt"
+	"sort"
+	"time"
+)
+
+type SplitService interface {
+	Split(req *request.SplitRequest, email string) ([]model.ExpenseSplit, error)
+	Unsplit(expenseID int, email string) error
+	GetByExpense(expenseID int, email string) ([]model.ExpenseSplit, error)
+	Settlement(planID int, email string) (*model.Settlement, error)
+	RecordPayment(req *request.SettlementPaymentRequest, email string) (*model.SettlementPayment, error)
+	GetPayments(planID int, email string) ([]model.SettlementPayment, error)
+	DeletePayment(id int, email string) error
+}
+
+type splitService struct {
+	repository repository.SplitRepository
+	expenses   repository.ExpensesRepository
+	budget     repository.BudgetPlanRepository
+	members    repository.PlanMemberRepository
+	user       repository.UserRepository
+}
+
+func NewSplitService(factory *repository.RepositoryBase) SplitService {
+	return &splitService{
+		repository: repository.GetByType[repository.SplitRepository](factory),
+		expenses:   repository.GetByType[repository.ExpensesRepository](factory),
+		budget:     repository.GetByType[repository.BudgetPlanRepository](factory),
+		members:    repository.GetByType[repository.PlanMemberRepository](factory),
+		user:       repository.GetByType[repository.UserRepository](factory),
+	}
+}
+
+// Split divides an expense between plan members, replacing any previous
+// split. Shares are computed on the amount in the plan's base currency.
+func (s *splitService) Split(req *request.SplitRequest, email string) ([]model.ExpenseSplit, error) {
+	expense, plan, err := s.expense(req.ExpenseID, email, model.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	// the shares are owed to the payer; without one they would not balance
+	if expense.PaidBy == nil {
+		return nil, Validation("payer_required", "the expense has no payer; set paid_by before splitting it",
+			map[string]interface{}{"expense_id": expense.ID})
+	}
+
+	shares := req.Shares
+	if req.Method == model.SplitEqual && len(shares) == 0 {
+		members, err := s.members.GetByPlan(plan.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range members {
+			shares = append(shares, model.SplitShare{UserID: m.UserID})
+		}
+	}
+	for _, share := range shares {
+		if _, err := s.members.Get(plan.ID, share.UserID); err != nil {
+			return nil, notFoundAs(err, fmt.Errorf("%w: user %d is not a member of plan %d", ErrInvalid, share.UserID, plan.ID))
+		}
+	}
+	splits, err := model.ComputeSplits(expense.BaseAmount, req.Method, shares)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if err := s.repository.SetSplits(expense.ID, req.Method, splits); err != nil {
+		return nil, notFound(err)
+	}
+	return s.repository.GetByExpense(expense.ID)
+}
+
+// Unsplit stops sharing an expense. It then only counts towards the plan.
+func (s *splitService) Unsplit(expenseID int, email string) error {
+	expense, _, err := s.expense(expenseID, email, model.RoleEditor)
+	if err != nil {
+		return err
+	}
+	return notFound(s.repository.ClearSplits(expense.ID))
+}
+
+func (s *splitService) GetByExpense(expenseID int, email string) ([]model.ExpenseSplit, error) {
+	expense, _, err := s.expense(expenseID, email, model.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	return s.repository.GetByExpense(expense.ID)
+}
+
+// Settlement computes what every member paid and owes for the split expenses
+// of a plan, net of settlement payments, and the transfers that settle up.
+func (s *splitService) Settlement(planID int, email string) (*model.Settlement, error) {
+	plan, err := s.plan(planID, email, model.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	currency := plan.BaseCurrency()
+
+	totals := make(map[int]*model.MemberBalance)
+	balance := func(userID int) *model.MemberBalance {
+		b, ok := totals[userID]
+		if !ok {
+			zero := model.NewMoney(0, currency)
+			b = &model.MemberBalance{UserID: userID, Paid: zero, Owed: zero, Sent: zero, Received: zero, Balance: zero}
+			totals[userID] = b
+		}
+		return b
+	}
+
+	members, err := s.members.GetByPlan(plan.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range members {
+		b := balance(m.UserID)
+		b.Name, b.Email = m.Name, m.Email
+	}
+
+	sums := []struct {
+		fetch func(int) ([]repository.UserAmount, error)
+		field func(*model.MemberBalance) *model.Money
+	}{
+		{s.repository.Paid, func(b *model.MemberBalance) *model.Money { return &b.Paid }},
+		{s.repository.Owed, func(b *model.MemberBalance) *model.Money { return &b.Owed }},
+		{s.repository.Sent, func(b *model.MemberBalance) *model.Money { return &b.Sent }},
+		{s.repository.Received, func(b *model.MemberBalance) *model.Money { return &b.Received }},
+	}
+	for _, sum := range sums {
+		amounts, err := sum.fetch(plan.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range amounts {
+			*sum.field(balance(a.UserID)) = model.NewMoney(a.Amount, currency)
+		}
+	}
+
+	settlement := &model.Settlement{PlanID: plan.ID, Currency: currency, Balances: []model.MemberBalance{}}
+	net := make(map[int]int64, len(totals))
+	for userID, b := range totals {
+		// former members who still have a balance are listed too
+		if b.Email == "" {
+			if user, err := s.user.FindByID(userID); err == nil {
+				b.Name, b.Email = user.Name, user.Email
+			}
+		}
+		b.Balance = model.NewMoney(b.Paid.Minor-b.Owed.Minor+b.Sent.Minor-b.Received.Minor, currency)
+		net[userID] = b.Balance.Minor
+		settlement.Balances = append(settlement.Balances, *b)
+	}
+	sort.Slice(settlement.Balances, func(i, j int) bool {
+		return settlement.Balances[i].UserID < settlement.Balances[j].UserID
+	})
+	settlement.Transfers = model.SettleBalances(net, currency)
+	return settlement, nil
+}
+
+// RecordPayment records that one member paid another to settle up. Amounts
+// are in the plan's base currency.
+func (s *splitService) RecordPayment(req *request.SettlementPaymentRequest, email string) (*model.SettlementPayment, error) {
+	plan, err := s.plan(req.PlanID, email, model.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	if req.FromUserID == req.ToUserID {
+		return nil, fmt.Errorf("%w: a member cannot pay themselves", ErrInvalid)
+	}
+	for _, userID := range []int{req.FromUserID, req.ToUserID} {
+		if _, err := s.members.Get(plan.ID, userID); err != nil {
+			return nil, notFoundAs(err, fmt.Errorf("%w: user %d is not a member of plan %d", ErrInvalid, userID, plan.ID))
+		}
+	}
+	currency := req.Currency
+	if currency == "" {
+		currency = plan.BaseCurrency()
+	}
+	amount := req.Amount.In(currency)
+	if amount.CurrencyCode() != plan.BaseCurrency() {
+		return nil, fmt.Errorf("%w: payments must be in the plan currency %s", ErrInvalid, plan.BaseCurrency())
+	}
+	if amount.Minor <= 0 {
+		return nil, fmt.Errorf("%w: amount must be positive", ErrInvalid)
+	}
+	date := req.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return nil, err
+	}
+	payment := &model.SettlementPayment{
+		PlanID:     plan.ID,
+		FromUserID: req.FromUserID,
+		ToUserID:   req.ToUserID,
+		Amount:     amount,
+		Date:       date,
+		Note:       req.Note,
+		CreatedBy:  &user.ID,
+	}
+	if err := s.repository.CreatePayment(payment); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+func (s *splitService) GetPayments(planID int, email string) ([]model.SettlementPayment, error) {
+	if _, err := s.plan(planID, email, model.RoleViewer); err != nil {
+		return nil, err
+	}
+	return s.repository.GetPayments(planID)
+}
+
+func (s *splitService) DeletePayment(id int, email string) error {
+	payment, err := s.repository.GetPayment(id)
+	if err != nil {
+		return notFound(err)
+	}
+	if _, err := s.plan(payment.PlanID, email, model.RoleEditor); err != nil {
+		return err
+	}
+	return notFound(s.repository.DeletePayment(id))
+}
+
+// expense loads an expense and its plan, requiring role in the plan.
+func (s *splitService) expense(id int, email string, role model.PlanRole) (*model.Expense, *model.BudgetPlan, error) {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return nil, nil, err
+	}
+	expense, err := s.expenses.GetByID(id, user.ID)
+	if err != nil {
+		return nil, nil, notFound(err)
+	}
+	plan, err := s.budget.GetAccessible(expense.BudgetID, user.ID)
+	if err != nil {
+		return nil, nil, notFound(err)
+	}
+	if err := requireRole(plan, role); err != nil {
+		return nil, nil, err
+	}
+	return expense, plan, nil
+}
+
+func (s *splitService) plan(planID int, email string, role model.PlanRole) (*model.BudgetPlan, error) {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := s.budget.GetAccessible(planID, user.ID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if err := requireRole(plan, role); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"backend/model"
+	"backend/model/request"
+	"backend/repository"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+type TagService interface {
+	GetByPlan(planID int, email string) ([]model.Tag, error)
+	Rename(req *request.TagRequest, email string) (*model.Tag, error)
+	Merge(req *request.MergeTagsRequest, email string) error
+	Delete(id int, planID int, email string) error
+}
+
+type tagService struct {
+	repository repository.TagRepository
+	budget     repository.BudgetPlanRepository
+	user       repository.UserRepository
+}
+
+func NewTagService(factory *repository.RepositoryBase) TagService {
+	return &tagService{
+		repository: repository.GetByType[repository.TagRepository](factory),
+		budget:     repository.GetByType[repository.BudgetPlanRepository](factory),
+		user:       repository.GetByType[repository.UserRepository](factory),
+	}
+}
+
+// GetByPlan lists the tags of a plan with how many expenses use each.
+func (s *tagService) GetByPlan(planID int, email string) ([]model.Tag, error) {
+	if _, err := s.plan(planID, email, model.RoleViewer); err != nil {
+		return nil, err
+	}
+	return s.repository.GetByPlan(planID)
+}
+
+// Rename changes the name of a tag on every expense carrying it. Renaming a
+// tag to the name of another tag of the plan is refused; merge them instead.
+func (s *tagService) Rename(req *request.TagRequest, email string) (*model.Tag, error) {
+	plan, err := s.plan(req.PlanID, email, model.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	names, err := model.NormalizeTags([]string{req.Name})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	tag, err := s.repository.GetByID(req.ID, plan.ID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	existing, err := s.repository.GetByName(names[0], plan.ID)
+	if err == nil && existing.ID != tag.ID {
+		return nil, Conflict("already_exists", fmt.Sprintf("tag %q already exists, merge the tags instead", existing.Name),
+			map[string]interface{}{"name": existing.Name})
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	tag.Name = names[0]
+	if err := s.repository.Rename(tag); err != nil {
+		return nil, notFound(err)
+	}
+	return tag, nil
+}
+
+// Merge folds the source tags into the target, which keeps its name.
+func (s *tagService) Merge(req *request.MergeTagsRequest, email string) error {
+	plan, err := s.plan(req.PlanID, email, model.RoleEditor)
+	if err != nil {
+		return err
+	}
+	if len(req.SourceIDs) == 0 {
+		return fmt.Errorf("%w: source_ids must not be empty", ErrInvalid)
+	}
+	if _, err := s.repository.GetByID(req.TargetID, plan.ID); err != nil {
+		return notFound(err)
+	}
+	for _, id := range req.SourceIDs {
+		if id == req.TargetID {
+			return fmt.Errorf("%w: a tag cannot be merged into itself", ErrInvalid)
+		}
+		if _, err := s.repository.GetByID(id, plan.ID); err != nil {
+			return notFound(err)
+		}
+	}
+	return notFound(s.repository.Merge(plan.ID, req.SourceIDs, req.TargetID))
+}
+
+// Delete removes a tag from the plan and from every expense carrying it.
+func (s *tagService) Delete(id int, planID int, email string) error {
+	plan, err := s.plan(planID, email, model.RoleEditor)
+	if err != nil {
+		return err
+	}
+	return notFound(s.repository.Delete(id, plan.ID))
+}
+
+func (s *tagService) plan(planID int, email string, role model.PlanRole) (*model.BudgetPlan, error) {
+	user, err := resolveUser(s.user, email)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := s.budget.GetAccessible(planID, user.ID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if err := requireRole(plan, role); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
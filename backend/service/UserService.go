@@ -1,13 +1,17 @@
 package service
 
 import (
-	"backend/middleware"
 	"backend/model"
 	"backend/model/request"
+	"backend/model/response"
 	"backend/repository"
 	"backend/util"
-	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 type UserService interface {
@@ -15,25 +19,47 @@ type UserService interface {
 	CreateUser(user *model.User) error
 	FindByEmail(email string) (*model.User, error)
 	UpdatePassword(user *model.User, password string) error
-	Update(user *model.User) error
-	Delete(id int) error
-	Login(user *request.LoginRequest) (string, error)
+	Update(user *model.User, email string) error
+	Delete(email string) error
+	Login(user *request.LoginRequest) (*response.TokenResponse, error)
 }
 
 type userService struct {
 	repository repository.UserRepository
+	category   repository.CategoryRepository
+	splits     repository.SplitRepository
+	sessions   SessionService
 }
 
 func NewUserService(factory *repository.RepositoryBase) UserService {
 	return &userService{
 		repository: repository.GetByType[repository.UserRepository](factory),
+		category:   repository.GetByType[repository.CategoryRepository](factory),
+		splits:     repository.GetByType[repository.SplitRepository](factory),
+		sessions:   NewSessionService(factory),
 	}
 }
 
+// MinPasswordLength is the length of the shortest password accepted.
+const MinPasswordLength = 8
+
+// maxPasswordLength is the longest password bcrypt can hash, in bytes.
+const maxPasswordLength = 72
+
+// CreateUser registers user, with its email trimmed and lowercased. The
+// password must pass checkPassword.
 func (s *userService) CreateUser(user *model.User) error {
+	email, err := normalizeEmail(user.Email)
+	if err != nil {
+		return err
+	}
+	user.Email = email
+	if err := checkPassword(user.Password); err != nil {
+		return err
+	}
 	_, uErr := s.repository.FindByEmail(user.Email)
 	if uErr == nil {
-		return errors.New("email already in use")
+		return errEmailInUse
 	}
 	newPassword, hashErr := util.HashPassword(user.Password)
 	if hashErr != nil {
@@ -41,8 +67,10 @@ func (s *userService) CreateUser(user *model.User) error {
 	}
 	user.Password = newPassword
 	user.CreatedDate = time.Now()
-	err := s.repository.Create(user)
-	return err
+	if err := s.repository.Create(user); err != nil {
+		return emailInUse(err)
+	}
+	return s.category.Seed(user.ID)
 }
 
 func (s *userService) GetUserByID(id int) (*model.User, error) {
@@ -54,47 +82,112 @@ func (s *userService) FindByEmail(email string) (*model.User, error) {
 }
 
 func (s *userService) UpdatePassword(user *model.User, password string) error {
+	if err := checkPassword(password); err != nil {
+		return err
+	}
 	if util.VerifyPassword(password, user.Password) {
-		return errors.New("please enter a different password")
+		return Validation("same_password", "please enter a different password", nil)
 	}
 	passwordHash, hasErr := util.HashPassword(password)
 	if hasErr != nil {
 		return hasErr
 	}
 	user.Password = passwordHash
-	err := s.repository.UpdatePassword(user)
-	return err
+	if err := s.repository.UpdatePassword(user); err != nil {
+		return err
+	}
+	// tokens issued with the old password must stop working
+	return s.sessions.LogoutAll(user.Email)
 }
 
-func (s *userService) Update(user *model.User) error {
-	err := s.repository.Update(user)
-	return err
+// Update changes the name and email of the caller, whatever ID user carries.
+func (s *userService) Update(user *model.User, callerEmail string) error {
+	caller, err := resolveUser(s.repository, callerEmail)
+	if err != nil {
+		return err
+	}
+	user.ID = caller.ID
+	email, err := normalizeEmail(user.Email)
+	if err != nil {
+		return err
+	}
+	user.Email = email
+	if other, err := s.repository.FindByEmail(email); err == nil && other.ID != user.ID {
+		return errEmailInUse
+	}
+	return emailInUse(s.repository.Update(user))
 }
-func (s *userService) Delete(id int) error {
-	u, err := s.repository.FindByID(id)
+
+// Delete deletes the account of the caller.
+func (s *userService) Delete(email string) error {
+	user, err := resolveUser(s.repository, email)
+	if err != nil {
+		return err
+	}
+	// deleting a payer or a share would leave the balances of the plan
+	// summing to something other than zero
+	involved, err := s.splits.Involves(user.ID)
 	if err != nil {
 		return err
 	}
-	if u == nil {
-		return errors.New("user not found")
+	if involved {
+		return Conflict("user_in_splits", "the user paid or shares split expenses; unsplit them or change their payer first",
+			map[string]interface{}{"user_id": user.ID})
 	}
-	err = s.repository.Delete(id)
-	return err
+	return s.repository.Delete(user.ID)
 }
 
-func (s *userService) Login(u *request.LoginRequest) (string, error) {
+func (s *userService) Login(u *request.LoginRequest) (*response.TokenResponse, error) {
 
-	user, err := s.repository.FindByEmail(u.Email)
+	invalid := Unauthorized("invalid_credentials", "invalid email or password", nil)
+	user, err := s.repository.FindByEmail(strings.TrimSpace(u.Email))
 	if err != nil {
-		return "", err
+		return nil, notFoundAs(err, invalid)
 	}
 	isValid := util.VerifyPassword(u.Password, user.Password)
 	if !isValid {
-		return "", errors.New("invalid email or password")
+		return nil, invalid
 	}
-	token, err := middleware.GenerateJWT(u.Email)
-	if err != nil {
-		return "", errors.New("error generating token")
+	return s.sessions.Create(user, u.UserAgent)
+}
+
+var errEmailInUse = Conflict("email_in_use", "email already in use", nil)
+
+// emailInUse reports a write that clashed with the unique index on the
+// lowercased email, as when two requests take the same address at once.
+func emailInUse(err error) error {
+	if repository.SQLState(err) == "23505" {
+		return errEmailInUse
+	}
+	return err
+}
+
+// normalizeEmail trims and lowercases an email address, so that an account
+// is found however its address is typed.
+func normalizeEmail(email string) (string, error) {
+	address, err := mail.ParseAddress(strings.TrimSpace(email))
+	if err != nil || address.Name != "" {
+		return "", Validation("invalid_email", "invalid email address", map[string]interface{}{"email": email})
+	}
+	return strings.ToLower(address.Address), nil
+}
+
+// checkPassword rejects passwords shorter than MinPasswordLength, longer than
+// bcrypt can hash or made only of letters or only of digits.
+func checkPassword(password string) error {
+	if len(password) > maxPasswordLength {
+		return Validation("validation_failed", fmt.Sprintf("password must be at most %d bytes long", maxPasswordLength),
+			map[string]interface{}{"max_length": maxPasswordLength})
+	}
+	var letter, digit bool
+	for _, c := range password {
+		letter = letter || unicode.IsLetter(c)
+		digit = digit || unicode.IsDigit(c)
+	}
+	if utf8.RuneCountInString(password) < MinPasswordLength || !letter || !digit {
+		return Validation("weak_password",
+			fmt.Sprintf("password must have at least %d characters, with letters and digits", MinPasswordLength),
+			map[string]interface{}{"min_length": MinPasswordLength})
 	}
-	return token, nil
+	return nil
 }
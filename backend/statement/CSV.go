@@ -0,0 +1,157 @@
+package statement
+
+import (
+	"backend/model"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSVMapping tells ParseCSV where each field lives. Columns are header names
+// or, when the file has no header, zero-based indexes written as strings.
+type CSVMapping struct {
+	Date           string `json:"date"`
+	Amount         string `json:"amount"`
+	Description    string `json:"description"`
+	Category       string `json:"category"`
+	DateFormat     string `json:"date_format"`
+	Delimiter      string `json:"delimiter"`
+	DecimalComma   bool   `json:"decimal_comma"`
+	HasHeader      bool   `json:"has_header"`
+	DebitsPositive bool   `json:"debits_positive"`
+	Currency       string `json:"currency"`
+}
+
+// DefaultCSVMapping matches a "date,amount,description,category" file with a header.
+func DefaultCSVMapping() CSVMapping {
+	return CSVMapping{
+		Date:        "date",
+		Amount:      "amount",
+		Description: "description",
+		Category:    "category",
+		DateFormat:  "2006-01-02",
+		Delimiter:   ",",
+		HasHeader:   true,
+	}
+}
+
+// ParseCSV reads the rows of a CSV statement using mapping.
+func ParseCSV(r io.Reader, mapping CSVMapping) ([]Entry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if mapping.Delimiter != "" {
+		reader.Comma = []rune(mapping.Delimiter)[0]
+	}
+	if mapping.DateFormat == "" {
+		mapping.DateFormat = "2006-01-02"
+	}
+
+	var header []string
+	var entries []Entry
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if line == 1 && mapping.HasHeader {
+			header = record
+			continue
+		}
+
+		field := func(column string) (string, error) {
+			if column == "" {
+				return "", nil
+			}
+			i, err := columnIndex(header, column)
+			if err != nil {
+				return "", err
+			}
+			if i >= len(record) {
+				return "", fmt.Errorf("line %d: missing column %q", line, column)
+			}
+			return strings.TrimSpace(record[i]), nil
+		}
+
+		rawDate, err := field(mapping.Date)
+		if err != nil {
+			return nil, err
+		}
+		date, err := time.Parse(mapping.DateFormat, rawDate)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid date %q", line, rawDate)
+		}
+
+		rawAmount, err := field(mapping.Amount)
+		if err != nil {
+			return nil, err
+		}
+		amount, err := parseAmount(rawAmount, mapping.DecimalComma, mapping.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if mapping.DebitsPositive {
+			amount = amount.Neg()
+		}
+
+		description, err := field(mapping.Description)
+		if err != nil {
+			return nil, err
+		}
+		// the category column is optional, unknown names are left for the user to pick
+		category, _ := field(mapping.Category)
+
+		entries = append(entries, Entry{
+			Line:        line,
+			Date:        date,
+			Amount:      amount,
+			Description: description,
+			Category:    category,
+		})
+	}
+	return entries, nil
+}
+
+// columnIndex resolves a column by header name, falling back to a numeric index.
+func columnIndex(header []string, column string) (int, error) {
+	for i, name := range header {
+		if strings.EqualFold(strings.TrimSpace(name), column) {
+			return i, nil
+		}
+	}
+	if i, err := strconv.Atoi(column); err == nil && i >= 0 {
+		return i, nil
+	}
+	return 0, fmt.Errorf("unknown column %q", column)
+}
+
+// parseAmount reads amounts such as "-1,234.56", "1.234,56" (decimalComma) or "R$ 10".
+func parseAmount(raw string, decimalComma bool, currency string) (model.Money, error) {
+	cleaned := strings.Map(func(r rune) rune {
+		switch {
+		case r >= '0' && r <= '9', r == '-', r == '.', r == ',':
+			return r
+		}
+		return -1
+	}, raw)
+	if decimalComma {
+		cleaned = strings.ReplaceAll(cleaned, ".", "")
+		cleaned = strings.ReplaceAll(cleaned, ",", ".")
+	} else {
+		cleaned = strings.ReplaceAll(cleaned, ",", "")
+	}
+	if strings.HasPrefix(strings.TrimSpace(raw), "(") {
+		cleaned = "-" + cleaned
+	}
+	value, err := strconv.ParseFloat(cleaned, 64)
+	if err != nil {
+		return model.Money{}, fmt.Errorf("invalid amount %q", raw)
+	}
+	return model.MoneyFromFloat(value, currency), nil
+}
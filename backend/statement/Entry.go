@@ -0,0 +1,18 @@
+// Package statement reads and writes bank statements (CSV and OFX/QFX).
+package statement
+
+import (
+	"backend/model"
+	"time"
+)
+
+// Entry is a single transaction of a bank statement. Amount is signed the
+// way the bank reports it: debits are negative.
+type Entry struct {
+	Line        int
+	Date        time.Time
+	Amount      model.Money
+	Description string
+	Category    string
+	ExternalID  string
+}
@@ -0,0 +1,228 @@
+package statement
+
+import (
+	"backend/model"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Export formats.
+const (
+	ExportCSV  = "csv"
+	ExportJSON = "json"
+	ExportOFX  = "ofx"
+)
+
+// ExportVersion is the version of the JSON backup document.
+const ExportVersion = 1
+
+// ExportHeader is written before any expense. Plans are listed in the order
+// their expenses will follow.
+type ExportHeader struct {
+	ExportedAt time.Time
+	From       *time.Time
+	To         *time.Time
+	Categories []model.Category
+	Plans      []model.BudgetPlan
+}
+
+// Exporter writes plans and their expenses to a stream. Expenses arrive grouped
+// by plan, so formats never have to hold more than one row at a time.
+type Exporter interface {
+	Begin(header ExportHeader) error
+	Expense(plan *model.BudgetPlan, expense *model.Expense) error
+	End() error
+}
+
+// NewExporter returns the Exporter for format writing to w.
+func NewExporter(format string, w io.Writer) (Exporter, error) {
+	switch format {
+	case ExportCSV:
+		return &csvExporter{w: csv.NewWriter(w)}, nil
+	case ExportJSON:
+		return &jsonExporter{w: w}, nil
+	case ExportOFX:
+		return &ofxExporter{w: w}, nil
+	}
+	return nil, fmt.Errorf("unsupported export format %q", format)
+}
+
+// ContentType returns the MIME type of an export format.
+func ContentType(format string) string {
+	switch format {
+	case ExportCSV:
+		return "text/csv"
+	case ExportOFX:
+		return "application/x-ofx"
+	}
+	return "application/json"
+}
+
+// csvExporter writes one row per expense. The date, amount, description and
+// category columns match DefaultCSVMapping, with debits positive.
+type csvExporter struct {
+	w *csv.Writer
+}
+
+func (e *csvExporter) Begin(ExportHeader) error {
+	return e.w.Write([]string{"plan_id", "plan", "id", "date", "amount", "currency", "description", "category",
+		"base_amount", "base_currency", "exchange_rate", "is_recurring"})
+}
+
+func (e *csvExporter) Expense(plan *model.BudgetPlan, expense *model.Expense) error {
+	return e.w.Write([]string{
+		strconv.Itoa(plan.ID),
+		plan.Name,
+		strconv.Itoa(expense.ID),
+		expense.Date.Format("2006-01-02"),
+		expense.Amount.Decimal(),
+		expense.Amount.CurrencyCode(),
+		expense.Description,
+		expense.CategoryName,
+		expense.BaseAmount.Decimal(),
+		expense.BaseAmount.CurrencyCode(),
+		strconv.FormatFloat(expense.ExchangeRate, 'f', -1, 64),
+		strconv.FormatBool(expense.IsRecurring),
+	})
+}
+
+func (e *csvExporter) End() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// jsonExporter writes a model.ExportDocument field by field so that the
+// expenses array is never built in memory.
+type jsonExporter struct {
+	w     io.Writer
+	count int
+}
+
+func (e *jsonExporter) Begin(header ExportHeader) error {
+	categories, err := json.Marshal(nonNil(header.Categories))
+	if err != nil {
+		return err
+	}
+	plans, err := json.Marshal(nonNil(header.Plans))
+	if err != nil {
+		return err
+	}
+	exportedAt, _ := header.ExportedAt.MarshalJSON()
+	_, err = fmt.Fprintf(e.w, `{"version":%d,"exported_at":%s,"categories":%s,"plans":%s,"expenses":[`,
+		ExportVersion, exportedAt, categories, plans)
+	return err
+}
+
+func (e *jsonExporter) Expense(_ *model.BudgetPlan, expense *model.Expense) error {
+	data, err := json.Marshal(expense)
+	if err != nil {
+		return err
+	}
+	if e.count > 0 {
+		if _, err := io.WriteString(e.w, ","); err != nil {
+			return err
+		}
+	}
+	e.count++
+	_, err = e.w.Write(data)
+	return err
+}
+
+func (e *jsonExporter) End() error {
+	_, err := io.WriteString(e.w, "]}\n")
+	return err
+}
+
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
+
+// ofxExporter writes an OFX 2 document with one bank statement per plan,
+// expressed in the plan's base currency.
+type ofxExporter struct {
+	w      io.Writer
+	header ExportHeader
+	plan   *model.BudgetPlan
+}
+
+func (e *ofxExporter) Begin(header ExportHeader) error {
+	e.header = header
+	_, err := fmt.Fprintf(e.w, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n"+
+		"<?OFX OFXHEADER=\"200\" VERSION=\"211\" SECURITY=\"NONE\" OLDFILEUID=\"NONE\" NEWFILEUID=\"NONE\"?>\n"+
+		"<OFX>\n<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>"+
+		"<DTSERVER>%s</DTSERVER><LANGUAGE>POR</LANGUAGE></SONRS></SIGNONMSGSRSV1>\n<BANKMSGSRSV1>\n",
+		ofxDate(header.ExportedAt))
+	return err
+}
+
+func (e *ofxExporter) Expense(plan *model.BudgetPlan, expense *model.Expense) error {
+	if e.plan == nil || e.plan.ID != plan.ID {
+		if err := e.closePlan(); err != nil {
+			return err
+		}
+		if err := e.openPlan(plan); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(e.w, "<STMTTRN><TRNTYPE>DEBIT</TRNTYPE><DTPOSTED>%s</DTPOSTED><TRNAMT>%s</TRNAMT>"+
+		"<FITID>%d</FITID><NAME>%s</NAME><MEMO>%s</MEMO></STMTTRN>\n",
+		ofxDate(expense.Date), expense.BaseAmount.Neg().Decimal(), expense.ID, ofxText(expense.Description, 32), ofxText(expense.CategoryName, 255))
+	return err
+}
+
+func (e *ofxExporter) End() error {
+	if err := e.closePlan(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(e.w, "</BANKMSGSRSV1>\n</OFX>\n")
+	return err
+}
+
+func (e *ofxExporter) openPlan(plan *model.BudgetPlan) error {
+	e.plan = plan
+	start := plan.CreatedDate
+	if e.header.From != nil {
+		start = *e.header.From
+	}
+	end := e.header.ExportedAt
+	if e.header.To != nil {
+		end = *e.header.To
+	}
+	_, err := fmt.Fprintf(e.w, "<STMTTRNRS><TRNUID>%d</TRNUID><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>"+
+		"<STMTRS><CURDEF>%s</CURDEF><BANKACCTFROM><BANKID>GASTOZERO</BANKID><ACCTID>%d</ACCTID><ACCTTYPE>CHECKING</ACCTTYPE></BANKACCTFROM>\n"+
+		"<BANKTRANLIST><DTSTART>%s</DTSTART><DTEND>%s</DTEND>\n",
+		plan.ID, plan.BaseCurrency(), plan.ID, ofxDate(start), ofxDate(end))
+	return err
+}
+
+func (e *ofxExporter) closePlan() error {
+	if e.plan == nil {
+		return nil
+	}
+	e.plan = nil
+	_, err := io.WriteString(e.w, "</BANKTRANLIST></STMTRS></STMTTRNRS>\n")
+	return err
+}
+
+func ofxDate(t time.Time) string {
+	return t.UTC().Format("20060102150405")
+}
+
+// ofxText escapes s for XML and cuts it to the maximum length of the element.
+func ofxText(s string, max int) string {
+	if runes := []rune(s); len(runes) > max {
+		s = string(runes[:max])
+	}
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
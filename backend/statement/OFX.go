@@ -0,0 +1,80 @@
+package statement
+
+import (
+	"backend/model"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ofxField matches a leaf element in both OFX 1.x SGML, where closing tags
+// are optional, and OFX 2.x XML.
+var ofxField = regexp.MustCompile(`<([A-Z0-9.]+)>([^<\r\n]*)`)
+
+// ParseOFX reads the transactions of an OFX or QFX statement. Amounts are
+// expressed in the statement's CURDEF currency, or in currency when it has none.
+func ParseOFX(r io.Reader, currency string) ([]Entry, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	doc := string(content)
+	if !strings.Contains(strings.ToUpper(doc), "<OFX>") {
+		return nil, fmt.Errorf("not an OFX document")
+	}
+
+	if match := regexp.MustCompile(`(?i)<CURDEF>\s*([A-Z]{3})`).FindStringSubmatch(doc); match != nil {
+		currency = strings.ToUpper(match[1])
+	}
+
+	var entries []Entry
+	blocks := strings.Split(doc, "<STMTTRN>")
+	for i, block := range blocks[1:] {
+		if end := strings.Index(block, "</STMTTRN>"); end >= 0 {
+			block = block[:end]
+		}
+		fields := make(map[string]string)
+		for _, match := range ofxField.FindAllStringSubmatch(block, -1) {
+			fields[match[1]] = html.UnescapeString(strings.TrimSpace(match[2]))
+		}
+
+		date, err := parseOFXDate(fields["DTPOSTED"])
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d: %w", i+1, err)
+		}
+		value, err := strconv.ParseFloat(strings.ReplaceAll(fields["TRNAMT"], ",", "."), 64)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d: invalid amount %q", i+1, fields["TRNAMT"])
+		}
+
+		description := fields["NAME"]
+		if memo := fields["MEMO"]; memo != "" && memo != description {
+			description = strings.TrimSpace(description + " " + memo)
+		}
+
+		entries = append(entries, Entry{
+			Line:        i + 1,
+			Date:        date,
+			Amount:      model.MoneyFromFloat(value, currency),
+			Description: description,
+			ExternalID:  fields["FITID"],
+		})
+	}
+	return entries, nil
+}
+
+// parseOFXDate reads YYYYMMDD[HHMMSS[.XXX]][[offset:TZ]] and keeps only the day.
+func parseOFXDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	date, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	return date, nil
+}
@@ -1,9 +1,9 @@
-import api from '../../services/API.jsx';
+import { createPlan as create } from '../../services/client.js';
 
 export default async function createPlan(data) {
     const { name = '', description = '' } = data;
 
-    return await api.post('/plan', {
+    return await create({
         name,
         description,
         totalAmount: 0,
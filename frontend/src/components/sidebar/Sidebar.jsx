@@ -7,6 +7,8 @@ import { FaSpinner } from "react-icons/fa";
 import ProtectedSidebar from "./ProtectedSidebar.jsx";
 import { MdClose, MdMenu, MdLogout } from "react-icons/md";
 import { queryClient } from '@tanstack/react-query'; 
+import { clearTokens } from "../../services/API.jsx";
+import { logout } from "../../services/client.js";
 export default function Sidebar({ isOpen, setIsOpen, setPlan, selectedPlan }) {
   const { theme, toggleTheme } = useTheme();
   const navigate = useNavigate();
@@ -20,7 +22,11 @@ export default function Sidebar({ isOpen, setIsOpen, setPlan, selectedPlan }) {
   }, [toggleTheme]);
 
   const handleLogout = () => {
-    Cookies.remove("authToken");
+    // revoke the session server-side; the local tokens are dropped either way
+    logout({
+      headers: { Authorization: `Bearer ${Cookies.get("authToken")}` },
+    }).catch(() => {});
+    clearTokens();
     Cookies.remove("graphType");
     queryClient.invalidateQueries(['plans']);
     toast.info(
@@ -1,12 +1,12 @@
 import { useQuery } from '@tanstack/react-query';
-import api from "../services/API.jsx";
+import { listAll } from "../services/API.jsx";
+import { listPlans } from "../services/client.js";
 
 export function usePlans() {
     return useQuery({
         queryKey: ['plans'],
         queryFn: async () => {
-            const response = await api.get('/plan/user');
-            return response.data;
+            return await listAll(listPlans);
         },
         staleTime: 1000 * 60 * 30,
         cacheTime: 1000 * 60 * 30,
@@ -1,10 +1,8 @@
-import API from "../../services/API";
-
-const defaultPath = "http://localhost:8080/";
+import { login as openSession } from "../../services/client.js";
 
 const login = async (email, password) => {
     try {
-        const response = await API.post(defaultPath + "users/login", {
+        const response = await openSession({
             email,
             password,
         });
@@ -12,7 +10,7 @@ const login = async (email, password) => {
         const token = response.data?.token;
 
         if (token) {
-            return token;
+            return response.data;
         } else {
             throw new Error("Token not found");
         }
@@ -3,6 +3,7 @@ import { useNavigate } from "react-router-dom";
 import login from "./Actions.jsx";
 import Cookies from "js-cookie";
 import { toast } from "react-toastify";
+import { saveTokens } from "../../services/API.jsx";
 
 export default function LoginPage() {
   const [email, setEmail] = useState("");
@@ -24,8 +25,8 @@ export default function LoginPage() {
     }
     setFields(false);
     try {
-      const token = await login(email, password);
-      Cookies.set("authToken", token);
+      const tokens = await login(email, password);
+      saveTokens(tokens);
       toast.success("Logged in successfully!");
       navigate("/home");
     } catch (e) {
@@ -1,10 +1,9 @@
-import API from "../../services/API.jsx";
-
-const BASE_URL = "http://localhost:8080";
+import { listAll } from "../../services/API.jsx";
+import * as client from "../../services/client.js";
 
 export const addExpense = async (expenseData) => {
   try {
-    const response = await API.post(`${BASE_URL}/expense`, {
+    const response = await client.createExpense({
       amount: parseFloat(expenseData.amount),
       description: expenseData.description,
       category_id: parseInt(expenseData.category_id),
@@ -23,10 +22,8 @@ export const addExpense = async (expenseData) => {
 
 export const getExpensesByPlan = async (planId) => {
   try {
-    const response = await API.get(`${BASE_URL}/expense/plan`, {
-      params: { id: planId },
-    });
-    return response;
+    const data = await listAll(client.listExpensesByPlan, { id: planId });
+    return { data };
   } catch (error) {
     throw new Error(
       error.response?.data?.message || error.message || "Failed to get expenses"
@@ -36,8 +33,7 @@ export const getExpensesByPlan = async (planId) => {
 
 export const getCategories = async () => {
   try {
-    const response = await API.get(`${BASE_URL}/category`);
-    return response.data;
+    return await listAll(client.listCategories);
   } catch (error) {
     throw new Error(error.message || "Failed to get categories");
   }
@@ -45,7 +41,7 @@ export const getCategories = async () => {
 
 export const addNewCategory = async (name) => {
   try {
-    const response = await API.post(`${BASE_URL}/category`, { name });
+    const response = await client.createCategory({ name });
     return response.data;
   } catch (error) {
     throw new Error(error.message);
@@ -54,11 +50,9 @@ export const addNewCategory = async (name) => {
 
 export const deleteExpense = async (id, plan) => {
   try {
-    const response = await API.delete(`${BASE_URL}/expense`, {
-      data: {
-        id: id,
-        plan_id: plan,
-      },
+    const response = await client.deleteExpense({
+      id: id,
+      plan_id: plan,
     });
     if (response.status === 200 || response.status === 204) {
       return true;
@@ -73,7 +67,7 @@ export const deleteExpense = async (id, plan) => {
 export const updatePlanAmout = async (planID, newAmount, add) => {
   try {
 
-    const r = await API.put(`${BASE_URL}/plan/amount`, {
+    const r = await client.updatePlanAmount({
         id: planID,
         amount: newAmount,
         add: add
@@ -92,10 +86,8 @@ export const updatePlanAmout = async (planID, newAmount, add) => {
 
 export const deletePlan = async (id) => {
   try {
-    const response = await API.delete(`${BASE_URL}/plan`, {
-      params: {
-        id: id,
-      },
+    const response = await client.deletePlan({
+      id: id,
     });
     if (response.status === 200 || response.status === 204) {
       return true;
@@ -110,15 +102,10 @@ export const deletePlan = async (id) => {
 
 export const updatePlan = async (planObject) => {
   try {
-    const r = await API.put(`${BASE_URL}/plan`, {
+    const r = await client.updatePlan({
       id: planObject.id,
       name: planObject.name,
       description: planObject.description,
-      amount: planObject.amount,
-      is_recurring: planObject.is_recurring,
-      category_id: planObject.category_id,
-      category_name: planObject.category_name,
-      budget_id: planObject.budget_id,
     });
 
     if (r.status === 200) {
@@ -134,7 +121,7 @@ export const updatePlan = async (planObject) => {
 
 export const updateExpense = async (id, expenseObject) => {
   try {
-    const r = await API.put(`${BASE_URL}/expense`, {
+    const r = await client.updateExpense({
       id: id,
       amount: Number(expenseObject.amount),
       description: expenseObject.description,
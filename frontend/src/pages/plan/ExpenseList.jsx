@@ -257,7 +257,7 @@ export default function ExpenseList({
                                 <td className="p-3">
                                     {isEditingThis ? (
                                         <select
-                                            value={editForm.category_name}
+                                            value={editForm.category_id}
                                             onChange={(e) =>
                                                 setEditForm({
                                                     ...editForm,
@@ -268,7 +268,7 @@ export default function ExpenseList({
                                             className="bg-gray-700 text-white px-2 py-1 rounded w-full"
                                         >
                                             {categories.map(cat => (
-                                                <option key={cat.id} value={cat.name}>{cat.name}</option>
+                                                <option key={cat.id} value={cat.id}>{cat.path || cat.name}</option>
                                             ))}
                                         </select>
                                     ) : expense.category_name}
@@ -27,7 +27,7 @@ export default function PlanAddEntry({
 
     useEffect(() => {
         const filtered = categories.filter((cat) =>
-            cat.name.toLowerCase().includes(categoryInput.toLowerCase())
+            (cat.path || cat.name).toLowerCase().includes(categoryInput.toLowerCase())
         );
         setFilteredCategories(filtered);
     }, [categoryInput, categories]);
@@ -40,7 +40,7 @@ export default function PlanAddEntry({
     }, [showDropdown, categoryInput, filteredCategories]); // Dependências para recalcular a posição
 
     const onSubmit = async (data) => {
-        let selectedCategory = categories.find((cat) => cat.name === categoryInput);
+        let selectedCategory = categories.find((cat) => (cat.path || cat.name) === categoryInput);
         if (!selectedCategory) {
             selectedCategory = await addNewCategory(categoryInput);
             setCategories((prev) => [...prev, selectedCategory]);
@@ -125,12 +125,12 @@ export default function PlanAddEntry({
                                     key={cat.id}
                                     className="p-2 opacity-80 hover:opacity-100 cursor-pointer text-text"
                                     onMouseDown={() => {
-                                        setCategoryInput(cat.name);
-                                        setValue("category_input", cat.name);
+                                        setCategoryInput(cat.path || cat.name);
+                                        setValue("category_input", cat.path || cat.name);
                                         setShowDropdown(false);
                                     }}
                                 >
-                                    {cat.name}
+                                    {cat.path || cat.name}
                                 </li>
                             ))}
                         </ul>,
@@ -1,9 +1,7 @@
-import axios from "axios";
-
-const defaultPath = "http://localhost:8080/";
+import { createUser } from "../../services/client.js";
 
 const create = async (name, email, password) => {
-    const response = await axios.post(defaultPath + "users", {
+    const response = await createUser({
         name,
         email,
         password,
@@ -5,6 +5,7 @@ import { toast } from "react-toastify";
 import "react-toastify/dist/ReactToastify.css";
 import ReportGraph from "./ReportGraph.jsx";
 import Cookies from "js-cookie";
+import { reportByCategory } from "../../services/client.js";
 
 const graphTypes = [
     { label: "Pie", value: "pie" },
@@ -29,7 +30,7 @@ export default function Reports() {
         Cookies.set("graphType", graphType);
     }, [graphType]);
 
-    const handleGenerateReport = (e) => {
+    const handleGenerateReport = async (e) => {
         e.preventDefault();
 
         if (!selectedPlan) {
@@ -51,29 +52,18 @@ export default function Reports() {
 
         setReportName(filteredPlan.name);
 
-        const filteredExpenses = filteredPlan.expenses.filter(exp => {
-            const expenseDate = new Date(exp.date);
-            return (!fromDate || expenseDate >= new Date(fromDate)) &&
-                (!toDate || expenseDate <= new Date(toDate)) &&
-                (recurring !== null ? exp.is_recurring === recurring : true);
-        });
-
-        const categoryAggregation = filteredExpenses.reduce((acc, exp) => {
-            const category = exp.category_name;
-            if (acc[category]) {
-                acc[category] += exp.amount;
-            } else {
-                acc[category] = exp.amount;
-            }
-            return acc;
-        }, {});
-
-        const reportData = Object.entries(categoryAggregation).map(([name, value]) => ({
-            name,
-            value
-        }));
-
-        setReportData(reportData);
+        const params = { plan: filteredPlan.id };
+        if (fromDate) params.from = fromDate;
+        if (toDate) params.to = toDate;
+        if (recurring !== null) params.recurring = recurring;
+
+        try {
+            const response = await reportByCategory(params);
+            setReportData(response.data.rows.map(({ name, value }) => ({ name, value })));
+        } catch (error) {
+            console.error(error);
+            toast.error("Error generating report");
+        }
     };
 
     return (
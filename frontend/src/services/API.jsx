@@ -24,15 +24,70 @@ export const setUnauthorizedHandler = (handler) => {
     onUnauthorized = handler;
 };
 
+export const saveTokens = ({ token, refresh_token }) => {
+    Cookies.set('authToken', token);
+    if (refresh_token) Cookies.set('refreshToken', refresh_token);
+};
+
+export const clearTokens = () => {
+    Cookies.remove('authToken');
+    Cookies.remove('refreshToken');
+};
+
+// a single refresh is shared by every request that failed while it was running
+let refreshing;
+
+const refreshTokens = () => {
+    if (!refreshing) {
+        refreshing = axios
+            .post(api.defaults.baseURL + 'users/refresh', {
+                refresh_token: Cookies.get('refreshToken'),
+            })
+            .then((response) => saveTokens(response.data))
+            .finally(() => {
+                refreshing = undefined;
+            });
+    }
+    return refreshing;
+};
+
 api.interceptors.response.use(
     (response) => response,
-    (error) => {
+    async (error) => {
+        const original = error.config;
         if (error.response?.status === 401) {
-            Cookies.remove('authToken');
+            const canRefresh = Cookies.get('refreshToken') && original && !original._retried
+                && !original.url?.includes('users/login');
+            if (canRefresh) {
+                original._retried = true;
+                try {
+                    await refreshTokens();
+                    return api(original);
+                } catch {
+                    // fall through to the unauthorized handler
+                }
+            }
+            clearTokens();
             if (onUnauthorized) onUnauthorized();
         }
         return Promise.reject(error);
     }
 );
 
+// largest page the API serves, see MaxPageLimit in backend/model/List.go
+const PAGE_LIMIT = 200;
+
+// listAll fetches every item of a paginated list operation of the client,
+// a page at a time, following the next_cursor of each page.
+export const listAll = async (list, params = {}) => {
+    const items = [];
+    let cursor;
+    do {
+        const response = await list({ ...params, limit: PAGE_LIMIT, cursor });
+        items.push(...response.data.items);
+        cursor = response.data.next_cursor;
+    } while (cursor);
+    return items;
+};
+
 export default api;
@@ -0,0 +1,1891 @@
+// Code generated by backend/cmd/genclient from the OpenAPI document. DO NOT EDIT.
+// Regenerate it with npm run generate:client after changing the API.
+
+import api from './API.jsx';
+
+/**
+ * @typedef {Object} Attachment
+ * @property {string} [content_type]
+ * @property {string} [created_at]
+ * @property {number} [expense_id]
+ * @property {string} [file_name]
+ * @property {number} [id]
+ * @property {string} [sha256]
+ * @property {number} [size_bytes]
+ * @property {(number|null)} [uploaded_by]
+ */
+
+/**
+ * @typedef {Object} Balance
+ * @property {number} [closing]
+ * @property {string} [currency]
+ * @property {number} [expenses]
+ * @property {(string|null)} [from]
+ * @property {number} [income]
+ * @property {number} [net]
+ * @property {number} [opening]
+ * @property {number} [period_id]
+ * @property {number} [plan_id]
+ * @property {(string|null)} [to]
+ */
+
+/**
+ * @typedef {Object} BudgetPeriod
+ * @property {number} [amount]
+ * @property {(string|null)} [endDate]
+ * @property {number} [id]
+ * @property {number} [planID]
+ * @property {number} [remaining]
+ * @property {number} [rollover]
+ * @property {number} [spent]
+ * @property {string} [startDate]
+ */
+
+/**
+ * @typedef {Object} BudgetPlan
+ * @property {string} [baseCurrency]
+ * @property {string} [description]
+ * @property {(string|null)} [endDate]
+ * @property {Array<Expense>} [expenses]
+ * @property {number} [id]
+ * @property {string} [name]
+ * @property {string} [period]
+ * @property {number} [periodDays]
+ * @property {string} [role]
+ * @property {boolean} [rollover]
+ * @property {string} [startDate]
+ * @property {PlanSummary} [summary]
+ * @property {number} [totalAmount]
+ * @property {number} [userID]
+ */
+
+/**
+ * @typedef {Object} BudgetPlanPage
+ * @property {Array<BudgetPlan>} [items]
+ * @property {string} [next_cursor]
+ */
+
+/**
+ * @typedef {Object} BudgetPlanPatchRequest
+ * @property {(string|null)} [description]
+ * @property {(string|null)} [endDate]
+ * @property {(string|null)} [name]
+ * @property {(("one_off"|"weekly"|"monthly"|"custom")|null)} [period]
+ * @property {(number|null)} [periodDays]
+ * @property {(boolean|null)} [rollover]
+ */
+
+/**
+ * @typedef {Object} BudgetPlanRequest
+ * @property {string} [baseCurrency]
+ * @property {string} [description]
+ * @property {(string|null)} [endDate]
+ * @property {Array<number>} [expenses]
+ * @property {string} name
+ * @property {("one_off"|"weekly"|"monthly"|"custom")} [period]
+ * @property {number} [periodDays]
+ * @property {boolean} [rollover]
+ * @property {string} [startDate]
+ * @property {number} [totalAmount]
+ * @property {number} [userID]
+ */
+
+/**
+ * @typedef {Object} BudgetPlanUpdateRequest
+ * @property {string} [description]
+ * @property {(string|null)} [endDate]
+ * @property {number} id
+ * @property {string} name
+ * @property {("one_off"|"weekly"|"monthly"|"custom")} [period]
+ * @property {number} [periodDays]
+ * @property {boolean} [rollover]
+ */
+
+/**
+ * @typedef {Object} BudgetStatus
+ * @property {number} [allocated]
+ * @property {Array<CategoryBudgetStatus>} [categories]
+ * @property {string} [currency]
+ * @property {(string|null)} [from]
+ * @property {boolean} [over_budget]
+ * @property {number} [plan_id]
+ * @property {number} [spent]
+ * @property {(string|null)} [to]
+ * @property {number} [unallocated]
+ */
+
+/**
+ * @typedef {Object} Category
+ * @property {Array<Category>} [children]
+ * @property {number} [id]
+ * @property {string} [name]
+ * @property {(number|null)} [parent_id]
+ * @property {string} [path]
+ * @property {number} [user_id]
+ */
+
+/**
+ * @typedef {Object} CategoryBudget
+ * @property {number} [amount]
+ * @property {number} [category_id]
+ * @property {string} [category_name]
+ * @property {number} [id]
+ * @property {number} [plan_id]
+ */
+
+/**
+ * @typedef {Object} CategoryBudgetRequest
+ * @property {number} [amount]
+ * @property {number} category_id
+ * @property {string} [currency]
+ * @property {number} plan_id
+ */
+
+/**
+ * @typedef {Object} CategoryBudgetStatus
+ * @property {number} [allocated]
+ * @property {number} [category_id]
+ * @property {string} [category_name]
+ * @property {boolean} [over_budget]
+ * @property {number} [percent_used]
+ * @property {number} [remaining]
+ * @property {number} [spent]
+ */
+
+/**
+ * @typedef {Object} CategoryPage
+ * @property {Array<Category>} [items]
+ * @property {string} [next_cursor]
+ */
+
+/**
+ * @typedef {Object} CategoryPatchRequest
+ * @property {(string|null)} [name]
+ * @property {(number|null)} [parent_id]
+ */
+
+/**
+ * @typedef {Object} CategoryRequest
+ * @property {string} name
+ * @property {(number|null)} [parent_id]
+ */
+
+/**
+ * @typedef {Object} DeleteExpenseRequest
+ * @property {number} id
+ * @property {number} plan_id
+ */
+
+/**
+ * @typedef {Object} ErrorResponse
+ * @property {string} [code]
+ * @property {Object<string, *>} [details]
+ * @property {string} [message]
+ * @property {string} [request_id]
+ */
+
+/**
+ * @typedef {Object} ExchangeRate
+ * @property {string} [base]
+ * @property {string} [date]
+ * @property {number} [id]
+ * @property {string} [quote]
+ * @property {number} [rate]
+ * @property {string} [source]
+ */
+
+/**
+ * @typedef {Object} ExchangeRateRequest
+ * @property {string} base
+ * @property {string} [date]
+ * @property {string} quote
+ * @property {number} [rate]
+ */
+
+/**
+ * @typedef {Object} Expense
+ * @property {number} [amount]
+ * @property {number} [base_amount]
+ * @property {number} [budget_id]
+ * @property {number} [category_id]
+ * @property {string} [category_name]
+ * @property {string} [currency]
+ * @property {string} [date]
+ * @property {string} [description]
+ * @property {number} [exchange_rate]
+ * @property {number} [id]
+ * @property {boolean} [is_recurring]
+ * @property {(string|null)} [occurrence_date]
+ * @property {(number|null)} [paid_by]
+ * @property {number} [rank]
+ * @property {(number|null)} [recurring_expense_id]
+ * @property {(string|null)} [split_method]
+ * @property {Array<string>} [tags]
+ */
+
+/**
+ * @typedef {Object} ExpensePage
+ * @property {Array<Expense>} [items]
+ * @property {string} [next_cursor]
+ */
+
+/**
+ * @typedef {Object} ExpensePatchRequest
+ * @property {(number|null)} [amount]
+ * @property {(number|null)} [category_id]
+ * @property {(string|null)} [date]
+ * @property {(string|null)} [description]
+ * @property {(boolean|null)} [is_recurring]
+ * @property {(number|null)} [paid_by]
+ * @property {(Array<string>|null)} [tags]
+ */
+
+/**
+ * @typedef {Object} ExpenseRequest
+ * @property {number} [amount]
+ * @property {number} budget_id
+ * @property {number} [category_id]
+ * @property {string} [category_name]
+ * @property {string} [currency]
+ * @property {string} [date]
+ * @property {string} [description]
+ * @property {boolean} [is_recurring]
+ * @property {(number|null)} [paid_by]
+ * @property {Array<string>} [tags]
+ */
+
+/**
+ * @typedef {Object} ExpenseRule
+ * @property {(number|null)} [category_id]
+ * @property {string} [category_name]
+ * @property {string} [created_at]
+ * @property {string} [currency]
+ * @property {(string|null)} [date_from]
+ * @property {(string|null)} [date_to]
+ * @property {string} [description_pattern]
+ * @property {boolean} [enabled]
+ * @property {number} [id]
+ * @property {(number|null)} [max_amount]
+ * @property {(number|null)} [min_amount]
+ * @property {string} [name]
+ * @property {(number|null)} [plan_id]
+ * @property {number} [priority]
+ * @property {Array<string>} [tags]
+ * @property {number} [user_id]
+ */
+
+/**
+ * @typedef {Object} ExpenseSplit
+ * @property {number} [amount]
+ * @property {number} [expense_id]
+ * @property {string} [name]
+ * @property {number} [user_id]
+ */
+
+/**
+ * @typedef {Object} ExpenseUpdateRequest
+ * @property {number} [amount]
+ * @property {number} [category_id]
+ * @property {string} [category_name]
+ * @property {string} [date]
+ * @property {string} [description]
+ * @property {number} id
+ * @property {boolean} [is_recurring]
+ * @property {(number|null)} [paid_by]
+ * @property {Array<string>} [tags]
+ */
+
+/**
+ * @typedef {Object} ExportDocument
+ * @property {Array<Category>} [categories]
+ * @property {Array<Expense>} [expenses]
+ * @property {string} [exported_at]
+ * @property {Array<BudgetPlan>} [plans]
+ * @property {number} [version]
+ */
+
+/**
+ * @typedef {Object} ImportCommitRequest
+ * @property {boolean} [allow_duplicates]
+ * @property {number} [default_category_id]
+ * @property {number} plan_id
+ * @property {Array<ImportRow>} rows
+ */
+
+/**
+ * @typedef {Object} ImportPreview
+ * @property {number} [duplicates]
+ * @property {string} [format]
+ * @property {number} [plan_id]
+ * @property {Array<ImportRow>} [rows]
+ * @property {number} [skipped]
+ */
+
+/**
+ * @typedef {Object} ImportResult
+ * @property {number} [duplicates]
+ * @property {Array<Expense>} [expenses]
+ * @property {number} [imported]
+ * @property {number} [plan_id]
+ * @property {number} [skipped]
+ */
+
+/**
+ * @typedef {Object} ImportRow
+ * @property {number} [amount]
+ * @property {number} [category_id]
+ * @property {string} [category_name]
+ * @property {string} [currency]
+ * @property {string} [date]
+ * @property {string} [description]
+ * @property {(number|null)} [duplicate_of]
+ * @property {number} [line]
+ * @property {string} [skip_reason]
+ * @property {Array<string>} [tags]
+ */
+
+/**
+ * @typedef {Object} Imported
+ * @property {number} [imported]
+ */
+
+/**
+ * @typedef {Object} Income
+ * @property {number} [amount]
+ * @property {number} [base_amount]
+ * @property {number} [budget_id]
+ * @property {string} [currency]
+ * @property {string} [date]
+ * @property {string} [description]
+ * @property {number} [exchange_rate]
+ * @property {number} [id]
+ * @property {string} [source]
+ */
+
+/**
+ * @typedef {Object} IncomeRequest
+ * @property {number} [amount]
+ * @property {number} budget_id
+ * @property {string} [currency]
+ * @property {string} [date]
+ * @property {string} [description]
+ * @property {string} [source]
+ */
+
+/**
+ * @typedef {Object} IncomeUpdateRequest
+ * @property {number} [amount]
+ * @property {string} [currency]
+ * @property {string} [date]
+ * @property {string} [description]
+ * @property {number} id
+ * @property {string} [source]
+ */
+
+/**
+ * @typedef {Object} InvitationRequest
+ * @property {string} email
+ * @property {number} plan_id
+ * @property {string} role
+ */
+
+/**
+ * @typedef {Object} LoginRequest
+ * @property {string} email
+ * @property {string} password
+ */
+
+/**
+ * @typedef {Object} MemberBalance
+ * @property {number} [balance]
+ * @property {string} [email]
+ * @property {string} [name]
+ * @property {number} [owed]
+ * @property {number} [paid]
+ * @property {number} [received]
+ * @property {number} [sent]
+ * @property {number} [user_id]
+ */
+
+/**
+ * @typedef {Object} MemberRoleRequest
+ * @property {number} plan_id
+ * @property {string} role
+ * @property {number} user_id
+ */
+
+/**
+ * @typedef {Object} MergeTagsRequest
+ * @property {number} plan_id
+ * @property {Array<number>} source_ids
+ * @property {number} target_id
+ */
+
+/**
+ * @typedef {Object} Message
+ * @property {string} [message]
+ */
+
+/**
+ * @typedef {Object} PasswordRequest
+ * @property {string} new_password
+ */
+
+/**
+ * @typedef {Object} PlanAdjustment
+ * @property {number} [amount]
+ * @property {string} [author_email]
+ * @property {(number|null)} [author_id]
+ * @property {string} [created_at]
+ * @property {number} [exchange_rate]
+ * @property {number} [id]
+ * @property {number} [original_amount]
+ * @property {number} [plan_id]
+ * @property {string} [reason]
+ * @property {number} [total_after]
+ */
+
+/**
+ * @typedef {Object} PlanAmountRequest
+ * @property {boolean} [add]
+ * @property {number} [amount]
+ * @property {string} [currency]
+ * @property {string} [date]
+ * @property {number} id
+ * @property {string} [reason]
+ */
+
+/**
+ * @typedef {Object} PlanInvitation
+ * @property {string} [created_at]
+ * @property {string} [email]
+ * @property {number} [id]
+ * @property {(number|null)} [invited_by]
+ * @property {number} [plan_id]
+ * @property {string} [plan_name]
+ * @property {(string|null)} [responded_at]
+ * @property {string} [role]
+ * @property {string} [status]
+ */
+
+/**
+ * @typedef {Object} PlanMember
+ * @property {string} [email]
+ * @property {(number|null)} [invited_by]
+ * @property {string} [joined_at]
+ * @property {string} [name]
+ * @property {number} [plan_id]
+ * @property {string} [role]
+ * @property {number} [user_id]
+ */
+
+/**
+ * @typedef {Object} PlanSummary
+ * @property {number} [expenseCount]
+ * @property {number} [expenseTotal]
+ */
+
+/**
+ * @typedef {Object} RecurringExpense
+ * @property {number} [amount]
+ * @property {number} [budget_id]
+ * @property {string} [by_day]
+ * @property {number} [by_month_day]
+ * @property {number} [category_id]
+ * @property {string} [category_name]
+ * @property {number} [count]
+ * @property {string} [description]
+ * @property {(string|null)} [end_date]
+ * @property {string} [frequency]
+ * @property {number} [id]
+ * @property {number} [interval]
+ * @property {(string|null)} [next_occurrence]
+ * @property {boolean} [paused]
+ * @property {Array<string>} [skipped_dates]
+ * @property {string} [start_date]
+ * @property {number} [user_id]
+ */
+
+/**
+ * @typedef {Object} RecurringExpenseRequest
+ * @property {number} [amount]
+ * @property {number} budget_id
+ * @property {string} [by_day]
+ * @property {number} [by_month_day]
+ * @property {number} [category_id]
+ * @property {string} [category_name]
+ * @property {number} [count]
+ * @property {string} [currency]
+ * @property {string} [description]
+ * @property {(string|null)} [end_date]
+ * @property {string} [frequency]
+ * @property {number} [interval]
+ * @property {string} [rrule]
+ * @property {string} [start_date]
+ */
+
+/**
+ * @typedef {Object} RefreshRequest
+ * @property {string} refresh_token
+ */
+
+/**
+ * @typedef {Object} Report
+ * @property {string} [currency]
+ * @property {(string|null)} [from]
+ * @property {string} [group_by]
+ * @property {number} [plan_id]
+ * @property {Array<ReportRow>} [rows]
+ * @property {(string|null)} [to]
+ * @property {number} [total]
+ */
+
+/**
+ * @typedef {Object} ReportRow
+ * @property {Array<ReportRow>} [children]
+ * @property {number} [count]
+ * @property {string} [name]
+ * @property {number} [value]
+ */
+
+/**
+ * @typedef {Object} RestoreResult
+ * @property {number} [categories]
+ * @property {number} [expenses]
+ * @property {number} [plans]
+ */
+
+/**
+ * @typedef {Object} RuleChange
+ * @property {Array<string>} [added_tags]
+ * @property {string} [description]
+ * @property {number} [expense_id]
+ * @property {string} [from_category]
+ * @property {Array<number>} [rule_ids]
+ * @property {string} [to_category]
+ */
+
+/**
+ * @typedef {Object} RuleRun
+ * @property {number} [changed]
+ * @property {Array<RuleChange>} [changes]
+ * @property {boolean} [dry_run]
+ * @property {number} [plan_id]
+ * @property {number} [scanned]
+ */
+
+/**
+ * @typedef {Object} RuleRunRequest
+ * @property {number} plan_id
+ */
+
+/**
+ * @typedef {Object} SearchPage
+ * @property {Array<Expense>} [items]
+ * @property {string} [next_cursor]
+ */
+
+/**
+ * @typedef {Object} Settlement
+ * @property {Array<MemberBalance>} [balances]
+ * @property {string} [currency]
+ * @property {number} [plan_id]
+ * @property {Array<Transfer>} [transfers]
+ */
+
+/**
+ * @typedef {Object} SettlementPayment
+ * @property {number} [amount]
+ * @property {string} [created_at]
+ * @property {(number|null)} [created_by]
+ * @property {string} [date]
+ * @property {number} [from_user_id]
+ * @property {number} [id]
+ * @property {string} [note]
+ * @property {number} [plan_id]
+ * @property {number} [to_user_id]
+ */
+
+/**
+ * @typedef {Object} SettlementPaymentRequest
+ * @property {number} [amount]
+ * @property {string} [currency]
+ * @property {string} [date]
+ * @property {number} from_user_id
+ * @property {string} [note]
+ * @property {number} plan_id
+ * @property {number} to_user_id
+ */
+
+/**
+ * @typedef {Object} SplitRequest
+ * @property {number} expense_id
+ * @property {("equal"|"percentage"|"exact")} method
+ * @property {Array<SplitShare>} [shares]
+ */
+
+/**
+ * @typedef {Object} SplitShare
+ * @property {number} [amount]
+ * @property {number} [percent]
+ * @property {number} [user_id]
+ */
+
+/**
+ * @typedef {Object} Tag
+ * @property {string} [created_at]
+ * @property {number} [id]
+ * @property {string} [name]
+ * @property {number} [plan_id]
+ * @property {number} [usage]
+ */
+
+/**
+ * @typedef {Object} TagRequest
+ * @property {number} id
+ * @property {string} name
+ * @property {number} plan_id
+ */
+
+/**
+ * @typedef {Object} TokenResponse
+ * @property {number} [expires_in]
+ * @property {string} [refresh_token]
+ * @property {string} [token]
+ * @property {string} [token_type]
+ */
+
+/**
+ * @typedef {Object} Transfer
+ * @property {number} [amount]
+ * @property {number} [from_user_id]
+ * @property {number} [to_user_id]
+ */
+
+/**
+ * @typedef {Object} UserRequest
+ * @property {string} email
+ * @property {string} name
+ * @property {string} password
+ */
+
+/**
+ * @typedef {Object} UserResponse
+ * @property {string} [created_date]
+ * @property {string} [email]
+ * @property {number} [id]
+ * @property {string} [name]
+ */
+
+/**
+ * @typedef {Object} UserUpdateRequest
+ * @property {string} email
+ * @property {string} name
+ */
+
+/**
+ * Find a user by email.
+ *
+ * @param {Object} params
+ * @param {string} params.email - Email of the user.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<UserResponse>>}
+ */
+export const findUserByEmail = (params, config = {}) =>
+    api.request({ ...config, method: 'get', url: '/users', params });
+
+/**
+ * Register a user.
+ *
+ * @param {UserRequest} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<UserResponse>>}
+ */
+export const createUser = (body, config = {}) =>
+    api.request({ ...config, method: 'post', url: '/users', data: body });
+
+/**
+ * Change the name and email of the caller.
+ *
+ * @param {UserUpdateRequest} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<void>>}
+ */
+export const updateUser = (body, config = {}) =>
+    api.request({ ...config, method: 'put', url: '/users', data: body });
+
+/**
+ * Delete the account of the caller.
+ *
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<void>>}
+ */
+export const deleteUser = (config = {}) =>
+    api.request({ ...config, method: 'delete', url: '/users' });
+
+/**
+ * Log in and open a session.
+ *
+ * @param {LoginRequest} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<TokenResponse>>}
+ */
+export const login = (body, config = {}) =>
+    api.request({ ...config, method: 'post', url: '/users/login', data: body });
+
+/**
+ * Revoke the current session.
+ *
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<void>>}
+ */
+export const logout = (config = {}) =>
+    api.request({ ...config, method: 'post', url: '/users/logout' });
+
+/**
+ * Revoke every session of the caller.
+ *
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<void>>}
+ */
+export const logoutAll = (config = {}) =>
+    api.request({ ...config, method: 'post', url: '/users/logout/all' });
+
+/**
+ * Change the password of the caller.
+ *
+ * @param {PasswordRequest} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<string>>}
+ */
+export const updatePassword = (body, config = {}) =>
+    api.request({ ...config, method: 'put', url: '/users/password', data: body });
+
+/**
+ * Exchange a refresh token for new tokens.
+ *
+ * @param {RefreshRequest} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<TokenResponse>>}
+ */
+export const refreshTokens = (body, config = {}) =>
+    api.request({ ...config, method: 'post', url: '/users/refresh', data: body });
+
+/**
+ * Create a plan.
+ *
+ * @param {BudgetPlanRequest} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<BudgetPlan>>}
+ */
+export const createPlan = (body, config = {}) =>
+    api.request({ ...config, method: 'post', url: '/plan', data: body });
+
+/**
+ * Edit a plan.
+ *
+ * @param {BudgetPlanUpdateRequest} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<void>>}
+ */
+export const updatePlan = (body, config = {}) =>
+    api.request({ ...config, method: 'put', url: '/plan', data: body });
+
+/**
+ * Delete a plan.
+ *
+ * @param {Object} params
+ * @param {number} params.id - ID of the plan.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<void>>}
+ */
+export const deletePlan = (params, config = {}) =>
+    api.request({ ...config, method: 'delete', url: '/plan', params });
+
+/**
+ * Add to or subtract from the total of a plan.
+ *
+ * @param {PlanAmountRequest} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<PlanAdjustment>>}
+ */
+export const updatePlanAmount = (body, config = {}) =>
+    api.request({ ...config, method: 'put', url: '/plan/amount', data: body });
+
+/**
+ * List the changes to the total of a plan.
+ *
+ * @param {Object} params
+ * @param {number} params.id - ID of the plan.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Array<PlanAdjustment>>>}
+ */
+export const getPlanAmountHistory = (params, config = {}) =>
+    api.request({ ...config, method: 'get', url: '/plan/amount/history', params });
+
+/**
+ * List the budget periods of a plan.
+ *
+ * @param {Object} params
+ * @param {number} params.plan - ID of the plan.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Array<BudgetPeriod>>>}
+ */
+export const listPeriods = (params, config = {}) =>
+    api.request({ ...config, method: 'get', url: '/plan/periods', params });
+
+/**
+ * Get the current budget period of a plan.
+ *
+ * @param {Object} params
+ * @param {number} params.plan - ID of the plan.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<BudgetPeriod>>}
+ */
+export const getCurrentPeriod = (params, config = {}) =>
+    api.request({ ...config, method: 'get', url: '/plan/periods/current', params });
+
+/**
+ * List the plans of the caller.
+ *
+ * @param {Object} [params]
+ * @param {string} [params.name] - Only plans whose name contains this text.
+ * @param {string} [params.role] - Only plans where the caller is owner, editor or viewer.
+ * @param {string} [params.period] - Only plans with this period.
+ * @param {string} [params.expenses] - summary (default), full or none.
+ * @param {number} [params.limit] - Page size, 50 by default and at most 200. Without limit nor cursor the first page is written as a plain array, with the cursor of the next one in the X-Next-Cursor header.
+ * @param {string} [params.sort] - Field to sort by, prefixed with - for descending order.
+ * @param {string} [params.cursor] - next_cursor of the previous page.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<(Array<BudgetPlan>|BudgetPlanPage)>>}
+ */
+export const listPlans = (params, config = {}) =>
+    api.request({ ...config, method: 'get', url: '/plan/user', params });
+
+/**
+ * List the pending invitations of a plan.
+ *
+ * @param {Object} params
+ * @param {number} params.plan - ID of the plan.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Array<PlanInvitation>>>}
+ */
+export const listInvitations = (params, config = {}) =>
+    api.request({ ...config, method: 'get', url: '/plan/invitations', params });
+
+/**
+ * Invite a user to a plan.
+ *
+ * @param {InvitationRequest} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<PlanInvitation>>}
+ */
+export const invite = (body, config = {}) =>
+    api.request({ ...config, method: 'post', url: '/plan/invitations', data: body });
+
+/**
+ * Revoke an invitation.
+ *
+ * @param {Object} params
+ * @param {number} params.id - ID of the resource.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<void>>}
+ */
+export const revokeInvitation = (params, config = {}) =>
+    api.request({ ...config, method: 'delete', url: '/plan/invitations', params });
+
+/**
+ * Accept an invitation.
+ *
+ * @param {Object} params
+ * @param {number} params.id - ID of the resource.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<PlanInvitation>>}
+ */
+export const acceptInvitation = (params, config = {}) =>
+    api.request({ ...config, method: 'post', url: '/plan/invitations/accept', params });
+
+/**
+ * Decline an invitation.
+ *
+ * @param {Object} params
+ * @param {number} params.id - ID of the resource.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<PlanInvitation>>}
+ */
+export const declineInvitation = (params, config = {}) =>
+    api.request({ ...config, method: 'post', url: '/plan/invitations/decline', params });
+
+/**
+ * List the invitations addressed to the caller.
+ *
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Array<PlanInvitation>>>}
+ */
+export const listPendingInvitations = (config = {}) =>
+    api.request({ ...config, method: 'get', url: '/plan/invitations/pending' });
+
+/**
+ * List the members of a plan.
+ *
+ * @param {Object} params
+ * @param {number} params.plan - ID of the plan.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Array<PlanMember>>>}
+ */
+export const listMembers = (params, config = {}) =>
+    api.request({ ...config, method: 'get', url: '/plan/members', params });
+
+/**
+ * Change the role of a member.
+ *
+ * @param {MemberRoleRequest} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<void>>}
+ */
+export const updateMemberRole = (body, config = {}) =>
+    api.request({ ...config, method: 'put', url: '/plan/members', data: body });
+
+/**
+ * Remove a member from a plan.
+ *
+ * @param {Object} params
+ * @param {number} params.plan - ID of the plan.
+ * @param {number} params.user - ID of the member.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<void>>}
+ */
+export const removeMember = (params, config = {}) =>
+    api.request({ ...config, method: 'delete', url: '/plan/members', params });
+
+/**
+ * List the categories of the caller.
+ *
+ * @param {Object} [params]
+ * @param {boolean} [params.tree] - Nest subcategories under their parents instead of listing them.
+ * @param {number} [params.parent] - Only the subcategories of this category.
+ * @param {string} [params.name] - Only categories whose name contains this text.
+ * @param {number} [params.limit] - Page size, 50 by default and at most 200. Without limit nor cursor the first page is written as a plain array, with the cursor of the next one in the X-Next-Cursor header.
+ * @param {string} [params.sort] - Field to sort by, prefixed with - for descending order.
+ * @param {string} [params.cursor] - next_cursor of the previous page.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<(Array<Category>|CategoryPage)>>}
+ */
+export const listCategories = (params, config = {}) =>
+    api.request({ ...config, method: 'get', url: '/category', params });
+
+/**
+ * Create a category.
+ *
+ * @param {CategoryRequest} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Category>>}
+ */
+export const createCategory = (body, config = {}) =>
+    api.request({ ...config, method: 'post', url: '/category', data: body });
+
+/**
+ * Rename or move a category.
+ *
+ * @param {Object} params
+ * @param {number} params.id - ID of the resource.
+ * @param {CategoryRequest} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Category>>}
+ */
+export const updateCategory = (params, body, config = {}) =>
+    api.request({ ...config, method: 'put', url: '/category', params, data: body });
+
+/**
+ * Delete a category.
+ *
+ * @param {Object} params
+ * @param {number} params.id - ID of the resource.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<void>>}
+ */
+export const deleteCategory = (params, config = {}) =>
+    api.request({ ...config, method: 'delete', url: '/category', params });
+
+/**
+ * Get a category by ID.
+ *
+ * @param {Object} params
+ * @param {number} params.id - ID of the resource.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Category>>}
+ */
+export const getCategory = (params, config = {}) =>
+    api.request({ ...config, method: 'get', url: '/category/id', params });
+
+/**
+ * Get a category by name.
+ *
+ * @param {Object} params
+ * @param {string} params.name - Name of the category.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Category>>}
+ */
+export const getCategoryByName = (params, config = {}) =>
+    api.request({ ...config, method: 'get', url: '/category/name', params });
+
+/**
+ * Add an expense to a plan.
+ *
+ * @param {ExpenseRequest} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Expense>>}
+ */
+export const createExpense = (body, config = {}) =>
+    api.request({ ...config, method: 'post', url: '/expense', data: body });
+
+/**
+ * Edit an expense.
+ *
+ * @param {ExpenseUpdateRequest} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<void>>}
+ */
+export const updateExpense = (body, config = {}) =>
+    api.request({ ...config, method: 'put', url: '/expense', data: body });
+
+/**
+ * Delete an expense.
+ *
+ * @param {DeleteExpenseRequest} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Message>>}
+ */
+export const deleteExpense = (body, config = {}) =>
+    api.request({ ...config, method: 'delete', url: '/expense', data: body });
+
+/**
+ * List the expenses of a category.
+ *
+ * @param {Object} params
+ * @param {number} params.id - ID of the resource.
+ * @param {number} [params.limit] - Page size, 50 by default and at most 200. Without limit nor cursor the first page is written as a plain array, with the cursor of the next one in the X-Next-Cursor header.
+ * @param {string} [params.sort] - Field to sort by, prefixed with - for descending order.
+ * @param {string} [params.cursor] - next_cursor of the previous page.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<(Array<Expense>|ExpensePage)>>}
+ */
+export const listExpensesByCategory = (params, config = {}) =>
+    api.request({ ...config, method: 'get', url: '/expense/category', params });
+
+/**
+ * List the expenses of a plan.
+ *
+ * @param {Object} params
+ * @param {number} params.id - ID of the plan.
+ * @param {number} [params.period_id] - Only the expenses of this budget period.
+ * @param {number} [params.category] - Only the expenses of this category.
+ * @param {number} [params.paid_by] - Only the expenses paid by this member.
+ * @param {boolean} [params.recurring] - Only recurring or only one-off expenses.
+ * @param {string} [params.from] - First day, inclusive.
+ * @param {string} [params.to] - Last day, inclusive.
+ * @param {number} [params.min_amount] - Smallest amount, inclusive.
+ * @param {number} [params.max_amount] - Largest amount, inclusive.
+ * @param {string} [params.tags] - Comma separated tag names.
+ * @param {string} [params.match] - any (default) or all of the tags.
+ * @param {number} [params.limit] - Page size, 50 by default and at most 200. Without limit nor cursor the first page is written as a plain array, with the cursor of the next one in the X-Next-Cursor header.
+ * @param {string} [params.sort] - Field to sort by, prefixed with - for descending order.
+ * @param {string} [params.cursor] - next_cursor of the previous page.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<(Array<Expense>|ExpensePage)>>}
+ */
+export const listExpensesByPlan = (params, config = {}) =>
+    api.request({ ...config, method: 'get', url: '/expense/plan', params });
+
+/**
+ * Search expenses by text.
+ *
+ * @param {Object} [params]
+ * @param {string} [params.q] - Words to search for in descriptions, categories and tags.
+ * @param {number} [params.plan] - Only the expenses of this plan.
+ * @param {number} [params.category] - Only the expenses of this category.
+ * @param {string} [params.from] - First day, inclusive.
+ * @param {string} [params.to] - Last day, inclusive.
+ * @param {number} [params.min_amount] - Smallest amount, inclusive.
+ * @param {number} [params.max_amount] - Largest amount, inclusive.
+ * @param {string} [params.currency] - Currency of the amount bounds.
+ * @param {number} [params.limit] - Page size.
+ * @param {string} [params.cursor] - next_cursor of the previous page.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<SearchPage>>}
+ */
+export const searchExpenses = (params, config = {}) =>
+    api.request({ ...config, method: 'get', url: '/expense/search', params });
+
+/**
+ * List the attachments of an expense.
+ *
+ * @param {Object} params
+ * @param {number} params.expense - ID of the expense.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Array<Attachment>>>}
+ */
+export const listAttachments = (params, config = {}) =>
+    api.request({ ...config, method: 'get', url: '/expense/attachments', params });
+
+/**
+ * Attach a receipt to an expense.
+ *
+ * @param {Object} params
+ * @param {number} params.expense - ID of the expense.
+ * @param {FormData} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Attachment>>}
+ */
+export const uploadAttachment = (params, body, config = {}) =>
+    api.request({ ...config, method: 'post', url: '/expense/attachments', params, data: body });
+
+/**
+ * Delete an attachment.
+ *
+ * @param {Object} params
+ * @param {number} params.id - ID of the resource.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<void>>}
+ */
+export const deleteAttachment = (params, config = {}) =>
+    api.request({ ...config, method: 'delete', url: '/expense/attachments', params });
+
+/**
+ * Download the file of an attachment.
+ *
+ * @param {Object} params
+ * @param {number} params.id - ID of the resource.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Blob>>}
+ */
+export const downloadAttachment = (params, config = {}) =>
+    api.request({ ...config, method: 'get', url: '/expense/attachments/download', params });
+
+/**
+ * List the tags of a plan.
+ *
+ * @param {Object} params
+ * @param {number} params.plan - ID of the plan.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Array<Tag>>>}
+ */
+export const listTags = (params, config = {}) =>
+    api.request({ ...config, method: 'get', url: '/tags', params });
+
+/**
+ * Rename a tag.
+ *
+ * @param {TagRequest} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Tag>>}
+ */
+export const renameTag = (body, config = {}) =>
+    api.request({ ...config, method: 'put', url: '/tags', data: body });
+
+/**
+ * Delete a tag.
+ *
+ * @param {Object} params
+ * @param {number} params.id - ID of the resource.
+ * @param {number} params.plan - ID of the plan.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<void>>}
+ */
+export const deleteTag = (params, config = {}) =>
+    api.request({ ...config, method: 'delete', url: '/tags', params });
+
+/**
+ * Merge tags into another.
+ *
+ * @param {MergeTagsRequest} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<void>>}
+ */
+export const mergeTags = (body, config = {}) =>
+    api.request({ ...config, method: 'post', url: '/tags/merge', data: body });
+
+/**
+ * List the rules of the caller.
+ *
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Array<ExpenseRule>>>}
+ */
+export const listRules = (config = {}) =>
+    api.request({ ...config, method: 'get', url: '/rules' });
+
+/**
+ * Create a rule.
+ *
+ * @param {ExpenseRule} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<ExpenseRule>>}
+ */
+export const createRule = (body, config = {}) =>
+    api.request({ ...config, method: 'post', url: '/rules', data: body });
+
+/**
+ * Edit a rule.
+ *
+ * @param {ExpenseRule} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<ExpenseRule>>}
+ */
+export const updateRule = (body, config = {}) =>
+    api.request({ ...config, method: 'put', url: '/rules', data: body });
+
+/**
+ * Delete a rule.
+ *
+ * @param {Object} params
+ * @param {number} params.id - ID of the resource.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<void>>}
+ */
+export const deleteRule = (params, config = {}) =>
+    api.request({ ...config, method: 'delete', url: '/rules', params });
+
+/**
+ * Apply the rules to the expenses of a plan.
+ *
+ * @param {RuleRunRequest} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<RuleRun>>}
+ */
+export const applyRules = (body, config = {}) =>
+    api.request({ ...config, method: 'post', url: '/rules/apply', data: body });
+
+/**
+ * Report what applying the rules would change.
+ *
+ * @param {RuleRunRequest} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<RuleRun>>}
+ */
+export const dryRunRules = (body, config = {}) =>
+    api.request({ ...config, method: 'post', url: '/rules/dry-run', data: body });
+
+/**
+ * List the shares of an expense.
+ *
+ * @param {Object} params
+ * @param {number} params.id - ID of the resource.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Array<ExpenseSplit>>>}
+ */
+export const listSplits = (params, config = {}) =>
+    api.request({ ...config, method: 'get', url: '/expense/splits', params });
+
+/**
+ * Share an expense between members.
+ *
+ * @param {SplitRequest} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Array<ExpenseSplit>>>}
+ */
+export const splitExpense = (body, config = {}) =>
+    api.request({ ...config, method: 'put', url: '/expense/splits', data: body });
+
+/**
+ * Stop sharing an expense.
+ *
+ * @param {Object} params
+ * @param {number} params.id - ID of the resource.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<void>>}
+ */
+export const unsplitExpense = (params, config = {}) =>
+    api.request({ ...config, method: 'delete', url: '/expense/splits', params });
+
+/**
+ * Compute who owes whom in a plan.
+ *
+ * @param {Object} params
+ * @param {number} params.plan - ID of the plan.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Settlement>>}
+ */
+export const getSettlement = (params, config = {}) =>
+    api.request({ ...config, method: 'get', url: '/plan/settlement', params });
+
+/**
+ * List the payments between members.
+ *
+ * @param {Object} params
+ * @param {number} params.plan - ID of the plan.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Array<SettlementPayment>>>}
+ */
+export const listPayments = (params, config = {}) =>
+    api.request({ ...config, method: 'get', url: '/plan/settlement/payments', params });
+
+/**
+ * Record a payment between members.
+ *
+ * @param {SettlementPaymentRequest} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<SettlementPayment>>}
+ */
+export const recordPayment = (body, config = {}) =>
+    api.request({ ...config, method: 'post', url: '/plan/settlement/payments', data: body });
+
+/**
+ * Delete a payment.
+ *
+ * @param {Object} params
+ * @param {number} params.id - ID of the resource.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<void>>}
+ */
+export const deletePayment = (params, config = {}) =>
+    api.request({ ...config, method: 'delete', url: '/plan/settlement/payments', params });
+
+/**
+ * List the category allocations of a plan.
+ *
+ * @param {Object} params
+ * @param {number} params.plan - ID of the plan.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Array<CategoryBudget>>>}
+ */
+export const listCategoryBudgets = (params, config = {}) =>
+    api.request({ ...config, method: 'get', url: '/plan/budgets', params });
+
+/**
+ * Allocate part of a plan to a category.
+ *
+ * @param {CategoryBudgetRequest} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<CategoryBudget>>}
+ */
+export const saveCategoryBudget = (body, config = {}) =>
+    api.request({ ...config, method: 'put', url: '/plan/budgets', data: body });
+
+/**
+ * Remove the allocation of a category.
+ *
+ * @param {Object} params
+ * @param {number} params.plan - ID of the plan.
+ * @param {number} params.category - ID of the category.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<void>>}
+ */
+export const deleteCategoryBudget = (params, config = {}) =>
+    api.request({ ...config, method: 'delete', url: '/plan/budgets', params });
+
+/**
+ * Compare the allocations of a plan with its expenses.
+ *
+ * @param {Object} params
+ * @param {number} params.plan - ID of the plan.
+ * @param {number} [params.period_id] - Budget period to report on, instead of from and to.
+ * @param {string} [params.from] - First day, inclusive.
+ * @param {string} [params.to] - Last day, inclusive.
+ * @param {boolean} [params.recurring] - Only recurring or only one-off expenses.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<BudgetStatus>>}
+ */
+export const getBudgetStatus = (params, config = {}) =>
+    api.request({ ...config, method: 'get', url: '/plan/budgets/status', params });
+
+/**
+ * Create a recurring expense.
+ *
+ * @param {RecurringExpenseRequest} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<RecurringExpense>>}
+ */
+export const createRecurringExpense = (body, config = {}) =>
+    api.request({ ...config, method: 'post', url: '/recurring', data: body });
+
+/**
+ * Edit the future occurrences of a recurring expense.
+ *
+ * @param {Object} params
+ * @param {number} params.id - ID of the resource.
+ * @param {RecurringExpenseRequest} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<RecurringExpense>>}
+ */
+export const updateRecurringExpense = (params, body, config = {}) =>
+    api.request({ ...config, method: 'put', url: '/recurring', params, data: body });
+
+/**
+ * Delete a recurring expense.
+ *
+ * @param {Object} params
+ * @param {number} params.id - ID of the resource.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<void>>}
+ */
+export const deleteRecurringExpense = (params, config = {}) =>
+    api.request({ ...config, method: 'delete', url: '/recurring', params });
+
+/**
+ * Pause a recurring expense.
+ *
+ * @param {Object} params
+ * @param {number} params.id - ID of the resource.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<void>>}
+ */
+export const pauseRecurringExpense = (params, config = {}) =>
+    api.request({ ...config, method: 'put', url: '/recurring/pause', params });
+
+/**
+ * List the recurring expenses of a plan.
+ *
+ * @param {Object} params
+ * @param {number} params.id - ID of the plan.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Array<RecurringExpense>>>}
+ */
+export const listRecurringExpenses = (params, config = {}) =>
+    api.request({ ...config, method: 'get', url: '/recurring/plan', params });
+
+/**
+ * Resume a recurring expense.
+ *
+ * @param {Object} params
+ * @param {number} params.id - ID of the resource.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<void>>}
+ */
+export const resumeRecurringExpense = (params, config = {}) =>
+    api.request({ ...config, method: 'put', url: '/recurring/resume', params });
+
+/**
+ * Skip an occurrence of a recurring expense.
+ *
+ * @param {Object} params
+ * @param {number} params.id - ID of the resource.
+ * @param {string} [params.date] - Day of the occurrence; defaults to the next one.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<void>>}
+ */
+export const skipOccurrence = (params, config = {}) =>
+    api.request({ ...config, method: 'put', url: '/recurring/skip', params });
+
+/**
+ * Add an income to a plan.
+ *
+ * @param {IncomeRequest} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Income>>}
+ */
+export const createIncome = (body, config = {}) =>
+    api.request({ ...config, method: 'post', url: '/income', data: body });
+
+/**
+ * Edit an income.
+ *
+ * @param {IncomeUpdateRequest} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Income>>}
+ */
+export const updateIncome = (body, config = {}) =>
+    api.request({ ...config, method: 'put', url: '/income', data: body });
+
+/**
+ * Delete an income.
+ *
+ * @param {Object} params
+ * @param {number} params.id - ID of the resource.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<void>>}
+ */
+export const deleteIncome = (params, config = {}) =>
+    api.request({ ...config, method: 'delete', url: '/income', params });
+
+/**
+ * List the incomes of a plan.
+ *
+ * @param {Object} params
+ * @param {number} params.plan - ID of the plan.
+ * @param {number} [params.period_id] - Budget period to report on, instead of from and to.
+ * @param {string} [params.from] - First day, inclusive.
+ * @param {string} [params.to] - Last day, inclusive.
+ * @param {boolean} [params.recurring] - Only recurring or only one-off expenses.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Array<Income>>>}
+ */
+export const listIncomesByPlan = (params, config = {}) =>
+    api.request({ ...config, method: 'get', url: '/income/plan', params });
+
+/**
+ * Compare the incomes and expenses of a plan.
+ *
+ * @param {Object} params
+ * @param {number} params.plan - ID of the plan.
+ * @param {number} [params.period_id] - Budget period to report on, instead of from and to.
+ * @param {string} [params.from] - First day, inclusive.
+ * @param {string} [params.to] - Last day, inclusive.
+ * @param {boolean} [params.recurring] - Only recurring or only one-off expenses.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Balance>>}
+ */
+export const getBalance = (params, config = {}) =>
+    api.request({ ...config, method: 'get', url: '/plan/balance', params });
+
+/**
+ * Sum the expenses of a plan by category.
+ *
+ * @param {Object} params
+ * @param {number} params.plan - ID of the plan.
+ * @param {number} [params.period_id] - Budget period to report on, instead of from and to.
+ * @param {string} [params.from] - First day, inclusive.
+ * @param {string} [params.to] - Last day, inclusive.
+ * @param {boolean} [params.recurring] - Only recurring or only one-off expenses.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Report>>}
+ */
+export const reportByCategory = (params, config = {}) =>
+    api.request({ ...config, method: 'get', url: '/reports/category', params });
+
+/**
+ * Sum the expenses of a plan by day, week or month.
+ *
+ * @param {Object} params
+ * @param {number} params.plan - ID of the plan.
+ * @param {number} [params.period_id] - Budget period to report on, instead of from and to.
+ * @param {string} [params.from] - First day, inclusive.
+ * @param {string} [params.to] - Last day, inclusive.
+ * @param {boolean} [params.recurring] - Only recurring or only one-off expenses.
+ * @param {string} [params.period] - day, week or month (default).
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Report>>}
+ */
+export const reportByPeriod = (params, config = {}) =>
+    api.request({ ...config, method: 'get', url: '/reports/period', params });
+
+/**
+ * Sum the recurring and one-off expenses of a plan.
+ *
+ * @param {Object} params
+ * @param {number} params.plan - ID of the plan.
+ * @param {number} [params.period_id] - Budget period to report on, instead of from and to.
+ * @param {string} [params.from] - First day, inclusive.
+ * @param {string} [params.to] - Last day, inclusive.
+ * @param {boolean} [params.recurring] - Only recurring or only one-off expenses.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Report>>}
+ */
+export const reportByRecurrence = (params, config = {}) =>
+    api.request({ ...config, method: 'get', url: '/reports/recurrence', params });
+
+/**
+ * Sum the expenses of a plan by tag.
+ *
+ * @param {Object} params
+ * @param {number} params.plan - ID of the plan.
+ * @param {number} [params.period_id] - Budget period to report on, instead of from and to.
+ * @param {string} [params.from] - First day, inclusive.
+ * @param {string} [params.to] - Last day, inclusive.
+ * @param {boolean} [params.recurring] - Only recurring or only one-off expenses.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Report>>}
+ */
+export const reportByTag = (params, config = {}) =>
+    api.request({ ...config, method: 'get', url: '/reports/tag', params });
+
+/**
+ * Export the data of the caller.
+ *
+ * @param {Object} [params]
+ * @param {string} [params.format] - csv, json (default) or ofx.
+ * @param {number} [params.plan] - Only the expenses of this plan.
+ * @param {string} [params.from] - First day, inclusive.
+ * @param {string} [params.to] - Last day, inclusive.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<ExportDocument>>}
+ */
+export const exportData = (params, config = {}) =>
+    api.request({ ...config, method: 'get', url: '/export', params });
+
+/**
+ * Import the rows of a preview.
+ *
+ * @param {ImportCommitRequest} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<ImportResult>>}
+ */
+export const commitImport = (body, config = {}) =>
+    api.request({ ...config, method: 'post', url: '/import/commit', data: body });
+
+/**
+ * Parse a bank statement without importing it.
+ *
+ * @param {Object} params
+ * @param {number} params.plan - ID of the plan to import into.
+ * @param {string} [params.format] - csv or ofx; defaults to the extension of the file.
+ * @param {FormData} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<ImportPreview>>}
+ */
+export const previewImport = (params, body, config = {}) =>
+    api.request({ ...config, method: 'post', url: '/import/preview', params, data: body });
+
+/**
+ * Restore a JSON export.
+ *
+ * @param {ExportDocument} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<RestoreResult>>}
+ */
+export const restoreBackup = (body, config = {}) =>
+    api.request({ ...config, method: 'post', url: '/import/restore', data: body });
+
+/**
+ * List exchange rates.
+ *
+ * @param {Object} [params]
+ * @param {string} [params.base] - Base currency.
+ * @param {string} [params.quote] - Quote currency.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Array<ExchangeRate>>>}
+ */
+export const listExchangeRates = (params, config = {}) =>
+    api.request({ ...config, method: 'get', url: '/rates', params });
+
+/**
+ * Store an exchange rate.
+ *
+ * @param {ExchangeRateRequest} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<ExchangeRate>>}
+ */
+export const createExchangeRate = (body, config = {}) =>
+    api.request({ ...config, method: 'post', url: '/rates', data: body });
+
+/**
+ * Store the exchange rates of a CSV file.
+ *
+ * @param {*} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Imported>>}
+ */
+export const importExchangeRates = (body, config = {}) =>
+    api.request({ ...config, method: 'post', url: '/rates/import', data: body });
+
+/**
+ * Browse this document.
+ *
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Blob>>}
+ */
+export const getDocs = (config = {}) =>
+    api.request({ ...config, method: 'get', url: '/docs' });
+
+/**
+ * This document.
+ *
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Object>>}
+ */
+export const getOpenAPI = (config = {}) =>
+    api.request({ ...config, method: 'get', url: '/openapi.json' });
+
+/**
+ * List the categories of the caller.
+ *
+ * @param {Object} [params]
+ * @param {boolean} [params.tree] - Nest subcategories under their parents instead of listing them.
+ * @param {number} [params.parent] - Only the subcategories of this category.
+ * @param {string} [params.name] - Only categories whose name contains this text.
+ * @param {number} [params.limit] - Page size, 50 by default and at most 200. Without limit nor cursor the first page is written as a plain array, with the cursor of the next one in the X-Next-Cursor header.
+ * @param {string} [params.sort] - Field to sort by, prefixed with - for descending order.
+ * @param {string} [params.cursor] - next_cursor of the previous page.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<(Array<Category>|CategoryPage)>>}
+ */
+export const v2ListCategories = (params, config = {}) =>
+    api.request({ ...config, method: 'get', url: '/api/v2/categories', params });
+
+/**
+ * Create a category.
+ *
+ * @param {CategoryRequest} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Category>>}
+ */
+export const v2CreateCategory = (body, config = {}) =>
+    api.request({ ...config, method: 'post', url: '/api/v2/categories', data: body });
+
+/**
+ * Get a category.
+ *
+ * @param {number} id - ID of the category.
+ * @param {import('axios').AxiosRequestConfig} [config] - Sends the If-None-Match headers.
+ * @returns {Promise<import('axios').AxiosResponse<Category>>}
+ */
+export const v2GetCategory = (id, config = {}) =>
+    api.request({ ...config, method: 'get', url: `/api/v2/categories/${id}` });
+
+/**
+ * Rename or move a category.
+ *
+ * @param {number} id - ID of the category.
+ * @param {CategoryPatchRequest} body
+ * @param {import('axios').AxiosRequestConfig} [config] - Sends the If-Match headers.
+ * @returns {Promise<import('axios').AxiosResponse<Category>>}
+ */
+export const v2PatchCategory = (id, body, config = {}) =>
+    api.request({ ...config, method: 'patch', url: `/api/v2/categories/${id}`, data: body });
+
+/**
+ * Delete a category.
+ *
+ * @param {number} id - ID of the category.
+ * @param {import('axios').AxiosRequestConfig} [config] - Sends the If-Match headers.
+ * @returns {Promise<import('axios').AxiosResponse<void>>}
+ */
+export const v2DeleteCategory = (id, config = {}) =>
+    api.request({ ...config, method: 'delete', url: `/api/v2/categories/${id}` });
+
+/**
+ * List the plans of the caller.
+ *
+ * @param {Object} [params]
+ * @param {string} [params.name] - Only plans whose name contains this text.
+ * @param {string} [params.role] - Only plans where the caller is owner, editor or viewer.
+ * @param {string} [params.period] - Only plans with this period.
+ * @param {string} [params.expenses] - none (default), summary or full.
+ * @param {number} [params.limit] - Page size, 50 by default and at most 200. Without limit nor cursor the first page is written as a plain array, with the cursor of the next one in the X-Next-Cursor header.
+ * @param {string} [params.sort] - Field to sort by, prefixed with - for descending order.
+ * @param {string} [params.cursor] - next_cursor of the previous page.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<(Array<BudgetPlan>|BudgetPlanPage)>>}
+ */
+export const v2ListPlans = (params, config = {}) =>
+    api.request({ ...config, method: 'get', url: '/api/v2/plans', params });
+
+/**
+ * Create a plan.
+ *
+ * @param {BudgetPlanRequest} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<BudgetPlan>>}
+ */
+export const v2CreatePlan = (body, config = {}) =>
+    api.request({ ...config, method: 'post', url: '/api/v2/plans', data: body });
+
+/**
+ * Get a plan.
+ *
+ * @param {number} id - ID of the plan.
+ * @param {import('axios').AxiosRequestConfig} [config] - Sends the If-None-Match headers.
+ * @returns {Promise<import('axios').AxiosResponse<BudgetPlan>>}
+ */
+export const v2GetPlan = (id, config = {}) =>
+    api.request({ ...config, method: 'get', url: `/api/v2/plans/${id}` });
+
+/**
+ * Edit some fields of a plan.
+ *
+ * @param {number} id - ID of the plan.
+ * @param {BudgetPlanPatchRequest} body
+ * @param {import('axios').AxiosRequestConfig} [config] - Sends the If-Match headers.
+ * @returns {Promise<import('axios').AxiosResponse<BudgetPlan>>}
+ */
+export const v2PatchPlan = (id, body, config = {}) =>
+    api.request({ ...config, method: 'patch', url: `/api/v2/plans/${id}`, data: body });
+
+/**
+ * Delete a plan.
+ *
+ * @param {number} id - ID of the plan.
+ * @param {import('axios').AxiosRequestConfig} [config] - Sends the If-Match headers.
+ * @returns {Promise<import('axios').AxiosResponse<void>>}
+ */
+export const v2DeletePlan = (id, config = {}) =>
+    api.request({ ...config, method: 'delete', url: `/api/v2/plans/${id}` });
+
+/**
+ * List the changes to the total of a plan.
+ *
+ * @param {number} id - ID of the plan.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Array<PlanAdjustment>>>}
+ */
+export const v2ListPlanAdjustments = (id, config = {}) =>
+    api.request({ ...config, method: 'get', url: `/api/v2/plans/${id}/adjustments` });
+
+/**
+ * Add to or subtract from the total of a plan.
+ *
+ * @param {number} id - ID of the plan.
+ * @param {PlanAmountRequest} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<PlanAdjustment>>}
+ */
+export const v2AdjustPlan = (id, body, config = {}) =>
+    api.request({ ...config, method: 'post', url: `/api/v2/plans/${id}/adjustments`, data: body });
+
+/**
+ * List the expenses of a plan.
+ *
+ * @param {number} id - ID of the plan.
+ * @param {Object} [params]
+ * @param {number} [params.period_id] - Only the expenses of this budget period.
+ * @param {number} [params.category] - Only the expenses of this category.
+ * @param {number} [params.paid_by] - Only the expenses paid by this member.
+ * @param {boolean} [params.recurring] - Only recurring or only one-off expenses.
+ * @param {string} [params.from] - First day, inclusive.
+ * @param {string} [params.to] - Last day, inclusive.
+ * @param {number} [params.min_amount] - Smallest amount, inclusive.
+ * @param {number} [params.max_amount] - Largest amount, inclusive.
+ * @param {string} [params.tags] - Comma separated tag names.
+ * @param {string} [params.match] - any (default) or all of the tags.
+ * @param {number} [params.limit] - Page size, 50 by default and at most 200. Without limit nor cursor the first page is written as a plain array, with the cursor of the next one in the X-Next-Cursor header.
+ * @param {string} [params.sort] - Field to sort by, prefixed with - for descending order.
+ * @param {string} [params.cursor] - next_cursor of the previous page.
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<(Array<Expense>|ExpensePage)>>}
+ */
+export const v2ListExpenses = (id, params, config = {}) =>
+    api.request({ ...config, method: 'get', url: `/api/v2/plans/${id}/expenses`, params });
+
+/**
+ * Add an expense to a plan.
+ *
+ * @param {number} id - ID of the plan.
+ * @param {ExpenseRequest} body
+ * @param {import('axios').AxiosRequestConfig} [config]
+ * @returns {Promise<import('axios').AxiosResponse<Expense>>}
+ */
+export const v2CreateExpense = (id, body, config = {}) =>
+    api.request({ ...config, method: 'post', url: `/api/v2/plans/${id}/expenses`, data: body });
+
+/**
+ * Get an expense.
+ *
+ * @param {number} id - ID of the plan.
+ * @param {number} expenseId - ID of the expense.
+ * @param {import('axios').AxiosRequestConfig} [config] - Sends the If-None-Match headers.
+ * @returns {Promise<import('axios').AxiosResponse<Expense>>}
+ */
+export const v2GetExpense = (id, expenseId, config = {}) =>
+    api.request({ ...config, method: 'get', url: `/api/v2/plans/${id}/expenses/${expenseId}` });
+
+/**
+ * Edit some fields of an expense.
+ *
+ * @param {number} id - ID of the plan.
+ * @param {number} expenseId - ID of the expense.
+ * @param {ExpensePatchRequest} body
+ * @param {import('axios').AxiosRequestConfig} [config] - Sends the If-Match headers.
+ * @returns {Promise<import('axios').AxiosResponse<Expense>>}
+ */
+export const v2PatchExpense = (id, expenseId, body, config = {}) =>
+    api.request({ ...config, method: 'patch', url: `/api/v2/plans/${id}/expenses/${expenseId}`, data: body });
+
+/**
+ * Delete an expense.
+ *
+ * @param {number} id - ID of the plan.
+ * @param {number} expenseId - ID of the expense.
+ * @param {import('axios').AxiosRequestConfig} [config] - Sends the If-Match headers.
+ * @returns {Promise<import('axios').AxiosResponse<void>>}
+ */
+export const v2DeleteExpense = (id, expenseId, config = {}) =>
+    api.request({ ...config, method: 'delete', url: `/api/v2/plans/${id}/expenses/${expenseId}` });